@@ -3,14 +3,26 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"legal-extractor/internal/config"
@@ -23,6 +35,12 @@ import (
 // 全局提取器实例
 var extractorInstance *extractor.Extractor
 
+// 全局提取结果缓存，为 nil 时不缓存
+var resultCache *ResultCache
+
+// 服务日志，main 中替换为带格式的 logger。只记录记录数、错误等概要，不记录提取到的字段值
+var logger = slog.Default()
+
 // IPRateLimiter 简单的 IP 限流器
 type IPRateLimiter struct {
 	requests map[string][]time.Time
@@ -72,7 +90,7 @@ func RateLimitMiddleware(limiter *IPRateLimiter) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			ip := c.RealIP()
-			if !limiter.Allow(ip) {
+			if !rateLimitExempt(c) && !limiter.Allow(ip) {
 				return c.JSON(http.StatusTooManyRequests, map[string]string{
 					"error": "请求过于频繁，请稍后再试",
 				})
@@ -82,41 +100,263 @@ func RateLimitMiddleware(limiter *IPRateLimiter) echo.MiddlewareFunc {
 	}
 }
 
-// ExtractRequest 提取请求结构
+// ResultCache 按文件内容哈希缓存提取结果，避免前端重试时重复调用 OCR、重复消耗配额
+type ResultCache struct {
+	entries map[string]cacheEntry
+	order   []string // 写入顺序，容量满时优先淘汰最早的条目
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxSize int
+}
+
+type cacheEntry struct {
+	records []extractor.Record
+	expires time.Time
+}
+
+// NewResultCache 创建结果缓存，ttl 或 maxSize 非正数时返回 nil (即禁用缓存)
+func NewResultCache(ttl time.Duration, maxSize int) *ResultCache {
+	if ttl <= 0 || maxSize <= 0 {
+		return nil
+	}
+	return &ResultCache{
+		entries: make(map[string]cacheEntry),
+		ttl:     ttl,
+		maxSize: maxSize,
+	}
+}
+
+// Get 查询未过期的缓存结果
+func (rc *ResultCache) Get(key string) ([]extractor.Record, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.records, true
+}
+
+// Put 写入缓存，超出容量时先清理过期条目，再淘汰最早写入的条目
+func (rc *ResultCache) Put(key string, records []extractor.Record) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, exists := rc.entries[key]; !exists {
+		rc.order = append(rc.order, key)
+	}
+	rc.entries[key] = cacheEntry{records: records, expires: time.Now().Add(rc.ttl)}
+
+	if len(rc.entries) <= rc.maxSize {
+		return
+	}
+	now := time.Now()
+	var kept []string
+	for _, k := range rc.order {
+		if now.After(rc.entries[k].expires) {
+			delete(rc.entries, k)
+			continue
+		}
+		kept = append(kept, k)
+	}
+	for len(kept) > rc.maxSize {
+		delete(rc.entries, kept[0])
+		kept = kept[1:]
+	}
+	rc.order = kept
+}
+
+// resultCacheKey 由文件内容哈希与所选字段共同组成缓存键
+func resultCacheKey(fileData []byte, fields []string) string {
+	sorted := append([]string(nil), fields...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("%x|%s", sha256.Sum256(fileData), strings.Join(sorted, ","))
+}
+
+// ExtractRequest JSON 方式提交的提取请求，文件内容以 base64 编码 (供不便构造 multipart 的客户端使用)
 type ExtractRequest struct {
-	Fields []string `json:"fields"`
+	Filename      string   `json:"filename"`
+	ContentBase64 string   `json:"contentBase64"` // 标准 base64，也接受 "data:...;base64," 形式的 Data URL
+	Fields        []string `json:"fields"`
 }
 
 // ExtractResponse 提取响应结构
 type ExtractResponse struct {
 	Success     bool               `json:"success"`
 	RecordCount int                `json:"recordCount"`
+	Total       int                `json:"total"`            // 分页前的记录总数
+	Offset      int                `json:"offset,omitempty"` // 本页起始位置
+	Limit       int                `json:"limit,omitempty"`  // 每页条数，未分页时为 0
 	Records     []extractor.Record `json:"records,omitempty"`
 	FieldLabels map[string]string  `json:"fieldLabels,omitempty"`
-	Error       string             `json:"error,omitempty"`
+	// FileHash 文件内容的 SHA-256，可用于 /api/extract/reparse 改选字段重新解析
+	FileHash string `json:"fileHash,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// Missing 严格模式下缺少必填字段的记录 (此时 success 为 false，状态码 422)
+	Missing []extractor.MissingFields `json:"missing,omitempty"`
+}
+
+// ReparseRequest 以新的字段选择重新解析已提取过的文件
+type ReparseRequest struct {
+	FileHash string   `json:"fileHash"`
+	Fields   []string `json:"fields"`
+}
+
+// BatchExtractResponse 批量提取响应结构，Results 按上传顺序列出每个文件的结果
+type BatchExtractResponse struct {
+	Success     bool                       `json:"success"`
+	Total       int                        `json:"total"` // 各文件的记录总数
+	Results     []extractor.DocumentResult `json:"results,omitempty"`
+	Merged      []extractor.Record         `json:"merged,omitempty"` // 各文件记录合并后的列表 (开启去重时已合并重复案件)
+	FieldLabels map[string]string          `json:"fieldLabels,omitempty"`
+	Error       string                     `json:"error,omitempty"`
+}
+
+// VersionResponse 版本信息响应结构
+type VersionResponse struct {
+	Version   string             `json:"version"`
+	BuildTime string             `json:"buildTime"`
+	GitCommit string             `json:"gitCommit"`
+	Trial     config.TrialStatus `json:"trial"`
+	Providers []string           `json:"providers"`
+	// Components 桥接工具、识别服务地址与提取规则的版本
+	Components extractor.ComponentVersions `json:"components"`
+}
+
+// CapabilitiesResponse 服务端能力清单，供通用前端按此动态渲染上传、字段与导出选项
+type CapabilitiesResponse struct {
+	Version         string            `json:"version"`
+	InputExtensions []string          `json:"inputExtensions"` // 可提取的文档扩展名
+	ImageExtensions []string          `json:"imageExtensions"` // 可识别的图片扩展名，需 providers 非空
+	ExportFormats   []string          `json:"exportFormats"`
+	Fields          []FieldCapability `json:"fields"`        // 可选择提取的字段
+	DerivedFields   []FieldCapability `json:"derivedFields"` // 结果与导出中可能出现、但不能单独选择的派生列
+	Providers       []string          `json:"providers"`
+	DocTypes        []string          `json:"docTypes"` // 可按 docType 参数筛选导出的文书类型
+}
+
+// FieldCapability 可提取的字段及其显示名称
+type FieldCapability struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
 }
 
 // ExportRequest 导出请求结构
 type ExportRequest struct {
 	Records []extractor.Record `json:"records"`
-	Format  string             `json:"format"` // xlsx, csv, json
+	Format  string             `json:"format"` // xlsx, csv, tsv, json, txt
+}
+
+// DiffRequest 比对请求：以 expected 为基准检查 actual
+type DiffRequest struct {
+	Expected []extractor.Record `json:"expected"`
+	Actual   []extractor.Record `json:"actual"`
+	Match    string             `json:"match"` // id (默认) 或 order
 }
 
 func main() {
+	showVersion := flag.Bool("version", false, "输出版本信息 (JSON) 后退出")
+	flag.Parse()
+
 	// 1. 初始化配置
 	if err := config.Init(""); err != nil {
-		fmt.Println("警告: 配置加载失败:", err.Error())
+		fmt.Fprintln(os.Stderr, "警告: 配置加载失败:", err.Error())
 	}
 
 	// 2. 初始化提取器
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+	if *showVersion {
+		// 输出只包含版本 JSON，日志写到标准错误
+		extractorInstance = extractor.NewExtractor(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+		out, _ := json.MarshalIndent(versionInfo(context.Background()), "", "  ")
+		fmt.Println(string(out))
+		return
+	}
 	extractorInstance = extractor.NewExtractor(logger)
+	extractorInstance.SetUsageCounter(extractor.NewUsageCounter(filepath.Join(config.Dir(), "usage.json")))
+
+	// 3. 初始化结果缓存 (LEGAL_EXTRACTOR_CACHE_TTL 为 0 时禁用)
+	cacheTTL := envDuration("LEGAL_EXTRACTOR_CACHE_TTL", 10*time.Minute)
+	cacheSize := envInt("LEGAL_EXTRACTOR_CACHE_SIZE", 100)
+	resultCache = NewResultCache(cacheTTL, cacheSize)
 
-	// 3. 创建 Echo 实例
+	// 4. 检查导出临时目录 (LEGAL_EXTRACTOR_TMPDIR)，并清理上次遗留的导出文件
+	exportTempDir = os.Getenv("LEGAL_EXTRACTOR_TMPDIR")
+	if err := checkTempDirWritable(exportTempDir); err != nil {
+		logger.Error("导出功能将不可用", "error", err)
+	} else {
+		if n := cleanStaleExportFiles(exportTempDir, time.Hour); n > 0 {
+			logger.Info("已清理遗留的导出临时文件", "count", n)
+		}
+	}
+
+	// 5. 初始化分片上传 (LEGAL_EXTRACTOR_UPLOAD_MAX_MB 限制单个文件大小，LEGAL_EXTRACTOR_UPLOAD_TTL 后清理未完成的上传)。
+	// 临时文件放在本进程私有的子目录中，退出时整体删除，不触碰共享临时目录中其他进程的文件
+	stopUploads := func() {}
+	if dir, err := os.MkdirTemp(exportTempDir, uploadDirPattern); err != nil {
+		logger.Error("分片上传功能将不可用", "error", err)
+	} else {
+		uploadStore = NewUploadStore(dir,
+			envDuration("LEGAL_EXTRACTOR_UPLOAD_TTL", time.Hour),
+			int64(envInt("LEGAL_EXTRACTOR_UPLOAD_MAX_MB", 500))<<20)
+		stopJanitor := uploadStore.StartJanitor(func(n int) {
+			logger.Info("已清理超时未完成的分片上传", "count", n)
+		})
+		stopUploads = func() {
+			stopJanitor()
+			os.RemoveAll(dir)
+		}
+	}
+	defer stopUploads()
+
+	// 6. 创建服务 (支持通过 LEGAL_EXTRACTOR_BASE_PATH 挂载到反向代理子路径下)
+	basePath := normalizeBasePath(os.Getenv("LEGAL_EXTRACTOR_BASE_PATH"))
+	e := newServer(basePath)
+
+	// 可选：OpenTelemetry 链路追踪 (配置 telemetry.otlp_endpoint 后启用)
+	shutdownTracing, err := setupTracing(context.Background(), config.Get().Telemetry)
+	if err != nil {
+		logger.Warn("链路追踪初始化失败，已禁用", "error", err)
+	} else if shutdownTracing != nil {
+		defer shutdownTracing(context.Background())
+		e.Use(TracingMiddleware())
+		logger.Info("已启用 OpenTelemetry 链路追踪", "endpoint", config.Get().Telemetry.OTLPEndpoint)
+	}
+
+	// 7. 启动服务
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	logger.Info("LegalExtractor Web 服务启动", "port", port, "basePath", basePath)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- e.Start(":" + port) }()
+	select {
+	case err := <-serveErr:
+		stopUploads()
+		e.Logger.Fatal(err)
+	case <-ctx.Done():
+	}
+
+	// 8. 收到退出信号后停止接收新请求，等待进行中的请求完成，再依次停止清理协程与链路追踪
+	logger.Info("正在停止服务...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("服务未能在超时前停止", "error", err)
+	}
+}
+
+// newServer 创建 Echo 实例并注册中间件与路由，所有路由均挂载在 basePath 之下
+func newServer(basePath string) *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
 
-	// 4. 中间件
+	// 中间件
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS()) // 允许跨域请求
@@ -125,114 +365,604 @@ func main() {
 	limiter := NewIPRateLimiter(10, time.Minute)
 	e.Use(RateLimitMiddleware(limiter))
 
-	// 5. 路由
-	e.GET("/", handleIndex)
-	e.GET("/health", handleHealth)
+	// 路由
+	root := e.Group(basePath)
+	root.GET("/", handleIndex)
+	root.GET("/health", handleHealth)
 
-	api := e.Group("/api")
+	api := root.Group("/api")
+	api.GET("/version", handleVersion)
+	api.GET("/capabilities", handleCapabilities)
+	api.GET("/usage", handleUsage)
+	api.POST("/usage/reset", handleUsageReset)
+	api.GET("/patterns", handlePatterns)
+	api.GET("/config/schema", handleConfigSchema)
+	// 凭据校验会向识别服务发起请求，单独限流：每 IP 每分钟最多 3 次
+	api.POST("/providers/test", handleProviderTest, RateLimitMiddleware(NewIPRateLimiter(3, time.Minute)))
 	api.POST("/extract", handleExtract)
+	api.POST("/extract/text", handleExtractText)
+	api.POST("/extract/batch", handleExtractBatch)
+	api.POST("/extract/reparse", handleReparse)
+	api.POST("/scan", handleScan)
+	api.POST("/estimate", handleEstimate)
 	api.POST("/export", handleExport)
+	api.POST("/diff", handleDiff)
 
-	// 6. 启动服务
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// 分片上传：大文件经不稳定网络上传时可断点续传
+	api.POST("/upload/init", handleUploadInit)
+	api.GET("/upload/:id", handleUploadStatus)
+	api.POST("/upload/:id/chunk", handleUploadChunk)
+	api.POST("/upload/:id/complete", handleUploadComplete)
+
+	return e
+}
+
+// envDuration 读取时长类环境变量 (如 "10m")，未设置或格式错误时返回默认值
+func envDuration(name string, def time.Duration) time.Duration {
+	if v := os.Getenv(name); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		fmt.Printf("警告: 环境变量 %s=%q 格式无效，使用默认值 %s\n", name, v, def)
 	}
+	return def
+}
+
+// envInt 读取整数类环境变量，未设置或格式错误时返回默认值
+func envInt(name string, def int) int {
+	if v := os.Getenv(name); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		fmt.Printf("警告: 环境变量 %s=%q 格式无效，使用默认值 %d\n", name, v, def)
+	}
+	return def
+}
 
-	logger.Info("LegalExtractor Web 服务启动", "port", port)
-	e.Logger.Fatal(e.Start(":" + port))
+// normalizeBasePath 规范化路由前缀：保证以 "/" 开头且不以 "/" 结尾，根路径返回空串
+func normalizeBasePath(p string) string {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
 }
 
 // handleIndex 首页
 func handleIndex(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{
 		"service": "LegalExtractor Web API",
-		"version": "2.1.0",
+		"version": config.Version,
 		"status":  "running",
 	})
 }
 
-// handleHealth 健康检查
+// handleHealth 健康检查。临时目录不可写只影响大文件导出与分片上传，提取仍可用，因此报告为 degraded 而不返回 503，
+// 避免编排系统据此重启或摘除仍能服务的实例
 func handleHealth(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
-		"status": "healthy",
+	if err := checkTempDirWritable(exportTempDir); err != nil {
+		return c.JSON(http.StatusOK, map[string]any{
+			"status":   "degraded",
+			"degraded": true,
+			"checks":   map[string]string{"tempDir": err.Error()},
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"status":   "healthy",
+		"degraded": false,
+		"checks":   map[string]string{"tempDir": "ok"},
 	})
 }
 
-// handleExtract 处理文件提取请求
-func handleExtract(c echo.Context) error {
-	// 1. 获取上传的文件
-	file, err := c.FormFile("file")
+// handleVersion 返回构建、授权与各组件的版本信息，便于支持人员将问题与具体版本对应
+func handleVersion(c echo.Context) error {
+	return c.JSON(http.StatusOK, versionInfo(c.Request().Context()))
+}
+
+// versionInfo 汇总版本信息，供 /api/version 与 --version 共用
+func versionInfo(ctx context.Context) VersionResponse {
+	return VersionResponse{
+		Version:    config.Version,
+		BuildTime:  config.BuildTime,
+		GitCommit:  config.GitCommit,
+		Trial:      config.GetTrialStatus(),
+		Providers:  extractorInstance.Providers(),
+		Components: extractorInstance.Versions(ctx),
+	}
+}
+
+// handleCapabilities 汇总支持的输入格式、导出格式、字段、已配置的识别引擎与版本
+func handleCapabilities(c echo.Context) error {
+	formats := make([]string, 0, len(exportFormats))
+	for f := range exportFormats {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+
+	var fields, derived []FieldCapability
+	for _, key := range extractor.FieldKeys() {
+		fields = append(fields, FieldCapability{Key: key, Label: extractor.PatternRegistry[key].Label})
+	}
+	for _, key := range extractor.DerivedFieldKeys() {
+		derived = append(derived, FieldCapability{Key: key, Label: extractor.PatternRegistry[key].Label})
+	}
+
+	return c.JSON(http.StatusOK, CapabilitiesResponse{
+		Version:         config.Version,
+		InputExtensions: extractor.SupportedExtensions(),
+		ImageExtensions: extractor.ImageExtensions(),
+		ExportFormats:   formats,
+		Fields:          fields,
+		DerivedFields:   derived,
+		Providers:       extractorInstance.Providers(),
+		DocTypes:        extractor.DocTypes(),
+	})
+}
+
+// handleUsage 返回各识别引擎的累计调用量，便于对照配额
+func handleUsage(c echo.Context) error {
+	return c.JSON(http.StatusOK, extractorInstance.Usage().Snapshot())
+}
+
+// handleUsageReset 清零调用量统计 (如配额周期重置后)，返回清零后的统计
+func handleUsageReset(c echo.Context) error {
+	if err := extractorInstance.Usage().Reset(); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("清零调用量统计失败: %v", err))
+	}
+	return c.JSON(http.StatusOK, extractorInstance.Usage().Snapshot())
+}
+
+// handlePatterns 返回当前生效的提取正则，便于排查字段为何未匹配
+func handlePatterns(c echo.Context) error {
+	return c.JSON(http.StatusOK, extractorInstance.ActivePatterns())
+}
+
+// handleConfigSchema 返回配置文件的 JSON Schema，供编辑器补全与校验 conf.yaml
+func handleConfigSchema(c echo.Context) error {
+	data, err := config.JSONSchema()
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, ExtractResponse{
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.JSONBlob(http.StatusOK, data)
+}
+
+// handleProviderTest 校验识别引擎凭据，provider 查询参数指定引擎，缺省时校验全部已配置的引擎
+func handleProviderTest(c echo.Context) error {
+	if name := c.QueryParam("provider"); name != "" {
+		return c.JSON(http.StatusOK, map[string]any{"results": []extractor.ProviderCheck{extractorInstance.TestProvider(name)}})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"results": extractorInstance.TestProviders()})
+}
+
+// handleExtract 处理文件提取请求，Content-Type 为 application/json 时按 ExtractRequest 读取 base64 编码的文件
+func handleExtract(c echo.Context) error {
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return handleExtractJSON(c)
+	}
+
+	// 读取并校验上传文件
+	fileName, fileData, httpErr := readUpload(c)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, ExtractResponse{
 			Success: false,
-			Error:   "请上传文件",
+			Error:   fmt.Sprint(httpErr.Message),
 		})
 	}
 
-	// 2. 验证文件类型
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowedExts := map[string]bool{".pdf": true, ".docx": true, ".jpg": true, ".jpeg": true, ".png": true}
-	if !allowedExts[ext] {
+	return extractAndRespond(c, fileName, fileData, queryFields(c))
+}
+
+// maxJSONFileSize JSON 方式提交的文件解码后的大小上限，更大的文件请使用 multipart 或分片上传
+const maxJSONFileSize = 32 << 20
+
+// handleExtractJSON 解码 ExtractRequest 中的文件内容，校验扩展名与大小后走与 multipart 相同的提取流程
+func handleExtractJSON(c echo.Context) error {
+	req := c.Request()
+	// base64 编码后约为原大小的 4/3，另留出文件名与字段列表的余量
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxJSONFileSize/3*4+64<<10)
+
+	var body ExtractRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return c.JSON(http.StatusRequestEntityTooLarge, ExtractResponse{
+				Success: false,
+				Error:   fmt.Sprintf("文件不能超过 %d MB，更大的文件请使用 multipart 或分片上传", maxJSONFileSize>>20),
+			})
+		}
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: "无效的 JSON 请求体"})
+	}
+
+	ext := strings.ToLower(filepath.Ext(body.Filename))
+	if !allowedUploadExts[ext] {
 		return c.JSON(http.StatusBadRequest, ExtractResponse{
 			Success: false,
-			Error:   fmt.Sprintf("不支持的文件格式: %s，支持 PDF、DOCX、JPG、PNG", ext),
+			Error:   fmt.Sprintf("不支持的文件格式: %s，支持 PDF、DOCX、EML、JPG、PNG", ext),
 		})
 	}
-
-	// 3. 读取文件内容到内存
-	src, err := file.Open()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ExtractResponse{
+	content := body.ContentBase64
+	if i := strings.Index(content, ";base64,"); i >= 0 && strings.HasPrefix(content, "data:") {
+		content = content[i+len(";base64,"):]
+	}
+	fileData, err := base64.StdEncoding.DecodeString(content)
+	if err != nil || len(fileData) == 0 {
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: "contentBase64 不是有效的 base64 文件内容"})
+	}
+	if len(fileData) > maxJSONFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, ExtractResponse{
 			Success: false,
-			Error:   "无法读取上传的文件",
+			Error:   fmt.Sprintf("文件不能超过 %d MB，更大的文件请使用 multipart 或分片上传", maxJSONFileSize>>20),
 		})
 	}
-	defer src.Close()
 
-	fileData, err := io.ReadAll(src)
+	fields := body.Fields
+	if len(fields) == 0 {
+		fields = queryFields(c)
+	}
+	return extractAndRespond(c, body.Filename, fileData, fields)
+}
+
+// maxTextSize 纯文本提取接口的请求体上限
+const maxTextSize = 2 << 20
+
+// handleExtractText 解析请求体中的纯文本 (或表单字段 text)，适用于已有 OCR 文本、无需再上传文件的场景
+func handleExtractText(c echo.Context) error {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxTextSize)
+
+	var text string
+	var readErr error
+	ctype := req.Header.Get(echo.HeaderContentType)
+	if strings.HasPrefix(ctype, echo.MIMEApplicationForm) || strings.HasPrefix(ctype, echo.MIMEMultipartForm) {
+		// FormValue 会吞掉解析错误，这里显式解析表单以区分超限与格式错误
+		params, err := c.FormParams()
+		text, readErr = params.Get("text"), err
+	} else {
+		body, err := io.ReadAll(req.Body)
+		text, readErr = string(body), err
+	}
+	if readErr != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(readErr, &maxErr) {
+			return c.JSON(http.StatusRequestEntityTooLarge, ExtractResponse{
+				Success: false,
+				Error:   fmt.Sprintf("文本不能超过 %d MB", maxTextSize>>20),
+			})
+		}
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: "读取请求内容失败"})
+	}
+	if strings.TrimSpace(text) == "" {
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: "请提供文本内容"})
+	}
+
+	offset, limit, err := pageFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: err.Error()})
+	}
+	records, err := extractorInstance.ExtractText(text, queryFields(c))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ExtractResponse{
 			Success: false,
-			Error:   "读取文件内容失败",
+			Error:   fmt.Sprintf("提取失败: %v", err),
 		})
 	}
+	return c.JSON(http.StatusOK, pagedResponse(records, offset, limit))
+}
+
+// maxBatchFileSize 批量提取中单个文件的大小上限，超出的文件记为该文件的错误
+const maxBatchFileSize = 32 << 20
+
+// handleExtractBatch 一次提取多个上传文件 (表单字段 files 可重复)，结果按来源文件分组返回，
+// 单个文件失败 (包括格式不支持或超出大小上限) 不影响其他文件。merge=true 或开启 extraction.dedupe 时另返回合并 (去重) 后的记录列表
+func handleExtractBatch(c echo.Context) error {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		return c.JSON(http.StatusBadRequest, BatchExtractResponse{Success: false, Error: "请上传文件 (表单字段 files)"})
+	}
+
+	files := form.File["files"]
+	results := make([]extractor.DocumentResult, len(files))
+	var docs []extractor.BatchDocument
+	var docIndex []int // docs 中各文件在 results 中的位置
+	for i, file := range files {
+		results[i] = extractor.DocumentResult{FileName: file.Filename, Records: []extractor.Record{}}
+		if ext := strings.ToLower(filepath.Ext(file.Filename)); !allowedUploadExts[ext] {
+			results[i].Error = fmt.Sprintf("不支持的文件格式: %s，支持 PDF、DOCX、EML、JPG、PNG", ext)
+			continue
+		}
+		if file.Size > maxBatchFileSize {
+			results[i].Error = fmt.Sprintf("文件不能超过 %d MB，更大的文件请使用分片上传", maxBatchFileSize>>20)
+			continue
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, BatchExtractResponse{Success: false, Error: "无法读取上传的文件"})
+		}
+		data, err := io.ReadAll(src)
+		src.Close()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, BatchExtractResponse{Success: false, Error: "读取文件内容失败"})
+		}
+		docs = append(docs, extractor.BatchDocument{FileName: file.Filename, Data: data})
+		docIndex = append(docIndex, i)
+	}
+
+	ctx, err := strictContext(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BatchExtractResponse{Success: false, Error: err.Error()})
+	}
+	for j, res := range extractorInstance.ExtractBatch(ctx, docs, queryFields(c), nil) {
+		results[docIndex[j]] = res
+	}
+	total := 0
+	for _, res := range results {
+		total += len(res.Records)
+	}
+	resp := BatchExtractResponse{
+		Success:     true,
+		Total:       total,
+		Results:     results,
+		FieldLabels: fieldLabels(),
+	}
+	if c.QueryParam("merge") == "true" || extractorInstance.Dedupe != nil {
+		resp.Merged = extractor.MergeBatch(results, extractorInstance.Dedupe)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// strictContext 返回提取使用的 ctx：strict 查询参数 (true/false) 为本次请求覆盖 extraction.strict
+func strictContext(c echo.Context) (context.Context, error) {
+	ctx := c.Request().Context()
+	v := c.QueryParam("strict")
+	if v == "" {
+		return ctx, nil
+	}
+	strict, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("strict 参数无效: %q", v)
+	}
+	return extractor.WithStrict(ctx, strict), nil
+}
 
-	// 4. 获取提取字段（可选）
+// queryFields 返回 fields 查询参数指定的提取字段，未指定时使用默认字段
+func queryFields(c echo.Context) []string {
 	fields := c.QueryParams()["fields"]
 	if len(fields) == 0 {
 		fields = []string{"defendant", "idNumber", "request", "factsReason"}
 	}
+	return fields
+}
 
-	// 5. 调用核心提取逻辑
-	records, err := extractorInstance.ExtractData(fileData, file.Filename, fields, nil)
+// extractAndRespond 提取文件内容并返回结果，供普通上传、JSON 提交与分片上传共用
+func extractAndRespond(c echo.Context, fileName string, fileData []byte, fields []string) error {
+	offset, limit, err := pageFromQuery(c)
 	if err != nil {
-		fmt.Printf("提取失败: %v\n", err)
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: err.Error()})
+	}
+
+	ctx, err := strictContext(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: err.Error()})
+	}
+
+	// 调用核心提取逻辑 (相同文件短时间内重复上传时直接返回缓存结果；指定 strict 时结果可能不同，单独缓存)
+	cacheKey := resultCacheKey(fileData, fields)
+	if v := c.QueryParam("strict"); v != "" {
+		cacheKey += "|strict=" + v
+	}
+	if resultCache != nil {
+		if cached, ok := resultCache.Get(cacheKey); ok {
+			c.Response().Header().Set("X-Cache", "HIT")
+			resp := pagedResponse(cached, offset, limit)
+			resp.FileHash = extractor.FileHash(fileData)
+			return c.JSON(http.StatusOK, resp)
+		}
+		c.Response().Header().Set("X-Cache", "MISS")
+	}
+
+	records, err := extractorInstance.ExtractDataContext(ctx, fileData, fileName, fields, nil)
+	var missing *extractor.MissingFieldsError
+	if errors.As(err, &missing) {
+		return c.JSON(http.StatusUnprocessableEntity, ExtractResponse{Success: false, Error: err.Error(), Missing: missing.Records})
+	}
+	if err != nil {
+		logger.Error("提取失败", "error", err)
 		return c.JSON(http.StatusInternalServerError, ExtractResponse{
 			Success: false,
 			Error:   fmt.Sprintf("提取失败: %v", err),
 		})
 	}
 
-	fmt.Printf("提取成功，记录数: %d\n", len(records))
 	if len(records) > 0 {
-		fmt.Printf("第一条记录示例: %+v\n", records[0])
+		logger.Info("提取成功", "recordCount", len(records))
 	} else {
-		fmt.Println("警告: 返回了空记录列表")
+		logger.Warn("提取成功但未提取到任何记录")
+	}
+
+	if resultCache != nil {
+		resultCache.Put(cacheKey, records)
+	}
+
+	// 返回结果及字段标签 (缓存完整结果，只返回所请求的一页)
+	resp := pagedResponse(records, offset, limit)
+	resp.FileHash = extractor.FileHash(fileData)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleReparse 对已提取过的文件 (按 fileHash) 以新的字段选择重新解析缓存的原文，不再上传文件或调用识别引擎；
+// fields 为空时使用查询参数或默认字段。服务重启或原文未缓存时返回 404，需重新上传提取
+func handleReparse(c echo.Context) error {
+	offset, limit, err := pageFromQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: err.Error()})
+	}
+	var req ReparseRequest
+	if err := c.Bind(&req); err != nil || strings.TrimSpace(req.FileHash) == "" {
+		return c.JSON(http.StatusBadRequest, ExtractResponse{Success: false, Error: "请求需包含 fileHash"})
+	}
+	fields := req.Fields
+	if len(fields) == 0 {
+		fields = queryFields(c)
+	}
+
+	records, err := extractorInstance.ReparseFields(req.FileHash, fields)
+	if errors.Is(err, extractor.ErrTextNotCached) {
+		return c.JSON(http.StatusNotFound, ExtractResponse{Success: false, Error: err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ExtractResponse{
+			Success: false,
+			Error:   fmt.Sprintf("重新解析失败: %v", err),
+		})
+	}
+	resp := pagedResponse(records, offset, limit)
+	resp.FileHash = req.FileHash
+	return c.JSON(http.StatusOK, resp)
+}
+
+// pageFromQuery 从 offset、limit 查询参数解析分页位置，未指定时返回全部记录
+func pageFromQuery(c echo.Context) (offset, limit int, err error) {
+	if v := c.QueryParam("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("无效的 offset 参数: %s", v)
+		}
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			return 0, 0, fmt.Errorf("无效的 limit 参数: %s", v)
+		}
+	}
+	return offset, limit, nil
+}
+
+// pagedResponse 按分页位置组装提取响应，RecordCount 为本页记录数，Total 为总数
+func pagedResponse(records []extractor.Record, offset, limit int) ExtractResponse {
+	page := extractor.PageRecords(records, offset, limit)
+	return ExtractResponse{
+		Success:     true,
+		RecordCount: len(page.Records),
+		Total:       page.Total,
+		Offset:      page.Offset,
+		Limit:       page.Limit,
+		Records:     page.Records,
+		FieldLabels: fieldLabels(),
+	}
+}
+
+// allowedUploadExts 允许上传的文件扩展名 (文档与图片)
+var allowedUploadExts = func() map[string]bool {
+	exts := make(map[string]bool)
+	for _, ext := range append(extractor.SupportedExtensions(), extractor.ImageExtensions()...) {
+		exts[ext] = true
+	}
+	return exts
+}()
+
+// readUpload 读取表单中的 file 字段并校验文件类型，失败时返回带状态码的错误
+func readUpload(c echo.Context) (string, []byte, *echo.HTTPError) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, "请上传文件")
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !allowedUploadExts[ext] {
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("不支持的文件格式: %s，支持 PDF、DOCX、EML、JPG、PNG", ext))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", nil, echo.NewHTTPError(http.StatusInternalServerError, "无法读取上传的文件")
+	}
+	defer src.Close()
+
+	fileData, err := io.ReadAll(src)
+	if err != nil {
+		return "", nil, echo.NewHTTPError(http.StatusInternalServerError, "读取文件内容失败")
+	}
+	return file.Filename, fileData, nil
+}
+
+// ScanField 字段扫描结果中的单个字段
+type ScanField struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// ScanResponse 字段扫描响应结构
+type ScanResponse struct {
+	Success bool        `json:"success"`
+	Fields  []ScanField `json:"fields,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// handleScan 轻量扫描上传文档中存在的字段 (不调用云端识别)，供前端构建字段选择界面
+func handleScan(c echo.Context) error {
+	fileName, fileData, httpErr := readUpload(c)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, ScanResponse{
+			Success: false,
+			Error:   fmt.Sprint(httpErr.Message),
+		})
+	}
+
+	keys, err := extractorInstance.ScanFields(fileData, fileName)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, ScanResponse{
+			Success: false,
+			Error:   fmt.Sprintf("扫描失败: %v", err),
+		})
+	}
+
+	fields := make([]ScanField, 0, len(keys))
+	for _, k := range keys {
+		if p, ok := extractor.PatternRegistry[k]; ok {
+			fields = append(fields, ScanField{Key: k, Label: p.Label})
+		}
+	}
+	return c.JSON(http.StatusOK, ScanResponse{Success: true, Fields: fields})
+}
+
+// handleEstimate 在提取前估算页数与识别费用 (只读取文本层，不调用识别接口)
+func handleEstimate(c echo.Context) error {
+	fileName, fileData, httpErr := readUpload(c)
+	if httpErr != nil {
+		return c.JSON(httpErr.Code, map[string]string{"error": fmt.Sprint(httpErr.Message)})
 	}
 
-	// 6. 获取字段标签
+	est, err := extractorInstance.EstimateCost(fileData, fileName)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": fmt.Sprintf("估算失败: %v", err)})
+	}
+	return c.JSON(http.StatusOK, est)
+}
+
+// fieldLabels 返回字段键到中文标签的映射
+func fieldLabels() map[string]string {
 	labels := make(map[string]string)
 	for k, p := range extractor.PatternRegistry {
 		labels[k] = p.Label
 	}
+	return labels
+}
 
-	return c.JSON(http.StatusOK, ExtractResponse{
-		Success:     true,
-		RecordCount: len(records),
-		Records:     records,
-		FieldLabels: labels,
-	})
+// handleDiff 比对两组提取结果的逐字段差异，用于识别质量抽检与回归测试
+func handleDiff(c echo.Context) error {
+	var req DiffRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "无效的请求数据"})
+	}
+	strategy, err := extractor.ParseMatchStrategy(req.Match)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	diffs := extractor.DiffRecords(req.Expected, req.Actual, strategy)
+	if diffs == nil {
+		diffs = []extractor.FieldDiff{}
+	}
+	return c.JSON(http.StatusOK, map[string]any{"diffs": diffs, "count": len(diffs)})
 }
 
 // handleExport 处理数据导出请求
@@ -256,40 +986,211 @@ func handleExport(c echo.Context) error {
 		format = "xlsx"
 	}
 
-	// 创建临时文件
-	tmpFile, err := os.CreateTemp("", "export-*."+format)
+	opts, err := exportOptionsFromQuery(c, format)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if !exportFormats[format] {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("不支持的导出格式: %s", format),
+		})
+	}
+	contentType := exportContentType(format, opts)
+	disposition := fmt.Sprintf("attachment; filename=extracted_data.%s", format)
+
+	// 常见的小结果直接在内存中生成，不经过磁盘
+	if estimateExportSize(req.Records) <= memoryExportLimit {
+		var buf bytes.Buffer
+		if err := writeExport(&buf, format, req.Records, opts); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("导出失败: %v", err),
+			})
+		}
+		c.Response().Header().Set("Content-Disposition", disposition)
+		return c.Blob(http.StatusOK, contentType, buf.Bytes())
+	}
+
+	// 大结果写入临时文件，避免占用过多内存
+	tmpFile, err := os.CreateTemp(exportTempDir, exportFilePattern+"."+format)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "创建临时文件失败",
+			"error": fmt.Sprintf("创建临时文件失败，请检查 LEGAL_EXTRACTOR_TMPDIR (%s) 是否可写", displayTempDir(exportTempDir)),
 		})
 	}
 	tmpPath := tmpFile.Name()
-	tmpFile.Close()
 	defer os.Remove(tmpPath)
 
-	// 导出到临时文件
+	err = writeExport(tmpFile, format, req.Records, opts)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("导出失败: %v", err),
+		})
+	}
+
+	c.Response().Header().Set("Content-Disposition", disposition)
+	return streamExportFile(c, tmpPath, contentType)
+}
+
+// memoryExportLimit 估算的导出内容不超过该大小时在内存中生成，超过时改用临时文件
+var memoryExportLimit = 8 << 20
+
+// exportFormats 支持的导出格式
+var exportFormats = map[string]bool{"xlsx": true, "csv": true, "tsv": true, "json": true, "txt": true, "parquet": true}
+
+// estimateExportSize 按字段键与值的字节数粗略估算导出内容的大小
+func estimateExportSize(records []extractor.Record) int {
+	size := 0
+	for _, r := range records {
+		for k, v := range r {
+			size += len(k) + len(v)
+		}
+	}
+	return size
+}
+
+// writeExport 按格式将记录写入 w
+func writeExport(w io.Writer, format string, records []extractor.Record, opts extractor.ExportOptions) error {
 	switch format {
 	case "xlsx":
-		err = extractor.ExportExcel(tmpPath, req.Records)
+		style := extractor.ExcelStyle{}
+		if opts.Styled {
+			style = extractor.BrandedExcelStyle()
+		}
+		return extractor.WriteExcel(w, records, opts, style)
 	case "csv":
-		err = extractor.ExportCSV(tmpPath, req.Records)
+		return extractor.WriteCSV(w, records, opts)
+	case "tsv":
+		return extractor.WriteTSV(w, records, opts)
 	case "json":
-		err = extractor.ExportJSON(tmpPath, req.Records)
+		return extractor.WriteJSON(w, records, opts)
+	case "txt":
+		return extractor.WriteText(w, records)
+	case "parquet":
+		return extractor.WriteParquet(w, records, opts)
 	default:
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("不支持的导出格式: %s", format),
-		})
+		return fmt.Errorf("不支持的导出格式: %s", format)
 	}
+}
 
+// streamExportFile 将导出文件同步写入响应，返回时文件已发送完毕，调用方随后删除临时文件不会与发送竞争
+func streamExportFile(c echo.Context, path, contentType string) error {
+	f, err := os.Open(path)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("导出失败: %v", err),
+			"error": fmt.Sprintf("读取导出文件失败: %v", err),
 		})
 	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil {
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(info.Size(), 10))
+	}
+	return c.Stream(http.StatusOK, contentType, f)
+}
 
-	// 设置下载文件名
-	filename := fmt.Sprintf("extracted_data.%s", format)
-	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+// exportContentType 返回导出格式对应的 Content-Type
+func exportContentType(format string, opts extractor.ExportOptions) string {
+	switch format {
+	case "xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case "csv", "tsv":
+		mediaType := "text/csv"
+		if format == "tsv" {
+			mediaType = "text/tab-separated-values"
+		}
+		if opts.Encoding == extractor.EncodingGBK {
+			return mediaType + "; charset=gbk"
+		}
+		return mediaType + "; charset=utf-8"
+	case "json":
+		return echo.MIMEApplicationJSONCharsetUTF8
+	case "txt":
+		return echo.MIMETextPlainCharsetUTF8
+	case "parquet":
+		return "application/vnd.apache.parquet"
+	default:
+		return echo.MIMEOctetStream
+	}
+}
 
-	return c.File(tmpPath)
+// exportOptionsFromQuery 从查询参数解析导出选项，未指定的选项保持默认值；
+// xlsx 中单元格按文本写入，未指定 sanitize 时不转义公式前缀
+func exportOptionsFromQuery(c echo.Context, format string) (extractor.ExportOptions, error) {
+	opts := extractor.DefaultExportOptions()
+	if format == "xlsx" {
+		opts.SanitizeFormulas = false
+	}
+	if v := c.QueryParam("bom"); v != "" {
+		bom, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 bom 参数: %s", v)
+		}
+		opts.BOM = bom
+	}
+	if v := c.QueryParam("sanitize"); v != "" {
+		sanitize, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 sanitize 参数: %s", v)
+		}
+		opts.SanitizeFormulas = sanitize
+	}
+	if v := c.QueryParam("styled"); v != "" {
+		styled, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 styled 参数: %s", v)
+		}
+		opts.Styled = styled
+	}
+	if v := c.QueryParam("summary"); v != "" {
+		summary, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 summary 参数: %s", v)
+		}
+		opts.Summary = summary
+	}
+	if v := c.QueryParam("summaryOnly"); v != "" {
+		summaryOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 summaryOnly 参数: %s", v)
+		}
+		opts.SummaryOnly = summaryOnly
+	}
+	if v := c.QueryParam("long"); v != "" {
+		long, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("无效的 long 参数: %s", v)
+		}
+		opts.Long = long
+	}
+	if v := c.QueryParam("docType"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(t)
+			if !slices.Contains(extractor.DocTypes(), t) {
+				return opts, fmt.Errorf("无效的 docType 参数: %s (支持 %s)", t, strings.Join(extractor.DocTypes(), "、"))
+			}
+			opts.DocTypes = append(opts.DocTypes, t)
+		}
+	}
+	opts.SummaryTemplate = c.QueryParam("summaryTemplate")
+	switch v := c.QueryParam("headers"); v {
+	case "", "zh":
+	case "en":
+		opts.Headers = extractor.EnglishHeaders
+	default:
+		return opts, fmt.Errorf("无效的 headers 参数: %s (支持 zh、en)", v)
+	}
+	switch v := strings.ToLower(c.QueryParam("encoding")); v {
+	case "", extractor.EncodingUTF8, "utf-8":
+	case extractor.EncodingGBK:
+		opts.Encoding = extractor.EncodingGBK
+	default:
+		return opts, fmt.Errorf("无效的 encoding 参数: %s (支持 utf8、gbk)", v)
+	}
+	return opts, nil
 }
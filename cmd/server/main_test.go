@@ -0,0 +1,780 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"legal-extractor/internal/extractor"
+
+	"github.com/labstack/echo/v4"
+	"github.com/xuri/excelize/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHandleVersion(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/version", nil)
+	rec := httptest.NewRecorder()
+	if err := handleVersion(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("handleVersion returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	for _, key := range []string{"version", "buildTime", "gitCommit", "trial", "providers"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("Missing key %q in response: %s", key, rec.Body.String())
+		}
+	}
+	if _, ok := body["providers"].([]any); !ok {
+		t.Errorf("providers should be a JSON array, got %T", body["providers"])
+	}
+	if _, ok := body["trial"].(map[string]any)["isExpired"]; !ok {
+		t.Errorf("trial should carry isExpired, got %v", body["trial"])
+	}
+
+	components, ok := body["components"].(map[string]any)
+	if !ok {
+		t.Fatalf("components should be a JSON object, got %T", body["components"])
+	}
+	for _, key := range []string{"bridgeVersion", "providers", "patternSet", "patternSetHash", "patternOverrides"} {
+		if _, ok := components[key]; !ok {
+			t.Errorf("Missing key %q in components: %v", key, components)
+		}
+	}
+	if got := components["patternSet"]; got != extractor.PatternSetDefault {
+		t.Errorf("patternSet = %v, want %q without configured overrides", got, extractor.PatternSetDefault)
+	}
+}
+
+func TestHandleCapabilities(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := handleCapabilities(e.NewContext(httptest.NewRequest(http.MethodGet, "/api/capabilities", nil), rec)); err != nil {
+		t.Fatalf("handleCapabilities returned error: %v", err)
+	}
+	var body CapabilitiesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if !slices.Equal(body.InputExtensions, extractor.SupportedExtensions()) || !slices.Contains(body.InputExtensions, ".docx") {
+		t.Errorf("inputExtensions = %v", body.InputExtensions)
+	}
+	if !slices.Contains(body.ExportFormats, "xlsx") || !slices.Contains(body.ExportFormats, "parquet") {
+		t.Errorf("exportFormats = %v", body.ExportFormats)
+	}
+	if len(body.Fields) != len(extractor.FieldKeys()) {
+		t.Fatalf("Expected %d fields, got %v", len(extractor.FieldKeys()), body.Fields)
+	}
+	labels := make(map[string]string)
+	for _, f := range body.Fields {
+		labels[f.Key] = f.Label
+	}
+	for _, key := range []string{"defendant", "idNumber", "plaintiffLawFirm"} {
+		if labels[key] != extractor.PatternRegistry[key].Label {
+			t.Errorf("field %s: expected label %q, got %q", key, extractor.PatternRegistry[key].Label, labels[key])
+		}
+	}
+	// 派生列单独列出，不能作为提取字段选择
+	for _, key := range []string{"page", "summary", "amountValue", "sourceFile", "extractedAt"} {
+		if _, ok := labels[key]; ok {
+			t.Errorf("derived column %s should not be listed in fields", key)
+		}
+		if !slices.ContainsFunc(body.DerivedFields, func(f FieldCapability) bool { return f.Key == key }) {
+			t.Errorf("derived column %s missing from derivedFields: %v", key, body.DerivedFields)
+		}
+	}
+	if body.Providers == nil {
+		t.Error("providers should be a JSON array")
+	}
+}
+
+// newMultipartUpload 构造包含单个文件的 multipart 上传请求
+func newMultipartUpload(t *testing.T, target, fieldName, fileName string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile(fieldName, fileName)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	fw.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	return req
+}
+
+// readFixture 读取提取器的测试样本
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "internal", "extractor", "testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func TestNewServer_BasePath(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	e := newServer(normalizeBasePath("/legal-extractor/"))
+
+	req := newMultipartUpload(t, "/legal-extractor/api/extract", "file", "complaint.docx", readFixture(t, "complaint.docx"))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 under prefix, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp ExtractResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if !resp.Success || resp.RecordCount != 1 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+
+	// 未带前缀的路径不应再被路由
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without prefix, got %d", rec.Code)
+	}
+}
+
+func TestHandleExtract_Strict(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	extractorInstance.RequiredFields = []string{"thirdParty"}
+	e := newServer("")
+	post := func(target string) (*httptest.ResponseRecorder, ExtractResponse) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, newMultipartUpload(t, target, "file", "complaint.docx", readFixture(t, "complaint.docx")))
+		var resp ExtractResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		return rec, resp
+	}
+
+	// 默认丢弃不完整的记录
+	if rec, resp := post("/api/extract"); rec.Code != http.StatusOK || resp.RecordCount != 0 {
+		t.Errorf("Expected 200 with the incomplete record dropped, got %d: %+v", rec.Code, resp)
+	}
+	// strict=true 时整次提取失败并列出缺少的字段
+	rec, resp := post("/api/extract?strict=true")
+	if rec.Code != http.StatusUnprocessableEntity || resp.Success {
+		t.Fatalf("Expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(resp.Missing) != 1 || resp.Missing[0].Index != 1 || resp.Missing[0].Fields[0] != "thirdParty" {
+		t.Errorf("Expected record 1 missing thirdParty, got %+v", resp.Missing)
+	}
+	if rec, _ := post("/api/extract?strict=maybe"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid strict value, got %d", rec.Code)
+	}
+}
+
+func TestNormalizeBasePath(t *testing.T) {
+	for in, want := range map[string]string{"": "", "/": "", "prefix": "/prefix", "/prefix/": "/prefix", "/a/b": "/a/b"} {
+		if got := normalizeBasePath(in); got != want {
+			t.Errorf("normalizeBasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHandleExtract_DuplicateUploadHitsCache(t *testing.T) {
+	mock := extractor.NewMockOCRProvider()
+	extractorInstance = extractor.NewExtractor(nil)
+	extractorInstance.SetOCRProvider(mock)
+	resultCache = NewResultCache(time.Minute, 10)
+	defer func() { resultCache = nil }()
+
+	e := newServer("")
+	data := readFixture(t, "scanned.pdf")
+	var hits []string
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, newMultipartUpload(t, "/api/extract", "file", "scanned.pdf", data))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Upload %d: expected 200, got %d: %s", i+1, rec.Code, rec.Body.String())
+		}
+		hits = append(hits, rec.Header().Get("X-Cache"))
+	}
+
+	if hits[0] != "MISS" || hits[1] != "HIT" {
+		t.Errorf("Expected X-Cache MISS then HIT, got %v", hits)
+	}
+	if mock.Calls() != 1 {
+		t.Errorf("Expected a single OCR call, got %d", mock.Calls())
+	}
+}
+
+func TestHandleReparse_ReducedFieldSet(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	e := newServer("")
+
+	post := func(req *http.Request, wantCode int) ExtractResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != wantCode {
+			t.Fatalf("Expected %d, got %d: %s", wantCode, rec.Code, rec.Body.String())
+		}
+		var resp ExtractResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		return resp
+	}
+	reparse := func(hash string, fields []string, wantCode int) ExtractResponse {
+		body, _ := json.Marshal(ReparseRequest{FileHash: hash, Fields: fields})
+		req := httptest.NewRequest(http.MethodPost, "/api/extract/reparse", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		return post(req, wantCode)
+	}
+
+	first := post(newMultipartUpload(t, "/api/extract?fields=defendant&fields=request", "file", "complaint.docx", readFixture(t, "complaint.docx")), http.StatusOK)
+	if first.FileHash == "" || first.RecordCount != 1 || first.Records[0]["request"] == "" {
+		t.Fatalf("Unexpected extract response: %+v", first)
+	}
+
+	resp := reparse(first.FileHash, []string{"defendant"}, http.StatusOK)
+	if !resp.Success || resp.RecordCount != 1 || resp.FileHash != first.FileHash {
+		t.Fatalf("Unexpected reparse response: %+v", resp)
+	}
+	if got := resp.Records[0]; got["defendant"] != first.Records[0]["defendant"] || got["request"] != "" {
+		t.Errorf("Expected only the defendant, got %v", got)
+	}
+
+	if resp := reparse(strings.Repeat("0", 64), []string{"defendant"}, http.StatusNotFound); resp.Success {
+		t.Errorf("Unknown hash should fail, got %+v", resp)
+	}
+	reparse("", nil, http.StatusBadRequest)
+}
+
+func TestHandleExtract_JSONBase64(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	e := newServer("")
+	data := readFixture(t, "complaint.docx")
+
+	post := func(req *http.Request) ExtractResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp ExtractResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		return resp
+	}
+
+	multipartResp := post(newMultipartUpload(t, "/api/extract?fields=defendant&fields=request", "file", "complaint.docx", data))
+
+	body, _ := json.Marshal(ExtractRequest{
+		Filename:      "complaint.docx",
+		ContentBase64: base64.StdEncoding.EncodeToString(data),
+		Fields:        []string{"defendant", "request"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/extract", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	jsonResp := post(req)
+
+	if !jsonResp.Success || jsonResp.RecordCount == 0 {
+		t.Fatalf("Unexpected response: %+v", jsonResp)
+	}
+	if !reflect.DeepEqual(jsonResp, multipartResp) {
+		t.Errorf("JSON response differs from multipart:\n got %+v\nwant %+v", jsonResp, multipartResp)
+	}
+
+	// 扩展名与内容校验
+	for _, bad := range []ExtractRequest{
+		{Filename: "complaint.exe", ContentBase64: base64.StdEncoding.EncodeToString(data)},
+		{Filename: "complaint.docx", ContentBase64: "不是 base64"},
+	} {
+		body, _ := json.Marshal(bad)
+		req := httptest.NewRequest(http.MethodPost, "/api/extract", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %q, got %d", bad.Filename, rec.Code)
+		}
+	}
+}
+
+func TestHandleExtractBatch_GroupsBySourceFile(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	e := newServer("")
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"complaint.docx", readFixture(t, "complaint.docx")},
+		{"two_cases.docx", readFixture(t, "two_cases.docx")},
+		{"notes.xlsx", []byte("PK")},
+	}
+	for _, f := range files {
+		fw, err := mw.CreateFormFile("files", f.name)
+		if err != nil {
+			t.Fatalf("create form file: %v", err)
+		}
+		fw.Write(f.data)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/extract/batch?fields=defendant", &body)
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp BatchExtractResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+
+	if len(resp.Results) != len(files) {
+		t.Fatalf("Expected %d results, got %d", len(files), len(resp.Results))
+	}
+	wantRecords := []int{1, 2, 0}
+	total := 0
+	for i, res := range resp.Results {
+		if res.FileName != files[i].name {
+			t.Errorf("result %d: expected %s, got %s", i, files[i].name, res.FileName)
+		}
+		if len(res.Records) != wantRecords[i] {
+			t.Errorf("%s: expected %d records, got %d", res.FileName, wantRecords[i], len(res.Records))
+		}
+		for _, r := range res.Records {
+			if r[extractor.SourceKey] != res.FileName {
+				t.Errorf("%s: record tagged with source %q", res.FileName, r[extractor.SourceKey])
+			}
+		}
+		total += len(res.Records)
+	}
+	if resp.Total != total {
+		t.Errorf("Expected total %d, got %d", total, resp.Total)
+	}
+	if !strings.Contains(resp.Results[2].Error, "不支持的文件格式") {
+		t.Errorf("notes.xlsx: expected a per-file format error, got %q", resp.Results[2].Error)
+	}
+	if resp.Results[0].OCRUsed || resp.Results[1].OCRUsed {
+		t.Error("DOCX files should not be reported as OCR'd")
+	}
+	if len(resp.Merged) != 0 {
+		t.Errorf("Expected no merged list without merge=true or dedupe, got %d records", len(resp.Merged))
+	}
+}
+
+// 开启去重时另返回合并后的记录，同一案件的重复扫描件合并为一条
+func TestHandleExtractBatch_Dedupe(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	extractorInstance.Dedupe = &extractor.DedupeOptions{NameDistance: 1}
+	e := newServer("")
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, name := range []string{"scan_a.docx", "scan_b.docx"} {
+		fw, err := mw.CreateFormFile("files", name)
+		if err != nil {
+			t.Fatalf("create form file: %v", err)
+		}
+		fw.Write(readFixture(t, "complaint.docx"))
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/extract/batch?fields=defendant&fields=idNumber", &body)
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	var resp BatchExtractResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Merged) != 1 || resp.Merged[0][extractor.DuplicatesKey] != "scan_b.docx" {
+		t.Errorf("Expected 2 records merged into 1, got total %d, merged %v", resp.Total, resp.Merged)
+	}
+}
+
+func TestResultCache_Eviction(t *testing.T) {
+	rc := NewResultCache(time.Minute, 2)
+	rc.Put("a", []extractor.Record{{"defendant": "甲"}})
+	rc.Put("b", nil)
+	rc.Put("c", nil)
+
+	if _, ok := rc.Get("a"); ok {
+		t.Error("Oldest entry should have been evicted")
+	}
+	if _, ok := rc.Get("c"); !ok {
+		t.Error("Newest entry should be cached")
+	}
+	if NewResultCache(0, 10) != nil {
+		t.Error("Zero TTL should disable the cache")
+	}
+}
+
+func TestHandleScan(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	e := newServer("")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newMultipartUpload(t, "/api/scan", "file", "complaint.docx", readFixture(t, "complaint.docx")))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp ScanResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	keys := map[string]bool{}
+	for _, f := range resp.Fields {
+		keys[f.Key] = true
+	}
+	if !keys["defendant"] || !keys["idNumber"] {
+		t.Errorf("Expected defendant and idNumber in scan result, got %+v", resp.Fields)
+	}
+
+	// 与提取接口共用上传校验
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, newMultipartUpload(t, "/api/scan", "file", "notes.txt", []byte("hello")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unsupported type, got %d", rec.Code)
+	}
+}
+
+func TestTracingMiddleware_PropagatesTraceContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	}()
+
+	extractorInstance = extractor.NewExtractor(nil)
+	e := newServer("")
+	e.Use(TracingMiddleware())
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := newMultipartUpload(t, "/api/extract", "file", "complaint.docx", readFixture(t, "complaint.docx"))
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	names := map[string]bool{}
+	for _, s := range exporter.GetSpans() {
+		names[s.Name] = true
+		if got := s.SpanContext.TraceID().String(); got != traceID {
+			t.Errorf("Span %s has trace ID %s, want the incoming %s", s.Name, got, traceID)
+		}
+	}
+	if !names["POST /api/extract"] || !names["extractor.ExtractData"] {
+		t.Errorf("Expected server and extraction spans, got %v", names)
+	}
+}
+
+func TestHandleHealth_TempDirNotWritable(t *testing.T) {
+	defer func(orig string) { exportTempDir = orig }(exportTempDir)
+
+	// 以普通文件充当目录，无论是否以 root 运行都无法在其中创建文件
+	readOnly := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(readOnly, nil, 0o444); err != nil {
+		t.Fatal(err)
+	}
+	exportTempDir = readOnly
+
+	e := newServer("")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	// 只影响导出，报告为 degraded 而不是不可用
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Status   string            `json:"status"`
+		Degraded bool              `json:"degraded"`
+		Checks   map[string]string `json:"checks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if body.Status != "degraded" || !body.Degraded || !strings.Contains(body.Checks["tempDir"], readOnly) {
+		t.Errorf("Expected a degraded status naming the temp dir, got %s", rec.Body.String())
+	}
+
+	exportTempDir = t.TempDir()
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"degraded":false`) {
+		t.Errorf("Expected a healthy status for a writable temp dir, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleExport_CSV(t *testing.T) {
+	defer func(orig string) { exportTempDir = orig }(exportTempDir)
+	exportTempDir = t.TempDir()
+	// 强制走临时文件路径
+	defer func(orig int) { memoryExportLimit = orig }(memoryExportLimit)
+	memoryExportLimit = 0
+
+	body := `{"format":"csv","records":[{"defendant":"张三","idNumber":"110101199001011237"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/export?bom=false", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	newServer("").ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if want := "被告,身份证号码\n张三,110101199001011237\n"; rec.Body.String() != want {
+		t.Errorf("Unexpected export body %q, want %q", rec.Body.String(), want)
+	}
+	if got := rec.Header().Get(echo.HeaderContentLength); got != strconv.Itoa(rec.Body.Len()) {
+		t.Errorf("Content-Length = %q, body is %d bytes", got, rec.Body.Len())
+	}
+	if got := rec.Header().Get("Content-Disposition"); !strings.Contains(got, "extracted_data.csv") {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+
+	// 响应发送完毕后临时文件应已删除
+	if left, _ := filepath.Glob(filepath.Join(exportTempDir, "*")); len(left) != 0 {
+		t.Errorf("Temp files left behind: %v", left)
+	}
+}
+
+func TestHandleExport_InMemoryXLSX(t *testing.T) {
+	// 临时目录不可用时，小结果仍可在内存中导出
+	defer func(orig string) { exportTempDir = orig }(exportTempDir)
+	exportTempDir = filepath.Join(t.TempDir(), "missing")
+
+	body := `{"format":"xlsx","records":[{"defendant":"张三","idNumber":"110101199001011237"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/export", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	newServer("").ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	f, err := excelize.OpenReader(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("Response is not a valid xlsx: %v", err)
+	}
+	defer f.Close()
+	rows, err := f.GetRows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "张三" || rows[1][1] != "110101199001011237" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+func TestHandleExport_XLSXSanitize(t *testing.T) {
+	body := `{"format":"xlsx","records":[{"defendant":"=HYPERLINK(\"http://x\")","idNumber":"110101199001011237"}]}`
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{"", `=HYPERLINK("http://x")`},
+		{"?sanitize=true", `'=HYPERLINK("http://x")`},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/export"+tt.query, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		newServer("").ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q: expected 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+
+		f, err := excelize.OpenReader(bytes.NewReader(rec.Body.Bytes()))
+		if err != nil {
+			t.Fatalf("%q: response is not a valid xlsx: %v", tt.query, err)
+		}
+		got, err := f.GetCellValue("Sheet1", "A2")
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: A2 = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHandleProviderTest(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	extractorInstance.SetOCRProvider(extractor.NewMockOCRProvider())
+
+	e := newServer("")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/providers/test", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Results []extractor.ProviderCheck `json:"results"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(body.Results) != 1 || body.Results[0].Provider != "mock" || !body.Results[0].OK {
+		t.Errorf("Unexpected results: %+v", body.Results)
+	}
+
+	// 凭据校验单独限流，每 IP 每分钟最多 3 次
+	for range 2 {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/providers/test", nil))
+	}
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/providers/test", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 after 3 checks, got %d", rec.Code)
+	}
+}
+
+func TestCleanStaleExportFiles(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "export-1.csv")
+	fresh := filepath.Join(dir, "export-2.csv")
+	other := filepath.Join(dir, "keep.csv")
+	for _, p := range []string{stale, fresh, other} {
+		os.WriteFile(p, []byte("x"), 0o644)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(stale, old, old)
+	os.Chtimes(other, old, old)
+
+	if n := cleanStaleExportFiles(dir, time.Hour); n != 1 {
+		t.Errorf("Expected 1 stale file removed, got %d", n)
+	}
+	for p, want := range map[string]bool{stale: false, fresh: true, other: true} {
+		if _, err := os.Stat(p); (err == nil) != want {
+			t.Errorf("%s: exists=%v, want %v", filepath.Base(p), err == nil, want)
+		}
+	}
+}
+
+func TestHandleExtractText(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	e := newServer("")
+
+	text := "民事起诉状\n原告：甲公司\n被告：张三，住址：北京\n身份证号码：110101199001011234\n诉讼请求：\n偿还借款10000元。\n事实与理由：\n借款未还。\n此致\n"
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/extract/text?fields=defendant&fields=request", strings.NewReader(text))
+	req.Header.Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp ExtractResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if !resp.Success || resp.RecordCount != 1 {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+	r := resp.Records[0]
+	if r["defendant"] != "张三" || r["request"] == "" || r["idNumber"] != "" {
+		t.Errorf("Expected only the selected fields, got %v", r)
+	}
+
+	// 分页：三个案件取第二页
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/extract/text?fields=defendant&limit=2&offset=2", strings.NewReader(strings.Repeat(text, 3)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	e.ServeHTTP(rec, req)
+	resp = ExtractResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if resp.Total != 3 || resp.RecordCount != 1 || resp.Offset != 2 || resp.Limit != 2 {
+		t.Errorf("Expected the last of 3 records, got %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/extract/text?limit=-1", strings.NewReader(text)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a negative limit, got %d", rec.Code)
+	}
+
+	// 超出大小限制
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/extract/text", strings.NewReader(strings.Repeat("字", maxTextSize))))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for oversized text, got %d", rec.Code)
+	}
+
+	// 表单提交同样返回 413，而不是 400
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/extract/text", strings.NewReader("text="+strings.Repeat("a", maxTextSize)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized form body, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	req = newMultipartUpload(t, "/api/extract/text", "text", "text.txt", []byte(strings.Repeat("a", maxTextSize)))
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized multipart body, got %d", rec.Code)
+	}
+}
+
+func TestHandleUsageReset(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	extractorInstance.SetOCRProvider(extractor.NewMockOCRProvider())
+	extractorInstance.SetUsageCounter(extractor.NewUsageCounter(filepath.Join(t.TempDir(), "usage.json")))
+	extractorInstance.Usage().Record("mock", 1, 2, nil)
+
+	e := newServer("")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/usage/reset", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != "{}" {
+		t.Errorf("Expected an empty snapshot, got %s", got)
+	}
+	if len(extractorInstance.Usage().Snapshot()) != 0 {
+		t.Error("Usage should be cleared")
+	}
+}
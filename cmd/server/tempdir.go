@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// exportTempDir 导出文件的临时目录，为空时使用系统默认临时目录 (可通过 LEGAL_EXTRACTOR_TMPDIR 指定)
+var exportTempDir string
+
+// exportFilePattern 导出临时文件的命名模式，启动时按此清理残留文件
+const exportFilePattern = "export-*"
+
+// checkTempDirWritable 确认临时目录可写，避免只读或已满的文件系统直到导出时才以 500 暴露
+func checkTempDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".healthcheck-*")
+	if err != nil {
+		return fmt.Errorf("临时目录 %s 不可写: %w", displayTempDir(dir), err)
+	}
+	name := f.Name()
+	_, err = f.WriteString("ok")
+	f.Close()
+	os.Remove(name)
+	if err != nil {
+		return fmt.Errorf("临时目录 %s 写入失败: %w", displayTempDir(dir), err)
+	}
+	return nil
+}
+
+// cleanStaleExportFiles 删除超过 maxAge 的导出临时文件 (进程异常退出时遗留)，返回删除数量
+func cleanStaleExportFiles(dir string, maxAge time.Duration) int {
+	return cleanStaleFiles(dir, exportFilePattern, maxAge)
+}
+
+// cleanStaleFiles 删除 dir 中匹配 pattern 且超过 maxAge 的文件，返回删除数量
+func cleanStaleFiles(dir, pattern string, maxAge time.Duration) int {
+	matches, err := filepath.Glob(filepath.Join(displayTempDir(dir), pattern))
+	if err != nil {
+		return 0
+	}
+	removed := 0
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		if os.Remove(path) == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+// displayTempDir 返回实际生效的临时目录路径
+func displayTempDir(dir string) string {
+	if dir == "" {
+		return os.TempDir()
+	}
+	return dir
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// uploadFilePattern 分片上传临时文件的命名模式
+const uploadFilePattern = "upload-*"
+
+// uploadDirPattern 存放分片上传临时文件的私有子目录的命名模式。上传进度只保存在内存中，
+// 进程退出后未完成的上传无法续传，该目录随之整体删除
+const uploadDirPattern = "legal-extractor-uploads-*"
+
+// maxChunkSize 单个分片的大小上限
+const maxChunkSize = 32 << 20
+
+// 全局分片上传存储，为 nil 时分片上传接口不可用
+var uploadStore *UploadStore
+
+var (
+	errUploadNotFound = errors.New("上传不存在或已过期")
+	errOffsetMismatch = errors.New("分片偏移与已接收的长度不一致")
+	errUploadTooLarge = errors.New("上传内容超出声明的文件大小")
+)
+
+// UploadStatus 分片上传的进度，客户端断线后可据 received 续传
+type UploadStatus struct {
+	UploadID string `json:"uploadId"`
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+	Received int64  `json:"received"`
+}
+
+// UploadInitRequest 创建分片上传的请求体
+type UploadInitRequest struct {
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+}
+
+// UploadStore 管理进行中的分片上传：分片按顺序追加到临时文件，超过 ttl 未活动的上传会被清理
+type UploadStore struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+	mu      sync.Mutex
+	uploads map[string]*pendingUpload
+}
+
+type pendingUpload struct {
+	mu       sync.Mutex
+	status   UploadStatus
+	path     string
+	lastSeen time.Time
+}
+
+// NewUploadStore 创建分片上传存储，临时文件写入 dir (为空时使用系统临时目录)
+func NewUploadStore(dir string, ttl time.Duration, maxSize int64) *UploadStore {
+	return &UploadStore{
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: maxSize,
+		uploads: make(map[string]*pendingUpload),
+	}
+}
+
+// Init 登记一个新的上传并创建其临时文件
+func (s *UploadStore) Init(fileName string, size int64) (UploadStatus, error) {
+	s.Sweep()
+
+	f, err := os.CreateTemp(s.dir, uploadFilePattern)
+	if err != nil {
+		return UploadStatus{}, err
+	}
+	f.Close()
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		os.Remove(f.Name())
+		return UploadStatus{}, err
+	}
+
+	u := &pendingUpload{
+		status:   UploadStatus{UploadID: hex.EncodeToString(idBytes), FileName: fileName, Size: size},
+		path:     f.Name(),
+		lastSeen: time.Now(),
+	}
+	s.mu.Lock()
+	s.uploads[u.status.UploadID] = u
+	s.mu.Unlock()
+	return u.status, nil
+}
+
+// Status 返回上传进度
+func (s *UploadStore) Status(id string) (UploadStatus, error) {
+	u, err := s.get(id)
+	if err != nil {
+		return UploadStatus{}, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.status, nil
+}
+
+// Append 在 offset 处写入分片。offset 必须不超过已接收长度：
+// 完全重复的分片 (如客户端超时后重发) 直接忽略，部分重叠时只追加新内容；
+// offset 大于已接收长度说明中间有分片缺失，返回 errOffsetMismatch，客户端应按 received 续传
+func (s *UploadStore) Append(id string, offset int64, data []byte) (UploadStatus, error) {
+	u, err := s.get(id)
+	if err != nil {
+		return UploadStatus{}, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.lastSeen = time.Now()
+
+	received := u.status.Received
+	if offset < 0 || offset > received {
+		return u.status, errOffsetMismatch
+	}
+	if offset+int64(len(data)) > u.status.Size {
+		return u.status, errUploadTooLarge
+	}
+	skip := received - offset
+	if skip >= int64(len(data)) {
+		return u.status, nil
+	}
+
+	f, err := os.OpenFile(u.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return u.status, err
+	}
+	n, err := f.Write(data[skip:])
+	u.status.Received += int64(n)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return u.status, err
+}
+
+// Complete 读取已接收完整的文件并结束上传，未接收完整时返回 errOffsetMismatch
+func (s *UploadStore) Complete(id string) (UploadStatus, []byte, error) {
+	u, err := s.get(id)
+	if err != nil {
+		return UploadStatus{}, nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.status.Received != u.status.Size {
+		return u.status, nil, errOffsetMismatch
+	}
+
+	data, err := os.ReadFile(u.path)
+	if err != nil {
+		return u.status, nil, err
+	}
+	s.remove(id)
+	return u.status, data, nil
+}
+
+// Sweep 清理超过 ttl 未活动的上传，返回清理数量
+func (s *UploadStore) Sweep() int {
+	s.mu.Lock()
+	pending := make(map[string]*pendingUpload, len(s.uploads))
+	for id, u := range s.uploads {
+		pending[id] = u
+	}
+	s.mu.Unlock()
+
+	// 逐个加锁检查，避免与持有上传锁后再删除的 Complete 形成锁顺序反转
+	removed := 0
+	for id, u := range pending {
+		u.mu.Lock()
+		if time.Since(u.lastSeen) > s.ttl {
+			s.remove(id)
+			removed++
+		}
+		u.mu.Unlock()
+	}
+	return removed
+}
+
+// StartJanitor 启动后台协程，每隔 ttl/2 清理一次超时的上传 (Init 时的清理只在有新上传时发生)，
+// onSwept 在清理到上传时收到数量，可为 nil。返回的函数停止清理并等待协程退出
+func (s *UploadStore) StartJanitor(onSwept func(n int)) (stop func()) {
+	interval := s.ttl / 2
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if n := s.Sweep(); n > 0 && onSwept != nil {
+					onSwept(n)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
+
+func (s *UploadStore) get(id string) (*pendingUpload, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.uploads[id]
+	if !ok {
+		return nil, errUploadNotFound
+	}
+	return u, nil
+}
+
+func (s *UploadStore) remove(id string) {
+	s.mu.Lock()
+	u, ok := s.uploads[id]
+	delete(s.uploads, id)
+	s.mu.Unlock()
+	if ok {
+		os.Remove(u.path)
+	}
+}
+
+// rateLimitExempt 分片请求不计入限流：一个大文件可能包含数十个分片，init 与 complete 仍受限
+func rateLimitExempt(c echo.Context) bool {
+	return strings.HasSuffix(c.Path(), "/upload/:id/chunk")
+}
+
+// handleUploadInit 创建分片上传，返回 uploadId
+func handleUploadInit(c echo.Context) error {
+	if uploadStore == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "分片上传未启用"})
+	}
+	var req UploadInitRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "请求格式错误"})
+	}
+	if ext := strings.ToLower(filepath.Ext(req.FileName)); !allowedUploadExts[ext] {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("不支持的文件格式: %s，支持 PDF、DOCX、EML、JPG、PNG", ext),
+		})
+	}
+	if req.Size <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "请提供文件大小"})
+	}
+	if req.Size > uploadStore.maxSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("文件超过大小上限 %d MB", uploadStore.maxSize>>20),
+		})
+	}
+
+	status, err := uploadStore.Init(req.FileName, req.Size)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("创建上传失败: %v", err)})
+	}
+	return c.JSON(http.StatusCreated, status)
+}
+
+// handleUploadStatus 查询上传进度，用于断线后续传
+func handleUploadStatus(c echo.Context) error {
+	if uploadStore == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "分片上传未启用"})
+	}
+	status, err := uploadStore.Status(c.Param("id"))
+	if err != nil {
+		return c.JSON(uploadErrorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// handleUploadChunk 追加一个分片，请求体为原始字节，offset 查询参数为该分片在文件中的起始位置
+func handleUploadChunk(c echo.Context) error {
+	if uploadStore == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "分片上传未启用"})
+	}
+	offset, err := strconv.ParseInt(c.QueryParam("offset"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "无效的 offset 参数"})
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request().Body, maxChunkSize+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "读取分片失败"})
+	}
+	if len(data) > maxChunkSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("单个分片不能超过 %d MB", maxChunkSize>>20),
+		})
+	}
+
+	status, err := uploadStore.Append(c.Param("id"), offset, data)
+	if err != nil {
+		return c.JSON(uploadErrorStatus(err), map[string]any{"error": err.Error(), "received": status.Received})
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// handleUploadComplete 组装已上传的文件并提取，查询参数与 /api/extract 相同
+func handleUploadComplete(c echo.Context) error {
+	if uploadStore == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "分片上传未启用"})
+	}
+	status, data, err := uploadStore.Complete(c.Param("id"))
+	if err != nil {
+		return c.JSON(uploadErrorStatus(err), ExtractResponse{
+			Success: false,
+			Error:   fmt.Sprintf("%v (已接收 %d / %d 字节)", err, status.Received, status.Size),
+		})
+	}
+	return extractAndRespond(c, status.FileName, data, queryFields(c))
+}
+
+// uploadErrorStatus 将上传错误映射为 HTTP 状态码
+func uploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errUploadNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errOffsetMismatch):
+		return http.StatusConflict
+	case errors.Is(err, errUploadTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"legal-extractor/internal/extractor"
+)
+
+func TestChunkedUpload_OutOfOrderAndDuplicateChunks(t *testing.T) {
+	extractorInstance = extractor.NewExtractor(nil)
+	uploadStore = NewUploadStore(t.TempDir(), time.Hour, 10<<20)
+	defer func() { uploadStore = nil }()
+	e := newServer("")
+
+	data := readFixture(t, "complaint.docx")
+	post := func(target string, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body)))
+		return rec
+	}
+
+	initBody, _ := json.Marshal(UploadInitRequest{FileName: "complaint.docx", Size: int64(len(data))})
+	req := httptest.NewRequest(http.MethodPost, "/api/upload/init", bytes.NewReader(initBody))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("init: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var status UploadStatus
+	json.Unmarshal(rec.Body.Bytes(), &status)
+	chunkURL := func(offset int) string {
+		return fmt.Sprintf("/api/upload/%s/chunk?offset=%d", status.UploadID, offset)
+	}
+
+	half := len(data) / 2
+	// 第二个分片先到：应拒绝并告知已接收长度
+	if rec := post(chunkURL(half), data[half:]); rec.Code != http.StatusConflict {
+		t.Fatalf("out-of-order chunk: expected 409, got %d", rec.Code)
+	}
+	// 提前完成：文件不完整
+	if rec := post("/api/upload/"+status.UploadID+"/complete", nil); rec.Code != http.StatusConflict {
+		t.Fatalf("early complete: expected 409, got %d", rec.Code)
+	}
+
+	for _, offset := range []int{0, 0} { // 重复发送第一个分片
+		rec := post(chunkURL(offset), data[:half])
+		if rec.Code != http.StatusOK {
+			t.Fatalf("chunk at %d: expected 200, got %d: %s", offset, rec.Code, rec.Body.String())
+		}
+		json.Unmarshal(rec.Body.Bytes(), &status)
+		if status.Received != int64(half) {
+			t.Errorf("received = %d after chunk at %d, want %d", status.Received, offset, half)
+		}
+	}
+	if rec := post(chunkURL(half), data[half:]); rec.Code != http.StatusOK {
+		t.Fatalf("second chunk: expected 200, got %d", rec.Code)
+	}
+
+	rec = post("/api/upload/"+status.UploadID+"/complete", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("complete: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp ExtractResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if !resp.Success || resp.RecordCount != 1 {
+		t.Errorf("Unexpected extract response: %+v", resp)
+	}
+
+	// 完成后上传即被清理
+	if rec := post(chunkURL(0), data[:half]); rec.Code != http.StatusNotFound {
+		t.Errorf("chunk after complete: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestUploadStore_LimitsAndTTL(t *testing.T) {
+	store := NewUploadStore(t.TempDir(), time.Millisecond, 1<<20)
+	status, err := store.Init("a.pdf", 4)
+	if err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+	if _, err := store.Append(status.UploadID, 0, []byte("12345")); err != errUploadTooLarge {
+		t.Errorf("Expected errUploadTooLarge, got %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if n := store.Sweep(); n != 1 {
+		t.Errorf("Expected 1 abandoned upload to be swept, got %d", n)
+	}
+	if _, err := store.Status(status.UploadID); err != errUploadNotFound {
+		t.Errorf("Expected errUploadNotFound after sweep, got %v", err)
+	}
+}
+
+func TestUploadStore_Janitor(t *testing.T) {
+	store := NewUploadStore(t.TempDir(), 10*time.Millisecond, 1<<20)
+	status, err := store.Init("a.pdf", 4)
+	if err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+
+	swept := make(chan int, 1)
+	stop := store.StartJanitor(func(n int) { swept <- n })
+	defer stop()
+	select {
+	case n := <-swept:
+		if n != 1 {
+			t.Errorf("Expected 1 abandoned upload to be swept, got %d", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Janitor did not sweep the abandoned upload")
+	}
+	if _, err := store.Status(status.UploadID); err != errUploadNotFound {
+		t.Errorf("Expected errUploadNotFound after sweep, got %v", err)
+	}
+	stop()
+}
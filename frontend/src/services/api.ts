@@ -9,10 +9,26 @@
 // 类型定义
 export interface Record {
   [key: string]: string | undefined;
+  plaintiff?: string;
+  plaintiffId?: string;
+  plaintiffPhone?: string;
+  plaintiffAgent?: string;
+  plaintiffLawFirm?: string;
+  agentAuthority?: string;
   defendant?: string;
+  gender?: string;
+  birthday?: string;
   idNumber?: string;
+  creditCode?: string;
+  thirdParty?: string;
+  thirdPartyId?: string;
+  caseCause?: string;
   request?: string;
   factsReason?: string;
+  sourceFile?: string;
+  sourceHash?: string;
+  sourcePage?: string;
+  extractedAt?: string;
 }
 
 export interface ExtractResult {
@@ -22,6 +38,7 @@ export interface ExtractResult {
   errorMessage?: string;
   records?: Record[];
   fieldLabels?: { [key: string]: string };
+  fileHash?: string; // Web 模式：可用于 /api/extract/reparse 改选字段重新解析
 }
 
 export interface FieldOption {
@@ -149,7 +166,7 @@ class WebAdapter implements IApiService {
     return new Promise((resolve, reject) => {
       const input = document.createElement('input');
       input.type = 'file';
-      input.accept = '.pdf,.docx,.jpg,.jpeg,.png';
+      input.accept = '.pdf,.docx,.eml,.jpg,.jpeg,.png';
 
       input.onchange = (e) => {
         const file = (e.target as HTMLInputElement).files?.[0];
@@ -213,18 +230,21 @@ class WebAdapter implements IApiService {
   }
 
   async scanFields(file: File): Promise<FieldOption[]> {
-    // 复用 previewData 接口获取字段信息
-    const result = await this.previewData(file, ['defendant', 'idNumber', 'request', 'factsReason']);
+    // 轻量扫描接口，不触发完整提取
+    const formData = new FormData();
+    formData.append('file', file);
 
-    if (!result.success || !result.fieldLabels) {
+    const response = await fetch(`${this.baseUrl}/api/scan`, {
+      method: 'POST',
+      body: formData,
+    });
+
+    if (!response.ok) {
       return [];
     }
 
-    // 转换为 FieldOption 数组
-    return Object.entries(result.fieldLabels).map(([key, label]) => ({
-      key,
-      label: label as string,
-    }));
+    const result = await response.json();
+    return result.fields || [];
   }
 
   async openFile(_path: string): Promise<void> {
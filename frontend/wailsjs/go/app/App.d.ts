@@ -6,20 +6,48 @@ import {config} from '../models';
 
 export function Activate(arg1:string):Promise<boolean>;
 
+export function DiffRecords(arg1:Array<extractor.Record>,arg2:Array<extractor.Record>,arg3:string):Promise<Array<extractor.FieldDiff>>;
+
+export function EstimateCost(arg1:string):Promise<extractor.CostEstimate>;
+
 export function ExportData(arg1:Array<extractor.Record>,arg2:string):Promise<app.ExtractResult>;
 
+export function ExportDataWithOptions(arg1:Array<extractor.Record>,arg2:string,arg3:extractor.ExportOptions):Promise<app.ExtractResult>;
+
+export function ExportGrouped(arg1:Array<extractor.Record>,arg2:string,arg3:string,arg4:extractor.ExportOptions):Promise<app.ExtractResult>;
+
+export function ExportTables(arg1:string,arg2:string):Promise<app.ExtractResult>;
+
+export function ExportWithTemplate(arg1:Array<extractor.Record>,arg2:string,arg3:string):Promise<app.ExtractResult>;
+
+export function ExtractFromImageBytes(arg1:Array<number>,arg2:string,arg3:Array<string>):Promise<app.ExtractResult>;
+
 export function ExtractToPath(arg1:string,arg2:string,arg3:Array<string>):Promise<app.ExtractResult>;
 
+export function GetActivePatterns():Promise<extractor.ActivePatterns>;
+
 export function GetMachineID():Promise<string>;
 
 export function GetTrialStatus():Promise<config.TrialStatus>;
 
+export function GetUsage():Promise<{[key: string]: extractor.ProviderUsage}>;
+
+export function GetVersionInfo():Promise<extractor.ComponentVersions>;
+
 export function OpenFile(arg1:string):Promise<void>;
 
 export function PreviewData(arg1:string,arg2:Array<string>):Promise<app.ExtractResult>;
 
+export function ResetUsage():Promise<void>;
+
 export function ScanFields(arg1:string):Promise<Array<app.FieldOption>>;
 
 export function SelectFile():Promise<string>;
 
+export function SelectOutputDir():Promise<string>;
+
 export function SelectOutputPath(arg1:string):Promise<string>;
+
+export function SelectTemplateFile():Promise<string>;
+
+export function TestProvider(arg1:string):Promise<extractor.ProviderCheck>;
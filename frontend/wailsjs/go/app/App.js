@@ -6,14 +6,46 @@ export function Activate(arg1) {
   return window['go']['app']['App']['Activate'](arg1);
 }
 
+export function DiffRecords(arg1, arg2, arg3) {
+  return window['go']['app']['App']['DiffRecords'](arg1, arg2, arg3);
+}
+
+export function EstimateCost(arg1) {
+  return window['go']['app']['App']['EstimateCost'](arg1);
+}
+
 export function ExportData(arg1, arg2) {
   return window['go']['app']['App']['ExportData'](arg1, arg2);
 }
 
+export function ExportDataWithOptions(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ExportDataWithOptions'](arg1, arg2, arg3);
+}
+
+export function ExportGrouped(arg1, arg2, arg3, arg4) {
+  return window['go']['app']['App']['ExportGrouped'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportTables(arg1, arg2) {
+  return window['go']['app']['App']['ExportTables'](arg1, arg2);
+}
+
+export function ExportWithTemplate(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ExportWithTemplate'](arg1, arg2, arg3);
+}
+
+export function ExtractFromImageBytes(arg1, arg2, arg3) {
+  return window['go']['app']['App']['ExtractFromImageBytes'](arg1, arg2, arg3);
+}
+
 export function ExtractToPath(arg1, arg2, arg3) {
   return window['go']['app']['App']['ExtractToPath'](arg1, arg2, arg3);
 }
 
+export function GetActivePatterns() {
+  return window['go']['app']['App']['GetActivePatterns']();
+}
+
 export function GetMachineID() {
   return window['go']['app']['App']['GetMachineID']();
 }
@@ -22,6 +54,14 @@ export function GetTrialStatus() {
   return window['go']['app']['App']['GetTrialStatus']();
 }
 
+export function GetUsage() {
+  return window['go']['app']['App']['GetUsage']();
+}
+
+export function GetVersionInfo() {
+  return window['go']['app']['App']['GetVersionInfo']();
+}
+
 export function OpenFile(arg1) {
   return window['go']['app']['App']['OpenFile'](arg1);
 }
@@ -30,6 +70,10 @@ export function PreviewData(arg1, arg2) {
   return window['go']['app']['App']['PreviewData'](arg1, arg2);
 }
 
+export function ResetUsage() {
+  return window['go']['app']['App']['ResetUsage']();
+}
+
 export function ScanFields(arg1) {
   return window['go']['app']['App']['ScanFields'](arg1);
 }
@@ -38,6 +82,18 @@ export function SelectFile() {
   return window['go']['app']['App']['SelectFile']();
 }
 
+export function SelectOutputDir() {
+  return window['go']['app']['App']['SelectOutputDir']();
+}
+
 export function SelectOutputPath(arg1) {
   return window['go']['app']['App']['SelectOutputPath'](arg1);
 }
+
+export function SelectTemplateFile() {
+  return window['go']['app']['App']['SelectTemplateFile']();
+}
+
+export function TestProvider(arg1) {
+  return window['go']['app']['App']['TestProvider'](arg1);
+}
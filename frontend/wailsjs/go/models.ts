@@ -4,6 +4,7 @@ export namespace app {
 	    success: boolean;
 	    recordCount: number;
 	    outputPath: string;
+	    outputPaths?: string[];
 	    errorMessage?: string;
 	    records?: any[];
 	    fieldLabels?: Record<string, string>;
@@ -17,6 +18,7 @@ export namespace app {
 	        this.success = source["success"];
 	        this.recordCount = source["recordCount"];
 	        this.outputPath = source["outputPath"];
+	        this.outputPaths = source["outputPaths"];
 	        this.errorMessage = source["errorMessage"];
 	        this.records = source["records"];
 	        this.fieldLabels = source["fieldLabels"];
@@ -64,3 +66,257 @@ export namespace config {
 
 }
 
+export namespace extractor {
+	
+	export class FieldDiff {
+	    index: number;
+	    actualIndex: number;
+	    kind: string;
+	    field?: string;
+	    expected: string;
+	    actual: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.actualIndex = source["actualIndex"];
+	        this.kind = source["kind"];
+	        this.field = source["field"];
+	        this.expected = source["expected"];
+	        this.actual = source["actual"];
+	    }
+	}
+	export class FieldPattern {
+	    field: string;
+	    label: string;
+	    pattern: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldPattern(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.label = source["label"];
+	        this.pattern = source["pattern"];
+	    }
+	}
+	export class ActivePatterns {
+	    fields: FieldPattern[];
+	    patterns: {[key: string]: string};
+	    defStopChars: string;
+	    defMaxRunes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActivePatterns(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fields = this.convertValues(source["fields"], FieldPattern);
+	        this.patterns = source["patterns"];
+	        this.defStopChars = source["defStopChars"];
+	        this.defMaxRunes = source["defMaxRunes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProviderVersion {
+	    name: string;
+	    endpoint?: string;
+	    version?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderVersion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.endpoint = source["endpoint"];
+	        this.version = source["version"];
+	    }
+	}
+	export class ComponentVersions {
+	    bridgeVersion: string;
+	    providers: ProviderVersion[];
+	    patternSet: string;
+	    patternSetHash: string;
+	    patternOverrides: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ComponentVersions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bridgeVersion = source["bridgeVersion"];
+	        this.providers = this.convertValues(source["providers"], ProviderVersion);
+	        this.patternSet = source["patternSet"];
+	        this.patternSetHash = source["patternSetHash"];
+	        this.patternOverrides = source["patternOverrides"];
+	    }
+	
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CostEstimate {
+	    totalPages: number;
+	    textPages: number;
+	    scannedPages: number;
+	    needsOcr: boolean;
+	    provider: string;
+	    ocrPages: number;
+	    ocrCalls: number;
+	    estimatedCost: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CostEstimate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.totalPages = source["totalPages"];
+	        this.textPages = source["textPages"];
+	        this.scannedPages = source["scannedPages"];
+	        this.needsOcr = source["needsOcr"];
+	        this.provider = source["provider"];
+	        this.ocrPages = source["ocrPages"];
+	        this.ocrCalls = source["ocrCalls"];
+	        this.estimatedCost = source["estimatedCost"];
+	    }
+	}
+	export class ExportOptions {
+	    bom: boolean;
+	    sanitizeFormulas: boolean;
+	    append: boolean;
+	    styled: boolean;
+	    summary: boolean;
+	    summaryOnly: boolean;
+	    summaryTemplate: string;
+	    headers: {[key: string]: string};
+	    encoding: string;
+	    groupBy: string;
+	    long: boolean;
+	    docTypes: string[];
+	    checksum: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bom = source["bom"];
+	        this.sanitizeFormulas = source["sanitizeFormulas"];
+	        this.append = source["append"];
+	        this.styled = source["styled"];
+	        this.summary = source["summary"];
+	        this.summaryOnly = source["summaryOnly"];
+	        this.summaryTemplate = source["summaryTemplate"];
+	        this.headers = source["headers"];
+	        this.encoding = source["encoding"];
+	        this.groupBy = source["groupBy"];
+	        this.long = source["long"];
+	        this.docTypes = source["docTypes"];
+	        this.checksum = source["checksum"];
+	    }
+	}
+	export class ProviderCheck {
+	    provider: string;
+	    ok: boolean;
+	    error?: string;
+	    hint?: string;
+	    latencyMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.provider = source["provider"];
+	        this.ok = source["ok"];
+	        this.error = source["error"];
+	        this.hint = source["hint"];
+	        this.latencyMs = source["latencyMs"];
+	    }
+	}
+	export class ProviderUsage {
+	    calls: number;
+	    pages: number;
+	    failures: number;
+	    quotaExceeded: number;
+	    // Go type: time
+	    lastUsed: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProviderUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.calls = source["calls"];
+	        this.pages = source["pages"];
+	        this.failures = source["failures"];
+	        this.quotaExceeded = source["quotaExceeded"];
+	        this.lastUsed = this.convertValues(source["lastUsed"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
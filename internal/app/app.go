@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"runtime"
@@ -55,14 +56,49 @@ func (a *App) Activate(licenseKey string) (bool, error) {
 	return false, fmt.Errorf("授权码无效，请检查后重试")
 }
 
+// GetUsage 返回各识别引擎的累计调用量 (调用次数、页数、失败次数)
+func (a *App) GetUsage() map[string]extractor.ProviderUsage {
+	return a.extractor.Usage().Snapshot()
+}
+
+// ResetUsage 清零调用量统计
+func (a *App) ResetUsage() error {
+	return a.extractor.Usage().Reset()
+}
+
+// GetActivePatterns 返回当前生效的提取正则 (含配置覆盖)，便于排查字段为何未匹配
+func (a *App) GetActivePatterns() extractor.ActivePatterns {
+	return a.extractor.ActivePatterns()
+}
+
+// GetVersionInfo 返回桥接工具、识别服务地址与提取规则的版本信息，便于反馈问题时附上
+func (a *App) GetVersionInfo() extractor.ComponentVersions {
+	return a.extractor.Versions(a.ctx)
+}
+
+// DiffRecords 以 expected (人工核对过的结果) 为基准比对 actual 的逐字段差异，用于识别质量抽检。
+// match 为配对方式："id" (按身份证号码，默认) 或 "order" (按顺序)
+func (a *App) DiffRecords(expected, actual []extractor.Record, match string) ([]extractor.FieldDiff, error) {
+	strategy, err := extractor.ParseMatchStrategy(match)
+	if err != nil {
+		return nil, err
+	}
+	return extractor.DiffRecords(expected, actual, strategy), nil
+}
+
+// TestProvider 对指定识别引擎 (如 "baidu") 发起一次不计费的认证请求，验证凭据是否可用
+func (a *App) TestProvider(name string) extractor.ProviderCheck {
+	return a.extractor.TestProvider(name)
+}
+
 // SelectFile opens a file dialog to select a .docx file
 func (a *App) SelectFile() (string, error) {
 	file, err := wr.OpenFileDialog(a.ctx, wr.OpenDialogOptions{
 		Title: "Select Legal Document (.docx)",
 		Filters: []wr.FileFilter{
 			{
-				DisplayName: "Legal Documents (*.docx;*.pdf)",
-				Pattern:     "*.docx;*.pdf",
+				DisplayName: "Legal Documents (*.docx;*.pdf;*.eml)",
+				Pattern:     "*.docx;*.pdf;*.eml",
 			},
 		},
 	})
@@ -77,6 +113,7 @@ type ExtractResult struct {
 	Success      bool               `json:"success"`
 	RecordCount  int                `json:"recordCount"`
 	OutputPath   string             `json:"outputPath"`
+	OutputPaths  []string           `json:"outputPaths,omitempty"` // Files written by a grouped export
 	ErrorMessage string             `json:"errorMessage,omitempty"`
 	Records      []extractor.Record `json:"records,omitempty"`
 	FieldLabels  map[string]string  `json:"fieldLabels,omitempty"` // Map of key -> Chinese label
@@ -88,13 +125,20 @@ type FieldOption struct {
 	Label string `json:"label"`
 }
 
-// ScanFields 返回系统支持的可提取字段列表 (优化：本地静态返回，避免 API 调用费)
+// ScanFields 返回文档中可提取的字段列表 (仅做本地轻量扫描，不产生 API 调用费)
 func (a *App) ScanFields(inputFile string) ([]FieldOption, error) {
-	var options []FieldOption
-	// 定义提取器支持的核心字段
-	orderedKeys := []string{"defendant", "idNumber", "request", "factsReason"}
+	fileData, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	// 加密 PDF、缺少识别工具等错误直接返回，由前端提示用户，而不是展示注定提取不到的字段
+	keys, err := a.extractor.ScanFields(fileData, inputFile)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, k := range orderedKeys {
+	var options []FieldOption
+	for _, k := range keys {
 		if p, ok := extractor.PatternRegistry[k]; ok {
 			options = append(options, FieldOption{
 				Key:   k,
@@ -128,10 +172,22 @@ func (a *App) SelectOutputPath(defaultName string) (string, error) {
 				DisplayName: "CSV Files (*.csv)",
 				Pattern:     "*.csv",
 			},
+			{
+				DisplayName: "TSV Files (*.tsv)",
+				Pattern:     "*.tsv",
+			},
 			{
 				DisplayName: "JSON Files (*.json)",
 				Pattern:     "*.json",
 			},
+			{
+				DisplayName: "Text Report (*.txt)",
+				Pattern:     "*.txt",
+			},
+			{
+				DisplayName: "Parquet Files (*.parquet)",
+				Pattern:     "*.parquet",
+			},
 		},
 	})
 
@@ -201,6 +257,11 @@ func (a *App) ExtractToPath(inputPath, outputPath string, fields []string) Extra
 
 // ExportData 接收用户编辑后的数据并直接保存到指定路径
 func (a *App) ExportData(records []extractor.Record, outputPath string) ExtractResult {
+	return a.ExportDataWithOptions(records, outputPath, extractor.DefaultExportOptions())
+}
+
+// ExportDataWithOptions 按指定导出选项 (如是否写入 BOM、是否追加到已有 JSON) 保存数据
+func (a *App) ExportDataWithOptions(records []extractor.Record, outputPath string, opts extractor.ExportOptions) ExtractResult {
 	if len(records) == 0 || outputPath == "" {
 		return ExtractResult{
 			Success:      false,
@@ -208,26 +269,137 @@ func (a *App) ExportData(records []extractor.Record, outputPath string) ExtractR
 		}
 	}
 
-	var err error
-	lowerPath := strings.ToLower(outputPath)
-	if strings.HasSuffix(lowerPath, ".json") {
-		err = extractor.ExportJSON(outputPath, records)
-	} else if strings.HasSuffix(lowerPath, ".xlsx") {
-		err = extractor.ExportExcel(outputPath, records)
-	} else {
-		err = extractor.ExportCSV(outputPath, records)
+	err := extractor.ExportFile(outputPath, records, opts)
+	if err != nil {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("导出失败: %v", err),
+		}
+	}
+
+	return ExtractResult{
+		Success:     true,
+		RecordCount: len(records),
+		OutputPath:  outputPath,
 	}
+}
 
+// SelectOutputDir opens a directory dialog for grouped exports
+func (a *App) SelectOutputDir() (string, error) {
+	return wr.OpenDirectoryDialog(a.ctx, wr.OpenDialogOptions{
+		Title:                "Select Output Folder",
+		CanCreateDirectories: true,
+	})
+}
+
+// ExportGrouped 按 opts.GroupBy 字段分组，每组导出一个 format 格式的文件 (如 "xlsx") 到 outputDir
+func (a *App) ExportGrouped(records []extractor.Record, outputDir, format string, opts extractor.ExportOptions) ExtractResult {
+	if len(records) == 0 || outputDir == "" {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: "无有效数据或未指定输出目录",
+		}
+	}
+
+	paths, err := extractor.ExportGrouped(outputDir, format, records, opts)
 	if err != nil {
 		return ExtractResult{
 			Success:      false,
 			ErrorMessage: fmt.Sprintf("导出失败: %v", err),
+			OutputPaths:  paths,
 		}
 	}
 
 	return ExtractResult{
 		Success:     true,
 		RecordCount: len(records),
+		OutputPath:  outputDir,
+		OutputPaths: paths,
+	}
+}
+
+// SelectTemplateFile opens a file dialog to select an export template (.yaml/.json)
+func (a *App) SelectTemplateFile() (string, error) {
+	return wr.OpenFileDialog(a.ctx, wr.OpenDialogOptions{
+		Title: "Select Export Template",
+		Filters: []wr.FileFilter{
+			{
+				DisplayName: "Templates (*.yaml;*.yml;*.json)",
+				Pattern:     "*.yaml;*.yml;*.json",
+			},
+		},
+	})
+}
+
+// ExportWithTemplate 按模板文件规定的表头与列顺序导出 Excel，模板中未对应字段的列留空
+func (a *App) ExportWithTemplate(records []extractor.Record, templatePath, outputPath string) ExtractResult {
+	if templatePath == "" || outputPath == "" {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: "未指定模板文件或输出路径",
+		}
+	}
+
+	tmpl, err := extractor.LoadExcelTemplate(templatePath)
+	if err != nil {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("读取模板失败: %v", err),
+		}
+	}
+	if err := extractor.ExportExcelTemplate(outputPath, records, tmpl); err != nil {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("导出失败: %v", err),
+		}
+	}
+
+	return ExtractResult{
+		Success:     true,
+		RecordCount: len(records),
+		OutputPath:  outputPath,
+	}
+}
+
+// ExportTables 将文档中的表格 (如证据清单、还款计划) 导出为 Excel，每张表一个工作表；RecordCount 为表格数
+func (a *App) ExportTables(inputPath, outputPath string) ExtractResult {
+	if inputPath == "" || outputPath == "" {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: "Invalid input or output path",
+		}
+	}
+
+	fileData, err := os.ReadFile(inputPath)
+	if err != nil {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("Failed to read file: %v", err),
+		}
+	}
+	tables, err := a.extractor.ExtractTables(fileData, inputPath)
+	if err != nil {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: err.Error(),
+		}
+	}
+	if len(tables) == 0 {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: "文档中没有表格",
+		}
+	}
+	if err := extractor.ExportTablesExcel(outputPath, tables); err != nil {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("导出失败: %v", err),
+		}
+	}
+
+	return ExtractResult{
+		Success:     true,
+		RecordCount: len(tables),
 		OutputPath:  outputPath,
 	}
 }
@@ -296,6 +468,77 @@ func (a *App) PreviewData(inputPath string, fields []string) ExtractResult {
 	}
 }
 
+// EstimateCost 估算处理指定文件所需的页数与识别费用，避免大批量扫描件意外消耗配额
+func (a *App) EstimateCost(inputPath string) (extractor.CostEstimate, error) {
+	fileData, err := os.ReadFile(inputPath)
+	if err != nil {
+		return extractor.CostEstimate{}, fmt.Errorf("读取文件失败: %w", err)
+	}
+	return a.extractor.EstimateCost(fileData, inputPath)
+}
+
+// maxClipboardImageSize 剪贴板图片的大小上限
+const maxClipboardImageSize = 10 << 20
+
+// ExtractFromImageBytes 识别剪贴板粘贴的图片 (PNG/JPEG)，无需先保存为文件
+func (a *App) ExtractFromImageBytes(data []byte, mimeType string, fields []string) ExtractResult {
+	a.extractor.Logger().Info("收到剪贴板图片识别请求", "mimeType", mimeType, "size", len(data))
+	if config.GetTrialStatus().IsExpired {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: "试用期已结束（限 7 天），识别功能已锁定。请联系开发者获取正式版。",
+		}
+	}
+
+	if len(data) == 0 {
+		return ExtractResult{Success: false, ErrorMessage: "剪贴板中没有图片"}
+	}
+	if len(data) > maxClipboardImageSize {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("图片过大 (%.1f MB)，请控制在 %d MB 以内", float64(len(data))/(1<<20), maxClipboardImageSize>>20),
+		}
+	}
+
+	// 声明的类型与实际内容都必须是 PNG/JPEG
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if mimeType == "image/jpg" {
+		mimeType = "image/jpeg"
+	}
+	if mimeType != "image/png" && mimeType != "image/jpeg" {
+		return ExtractResult{Success: false, ErrorMessage: fmt.Sprintf("不支持的图片格式: %s，仅支持 PNG、JPEG", mimeType)}
+	}
+	if detected := http.DetectContentType(data); detected != mimeType {
+		return ExtractResult{Success: false, ErrorMessage: fmt.Sprintf("图片内容与声明的格式不符 (%s)", detected)}
+	}
+
+	records, err := a.extractor.ExtractImage(data, fields, func(current, total int, message string) {
+		wr.EventsEmit(a.ctx, "extraction_progress", map[string]interface{}{
+			"current": current,
+			"total":   total,
+			"message": message,
+		})
+	})
+	if err != nil {
+		return ExtractResult{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("图片识别失败: %v", err),
+		}
+	}
+
+	labels := make(map[string]string)
+	for k, p := range extractor.PatternRegistry {
+		labels[k] = p.Label
+	}
+
+	return ExtractResult{
+		Success:     true,
+		RecordCount: len(records),
+		Records:     records,
+		FieldLabels: labels,
+	}
+}
+
 // OpenFile opens the file at the given path using the system's default application
 func (a *App) OpenFile(path string) error {
 	var cmd *exec.Cmd
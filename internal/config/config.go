@@ -14,8 +14,12 @@ import (
 )
 
 var (
+	// Version is the application version, overridable via -ldflags
+	Version string = "3.0.0"
 	// BuildTime is injected via -ldflags at build time (Unix timestamp)
 	BuildTime string = ""
+	// GitCommit is injected via -ldflags at build time
+	GitCommit string = ""
 	// EmbeddedBaiduToken is injected via -ldflags at build time
 	EmbeddedBaiduToken string = ""
 )
@@ -74,13 +78,128 @@ var bakedConfig []byte
 
 // Config 应用配置结构
 type Config struct {
-	Baidu BaiduConfig `mapstructure:"baidu"`
+	Baidu      BaiduConfig      `mapstructure:"baidu"`
+	OCR        OCRConfig        `mapstructure:"ocr"`
+	Branding   BrandingConfig   `mapstructure:"branding"`
+	Telemetry  TelemetryConfig  `mapstructure:"telemetry"`
+	Export     ExportConfig     `mapstructure:"export"`
+	Extraction ExtractionConfig `mapstructure:"extraction"`
+}
+
+// ExtractionConfig 本地解析规则配置
+type ExtractionConfig struct {
+	// DefendantStopKeywords 截断被告姓名的关键词 (如 "性别"、"户籍")，为空时使用内置列表
+	DefendantStopKeywords []string `mapstructure:"defendant_stop_keywords"`
+	// DefendantMaxRunes 未遇到截止关键词或句号时被告姓名最多保留的字符数，0 表示内置的 16
+	DefendantMaxRunes int `mapstructure:"defendant_max_runes"`
+	// SectionEndAnchors 截止事实与理由、诉讼请求正文的结尾标记 (正则表达式，按行匹配)，取最早出现者；为空时使用内置列表
+	// (此致、具状人/起诉人签名行、日期行、证据章节标题)
+	SectionEndAnchors []string `mapstructure:"section_end_anchors"`
+	// FieldPrefixes 提取后从各字段取值开头去除的标注 (字段键 -> 标注列表)，如 defendant: ["被告", "被起诉人"]，
+	// 覆盖该字段的内置列表，空列表表示不处理该字段
+	FieldPrefixes map[string][]string `mapstructure:"field_prefixes"`
+	// NameWhitespace 当事人姓名中空白的处理方式：compact (默认，删除内部空白，仅保留拉丁字母之间的一个空格) 或 collapse (连续空白合并为一个空格)；换行总会去除
+	NameWhitespace string `mapstructure:"name_whitespace"`
+	// RepeatableFields 需要提取全部出现项的字段 (plaintiff、plaintiffPhone、defendant、thirdParty、idNumber)，多个值以 "、" 连接；
+	// 为空时每个字段只取第一处匹配
+	RepeatableFields []string `mapstructure:"repeatable_fields"`
+	// StrictIDLabel 为 true 时只识别 "身份证号码：" 标注的号码，不再兜底匹配 "公民身份号码"、"身份证" 等写法
+	StrictIDLabel bool `mapstructure:"strict_id_label"`
+	// RequiredFields 记录必须具备的字段，缺少时丢弃该记录；为空时任一字段非空即保留
+	RequiredFields []string `mapstructure:"required_fields"`
+	// RequireAny 为 true 时只需具备 RequiredFields 中的任一字段，否则需全部具备
+	RequireAny bool `mapstructure:"require_any"`
+	// Strict 为 true 时任一记录缺少必填字段即整次提取失败并列出不完整的记录，而不是丢弃后返回其余记录；
+	// Web 服务可按请求以 strict 参数覆盖
+	Strict bool `mapstructure:"strict"`
+	// Workers 本地并行解析 PDF 页面的协程数，0 表示自动 (GOMAXPROCS，最多 8)
+	Workers int `mapstructure:"workers"`
+	// MinTextChars PDF 页面至少包含多少个非空白字符才视为文本层，直接本地解析；不足时视为扫描件送识别
+	MinTextChars int `mapstructure:"min_text_chars"`
+	// Deskew 图片送识别前先在本地检测并校正倾斜，适用于手工扫描歪斜的材料，默认关闭
+	Deskew bool `mapstructure:"deskew"`
+	// IncludeProvenance 在每条记录中写入源文件名、内容哈希、页码与提取时间，导出时作为附加列，便于审计追溯，默认关闭
+	IncludeProvenance bool `mapstructure:"include_provenance"`
+	// AuditLog 审计记录的去向：为空时不记录；"log" 写入程序日志 (带 audit=true 属性)；其他值为 JSON Lines 文件路径 (相对路径相对于配置目录)。
+	// 每次提取记录内容哈希、提取路径与识别引擎、页数、提取到的字段键、提示与耗时，不含字段取值
+	AuditLog string `mapstructure:"audit_log"`
+	// Dedupe 合并批量结果时跨文件去重 (同一案件的重复扫描件)，默认关闭
+	Dedupe bool `mapstructure:"dedupe"`
+	// DedupeNameDistance 去重时缺少身份证号码的记录，被告姓名允许相差的字符数 (容忍识别误差)
+	DedupeNameDistance int `mapstructure:"dedupe_name_distance"`
+	// Hybrid 为 true 时，带文本层的 PDF 在本地解析之外再调用云端识别引擎，按 FieldPriority 逐字段合并两份结果
+	Hybrid bool `mapstructure:"hybrid"`
+	// FieldPriority 混合解析时各字段优先采用的来源 ("text" 原生文本层 或 "ocr" 识别结果)，覆盖内置优先级表
+	FieldPriority map[string]string `mapstructure:"field_priority"`
+	// Transforms 提取后按顺序执行的具名后处理，如 ["trim-whitespace", "validate-id", "mask-pii"]
+	Transforms []string `mapstructure:"transforms"`
+	// QualityCheck 为每个字段附带质量评分并标出疑似识别乱码的字段，默认关闭
+	QualityCheck bool `mapstructure:"quality_check"`
+	// QualityThreshold 质量评分低于该值 (0 ~ 1) 的字段标记为需人工核对
+	QualityThreshold float64 `mapstructure:"quality_threshold"`
+	// TextCacheTTL 提取结果与提取时使用的原文 (供按新的字段选择重新解析) 在内存中保留的时间，如 "10m"
+	TextCacheTTL time.Duration `mapstructure:"text_cache_ttl"`
+	// TextCacheSize 最多缓存多少份文档的提取结果与原文，超出时淘汰最早的条目
+	TextCacheSize int `mapstructure:"text_cache_size"`
+}
+
+// ExportConfig 导出相关配置
+type ExportConfig struct {
+	// SummaryTemplate 摘要列模板，以 {字段键} 作占位符、"；"分段，如 "被告：{defendant}；诉讼请求：{request}"
+	SummaryTemplate string `mapstructure:"summary_template"`
+}
+
+// TelemetryConfig OpenTelemetry 链路追踪配置 (仅 Web 服务使用)
+type TelemetryConfig struct {
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"` // OTLP/HTTP 上报地址，如 "otel-collector:4318"；为空时不启用追踪
+	ServiceName  string `mapstructure:"service_name"`
+	Insecure     bool   `mapstructure:"insecure"` // 为 true 时以明文 HTTP 上报 (如同一内网中的 Collector)，默认使用 TLS
+	CAFile       string `mapstructure:"ca_file"`  // 校验 Collector 证书的 CA 证书 (PEM)，为空时使用系统根证书
+}
+
+// BrandingConfig 导出 Excel 时使用的律所品牌信息
+type BrandingConfig struct {
+	FirmName    string `mapstructure:"firm_name"`    // 标题行显示的律所名称
+	HeaderColor string `mapstructure:"header_color"` // 表头底色，如 "#1F4E78"
+}
+
+// OCRConfig 识别引擎选择
+type OCRConfig struct {
+	// Provider 指定扫描件使用的识别引擎: "" (自动，配置了百度 Token 时使用百度) / "mock" (离线模拟)
+	Provider string `mapstructure:"provider"`
+	// CostPerPage 云端识别每页的费用 (元)，用于处理前的费用估算，0 表示未知
+	CostPerPage float64 `mapstructure:"cost_per_page"`
+	// BridgeTimeout 本地识别桥接工具处理单页的超时时间，如 "120s"，超时后终止其进程及子进程
+	BridgeTimeout time.Duration `mapstructure:"bridge_timeout"`
+	// FieldAliases 识别结果中字段标注的别名 (别名 -> 字段键)，如 被起诉人: defendant，补充内置的标注写法
+	FieldAliases map[string]string `mapstructure:"field_aliases"`
+	// StreamPages 扫描版 PDF 逐页送云端识别，内存占用与页数无关 (适用于数百页的扫描件)，请求次数相应增加，默认关闭
+	StreamPages bool `mapstructure:"stream_pages"`
+	// ParseRawTextOnEmpty 识别引擎未能结构化解析出记录时，先按其识别出的原始文字在本地提取 (不额外调用引擎)，默认开启
+	ParseRawTextOnEmpty bool `mapstructure:"parse_raw_text_on_empty"`
+	// FallbackOnEmpty 仍未得到记录时依次改用备用识别引擎，扫描版 PDF 最后尝试本地系统识别，默认关闭
+	FallbackOnEmpty bool `mapstructure:"fallback_on_empty"`
 }
 
 // BaiduConfig 百度 OCR 配置
 type BaiduConfig struct {
-	Token  string `mapstructure:"token"`
-	ApiUrl string `mapstructure:"api_url"`
+	Token            string        `mapstructure:"token"`
+	ApiUrl           string        `mapstructure:"api_url"`
+	BatchConcurrency int           `mapstructure:"batch_concurrency"` // 批量解析时的最大并发文档数，0 表示自动
+	SubmitQPS        float64       `mapstructure:"submit_qps"`        // 批量提交的每秒请求上限
+	Timeout          time.Duration `mapstructure:"timeout"`           // 单次请求超时，如 "180s"
+	Proxy            string        `mapstructure:"proxy"`             // HTTP 代理地址，为空时沿用 HTTP_PROXY/HTTPS_PROXY 环境变量
+	// ChunkConcurrency 超长 PDF 拆分后同时识别的分块数，1 表示逐块串行
+	ChunkConcurrency int `mapstructure:"chunk_concurrency"`
+	// ChunkQPS 提交分块的每秒请求上限，0 表示不限制
+	ChunkQPS float64 `mapstructure:"chunk_qps"`
+	// OrientationClassify 让云端先判断页面方向并旋转校正，适用于横放、倒置扫描的文档
+	OrientationClassify bool `mapstructure:"orientation_classify"`
+	// DocUnwarping 让云端校正拍照文档的弯曲、透视变形
+	DocUnwarping bool `mapstructure:"doc_unwarping"`
+	// FallbackApiUrls 开启 ocr.fallback_on_empty 时依次尝试的备用接口地址 (同一账号下部署的其他版面解析服务)，
+	// 沿用同一 Token 与其他设置
+	FallbackApiUrls []string `mapstructure:"fallback_api_urls"`
 }
 
 var (
@@ -105,6 +224,46 @@ func Init(configPath string) error {
 	// 设置默认值
 	v.SetDefault("baidu.token", EmbeddedBaiduToken)
 	v.SetDefault("baidu.api_url", "https://n1544et5uec1tbh9.aistudio-app.com/layout-parsing")
+	v.SetDefault("baidu.batch_concurrency", 0)
+	v.SetDefault("baidu.submit_qps", 2)
+	v.SetDefault("baidu.chunk_concurrency", 1)
+	v.SetDefault("baidu.chunk_qps", 0.1)
+	v.SetDefault("baidu.timeout", "180s")
+	v.SetDefault("baidu.proxy", "")
+	v.SetDefault("baidu.orientation_classify", false)
+	v.SetDefault("baidu.doc_unwarping", false)
+	v.SetDefault("ocr.provider", "")
+	v.SetDefault("ocr.cost_per_page", 0)
+	v.SetDefault("ocr.bridge_timeout", "120s")
+	v.SetDefault("ocr.stream_pages", false)
+	v.SetDefault("ocr.parse_raw_text_on_empty", true)
+	v.SetDefault("ocr.fallback_on_empty", false)
+	v.SetDefault("branding.firm_name", "")
+	v.SetDefault("branding.header_color", "#1F4E78")
+	v.SetDefault("telemetry.otlp_endpoint", "")
+	v.SetDefault("telemetry.service_name", "legal-extractor")
+	v.SetDefault("telemetry.insecure", false)
+	v.SetDefault("telemetry.ca_file", "")
+	v.SetDefault("export.summary_template", "")
+	v.SetDefault("extraction.defendant_max_runes", 16)
+	v.SetDefault("extraction.name_whitespace", "compact")
+	v.SetDefault("extraction.strict_id_label", false)
+	v.SetDefault("extraction.repeatable_fields", []string{})
+	v.SetDefault("extraction.required_fields", []string{})
+	v.SetDefault("extraction.require_any", false)
+	v.SetDefault("extraction.strict", false)
+	v.SetDefault("extraction.workers", 0)
+	v.SetDefault("extraction.min_text_chars", 10)
+	v.SetDefault("extraction.deskew", false)
+	v.SetDefault("extraction.include_provenance", false)
+	v.SetDefault("extraction.audit_log", "")
+	v.SetDefault("extraction.dedupe", false)
+	v.SetDefault("extraction.dedupe_name_distance", 1)
+	v.SetDefault("extraction.hybrid", false)
+	v.SetDefault("extraction.quality_check", false)
+	v.SetDefault("extraction.quality_threshold", 0.6)
+	v.SetDefault("extraction.text_cache_ttl", "10m")
+	v.SetDefault("extraction.text_cache_size", 100)
 
 	// 绑定环境变量 (前缀 LEGAL_EXTRACTOR_)
 	v.SetEnvPrefix("LEGAL_EXTRACTOR")
@@ -137,7 +296,7 @@ func Init(configPath string) error {
 		}
 	} else {
 		// 文件读取成功，检查是否为空配置且无内置 Token
-		if v.GetString("baidu.token") == "" && EmbeddedBaiduToken == "" {
+		if !secretConfigured(v, "baidu.token") && EmbeddedBaiduToken == "" {
 			fmt.Println("[ℹ️ 提示] 未检测到百度云密钥，尝试加载内置配置...")
 			useBaked = true
 		}
@@ -154,7 +313,7 @@ func Init(configPath string) error {
 	}
 
 	// 如果最终密钥仍然为空，且之前是因为文件不存在才进来的，则创建默认模板
-	if v.GetString("baidu.token") == "" && EmbeddedBaiduToken == "" {
+	if !secretConfigured(v, "baidu.token") && EmbeddedBaiduToken == "" {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			defaultPath := filepath.Join(baseDir, "config", "conf.yaml")
 			if createErr := ensureConfigFile(defaultPath); createErr != nil {
@@ -165,6 +324,22 @@ func Init(configPath string) error {
 		}
 	}
 
+	// 解析 _file / _cmd 方式配置的密钥
+	if err := resolveSecrets(v); err != nil {
+		return err
+	}
+
+	// 检查拼写错误的配置项与取值类型，集中输出一份报告；类型错误时无法加载
+	if problems := validate(v); len(problems) > 0 {
+		report := formatProblems(problems)
+		for _, p := range problems {
+			if p.Invalid {
+				return fmt.Errorf("配置校验失败:\n%s", report)
+			}
+		}
+		fmt.Print(report)
+	}
+
 	// 解析到结构体
 	cfg = &Config{}
 	if err := v.Unmarshal(cfg); err != nil {
@@ -199,7 +374,7 @@ func ensureConfigFile(configPath string) error {
 # 例如: LEGAL_EXTRACTOR_BAIDU_TOKEN=xxx
 
 baidu:
-  token: ""      # 百度 AI Studio Token
+  token: ""      # 百度 AI Studio Token (也可用 token_file / token_cmd 从文件或命令读取)
   api_url: "https://n1544et5uec1tbh9.aistudio-app.com/layout-parsing"
 `
 	return os.WriteFile(configPath, []byte(defaultConfig), 0644)
@@ -213,6 +388,18 @@ func Get() *Config {
 	return cfg
 }
 
+// Dir 返回配置目录：已加载配置文件所在的目录，否则为可执行文件同级的 config 目录
+func Dir() string {
+	if v != nil && v.ConfigFileUsed() != "" {
+		return filepath.Dir(v.ConfigFileUsed())
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return "config"
+	}
+	return filepath.Join(filepath.Dir(exePath), "config")
+}
+
 // GetBaidu 获取百度配置
 func GetBaidu() BaiduConfig {
 	if cfg == nil {
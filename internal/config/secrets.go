@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// secretKeys 需要支持间接读取的密钥配置项。每项可改用 <key>_file (从文件读取，
+// 如 Docker secrets) 或 <key>_cmd (执行命令取标准输出，如 Vault agent)，避免明文写入 conf.yaml
+var secretKeys = []string{"baidu.token"}
+
+// secretCmdTimeout 执行取密钥命令的超时时间
+const secretCmdTimeout = 10 * time.Second
+
+// secretConfigured 判断密钥是否已通过直接值、文件或命令任一方式配置
+func secretConfigured(v *viper.Viper, key string) bool {
+	return v.GetString(key) != "" || v.GetString(key+"_file") != "" || v.GetString(key+"_cmd") != ""
+}
+
+// resolveSecrets 解析间接配置的密钥并写回 viper。优先级：_file > _cmd > 直接值
+func resolveSecrets(v *viper.Viper) error {
+	for _, key := range secretKeys {
+		if path := v.GetString(key + "_file"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("读取 %s_file 失败: %w", key, err)
+			}
+			v.Set(key, strings.TrimSpace(string(data)))
+			continue
+		}
+		if command := v.GetString(key + "_cmd"); command != "" {
+			secret, err := runSecretCommand(command)
+			if err != nil {
+				return fmt.Errorf("执行 %s_cmd 失败: %w", key, err)
+			}
+			v.Set(key, secret)
+		}
+	}
+	return nil
+}
+
+// runSecretCommand 通过系统 shell 执行命令，返回去除首尾空白的标准输出
+func runSecretCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCmdTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveSecrets_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baidu_token")
+	if err := os.WriteFile(path, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.Set("baidu.token", "inline")
+	v.Set("baidu.token_file", path)
+	if err := resolveSecrets(v); err != nil {
+		t.Fatalf("resolveSecrets: %v", err)
+	}
+	if got := v.GetString("baidu.token"); got != "file-secret" {
+		t.Errorf("Expected token from file, got %q", got)
+	}
+
+	v.Set("baidu.token_file", filepath.Join(t.TempDir(), "missing"))
+	if err := resolveSecrets(v); err == nil {
+		t.Error("Expected error for missing secret file")
+	}
+}
+
+func TestResolveSecrets_Command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	v := viper.New()
+	v.Set("baidu.token_cmd", "echo cmd-secret")
+	if err := resolveSecrets(v); err != nil {
+		t.Fatalf("resolveSecrets: %v", err)
+	}
+	if got := v.GetString("baidu.token"); got != "cmd-secret" {
+		t.Errorf("Expected token from command, got %q", got)
+	}
+
+	v.Set("baidu.token_cmd", "echo denied >&2; exit 3")
+	if err := resolveSecrets(v); err == nil {
+		t.Error("Expected error for failing command")
+	}
+}
+
+func TestResolveSecrets_Direct(t *testing.T) {
+	v := viper.New()
+	v.Set("baidu.token", "inline")
+	if err := resolveSecrets(v); err != nil {
+		t.Fatalf("resolveSecrets: %v", err)
+	}
+	if got := v.GetString("baidu.token"); got != "inline" {
+		t.Errorf("Expected direct token to be kept, got %q", got)
+	}
+}
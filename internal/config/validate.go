@@ -0,0 +1,210 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"legal-extractor/internal/editdist"
+
+	"github.com/spf13/viper"
+)
+
+// Problem 配置检查发现的一处问题
+type Problem struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // 建议的修正，如拼写相近的正确配置项
+	// Invalid 为 true 表示取值类型错误，配置无法加载；否则仅为警告 (如未知配置项被忽略)
+	Invalid bool `json:"invalid"`
+}
+
+func (p Problem) String() string {
+	s := p.Key + ": " + p.Message
+	if p.Hint != "" {
+		s += " (" + p.Hint + ")"
+	}
+	return s
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// schema 由 Config 结构体的 mapstructure 标签推导出的全部配置项及其类型，
+// 并包含密钥配置项的 _file / _cmd 变体
+func schema() map[string]reflect.Type {
+	keys := make(map[string]reflect.Type)
+	collectKeys(reflect.TypeOf(Config{}), "", keys)
+	for _, key := range secretKeys {
+		keys[key+"_file"] = reflect.TypeOf("")
+		keys[key+"_cmd"] = reflect.TypeOf("")
+	}
+	return keys
+}
+
+func collectKeys(t reflect.Type, prefix string, keys map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			collectKeys(f.Type, key+".", keys)
+			continue
+		}
+		keys[key] = f.Type
+	}
+}
+
+// validate 检查 viper 中的配置：未知配置项 (通常是拼写错误，会被静默忽略) 给出警告与相近的正确写法，
+// 无法转换为目标类型的取值标记为 Invalid。结果按配置项排序
+func validate(v *viper.Viper) []Problem {
+	known := schema()
+	var problems []Problem
+	for _, key := range v.AllKeys() {
+		t, ok := known[key]
+		if !ok {
+			t, ok = mapEntryType(key, known)
+		}
+		if !ok {
+			p := Problem{Key: key, Message: "未知配置项，将被忽略"}
+			if s := suggestKey(key, known); s != "" {
+				p.Hint = "是否应为 " + s + "?"
+			}
+			problems = append(problems, p)
+			continue
+		}
+		if msg, hint := checkType(t, v.Get(key)); msg != "" {
+			problems = append(problems, Problem{Key: key, Message: msg, Hint: hint, Invalid: true})
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+	return problems
+}
+
+// mapEntryType 对映射类型配置项 (如 extraction.field_priority) 下的子键，返回其取值类型
+func mapEntryType(key string, known map[string]reflect.Type) (reflect.Type, bool) {
+	for i := strings.LastIndex(key, "."); i > 0; i = strings.LastIndex(key[:i], ".") {
+		if t, ok := known[key[:i]]; ok && t.Kind() == reflect.Map {
+			return t.Elem(), true
+		}
+	}
+	return nil, false
+}
+
+// checkType 判断取值能否按 viper 的宽松规则解析为类型 t，不能时返回错误说明与示例
+func checkType(t reflect.Type, val any) (msg, hint string) {
+	if val == nil {
+		return "", ""
+	}
+	switch val.(type) {
+	case map[string]any, map[any]any:
+		return "应为单个值，而不是一组子配置项", ""
+	}
+
+	s, isString := val.(string)
+	switch {
+	case t == durationType:
+		if isString {
+			if _, err := time.ParseDuration(s); err != nil {
+				if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+					return fmt.Sprintf("%q 不是有效的时长", s), `示例: "180s"、"2m"`
+				}
+			}
+		}
+	case t.Kind() == reflect.Slice:
+		// 单个字符串会被视作只含一项的列表
+	case t.Kind() == reflect.Bool:
+		if _, err := strconv.ParseBool(s); isString && err != nil {
+			return fmt.Sprintf("%q 不是有效的布尔值", s), "应为 true 或 false"
+		}
+	case t.Kind() == reflect.Int:
+		if _, err := strconv.Atoi(strings.TrimSpace(s)); isString && err != nil {
+			return fmt.Sprintf("%q 不是有效的整数", s), ""
+		}
+	case t.Kind() == reflect.Float64:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); isString && err != nil {
+			return fmt.Sprintf("%q 不是有效的数字", s), ""
+		}
+	}
+	if _, isList := val.([]any); isList && t.Kind() != reflect.Slice {
+		return "应为单个值，而不是列表", ""
+	}
+	return "", ""
+}
+
+// suggestKey 返回与 key 拼写最接近的已知配置项 (忽略大小写、下划线与连字符)，差异过大时返回空字符串
+func suggestKey(key string, known map[string]reflect.Type) string {
+	normalize := func(s string) string {
+		return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(s))
+	}
+	target := normalize(key)
+	best, bestDist := "", 3 // 至多相差 2 处编辑
+	for candidate := range known {
+		d := editdist.Distance(target, normalize(candidate))
+		if d < bestDist || (d == bestDist && best != "" && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// formatProblems 将检查结果整理为一份启动报告
+func formatProblems(problems []Problem) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[⚠️ 配置检查] 发现 %d 处问题:\n", len(problems))
+	for _, p := range problems {
+		sb.WriteString("  - " + p.String() + "\n")
+	}
+	return sb.String()
+}
+
+// JSONSchema 返回 conf.yaml 的 JSON Schema (draft-07)，可供编辑器 (如 yaml-language-server) 做补全与校验
+func JSONSchema() ([]byte, error) {
+	root := map[string]any{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                "Legal Extractor 配置",
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties":           map[string]any{},
+	}
+	for key, t := range schema() {
+		node := root
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			props := node["properties"].(map[string]any)
+			child, ok := props[part].(map[string]any)
+			if !ok {
+				child = map[string]any{"type": "object", "additionalProperties": false, "properties": map[string]any{}}
+				props[part] = child
+			}
+			node = child
+		}
+		node["properties"].(map[string]any)[parts[len(parts)-1]] = jsonSchemaType(t)
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func jsonSchemaType(t reflect.Type) map[string]any {
+	switch {
+	case t == durationType:
+		return map[string]any{"type": "string", "pattern": `^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$`}
+	case t.Kind() == reflect.Slice:
+		return map[string]any{"type": "array", "items": jsonSchemaType(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchemaType(t.Elem())}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() == reflect.Int:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
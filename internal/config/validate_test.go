@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidate_MisspelledKey(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	conf := "baidu:\n  tokne: abc\n  batchconcurrency: 2\ntencent:\n  secretid: xyz\n"
+	if err := v.ReadConfig(strings.NewReader(conf)); err != nil {
+		t.Fatal(err)
+	}
+
+	problems := validate(v)
+	hints := make(map[string]string)
+	for _, p := range problems {
+		if p.Invalid {
+			t.Errorf("unexpected type error: %v", p)
+		}
+		hints[p.Key] = p.Hint
+	}
+	if len(problems) != 3 {
+		t.Fatalf("Expected 3 problems, got %v", problems)
+	}
+	if got := hints["baidu.tokne"]; !strings.Contains(got, "baidu.token") {
+		t.Errorf("Expected baidu.token suggestion, got %q", got)
+	}
+	if got := hints["baidu.batchconcurrency"]; !strings.Contains(got, "baidu.batch_concurrency") {
+		t.Errorf("Expected baidu.batch_concurrency suggestion, got %q", got)
+	}
+	if got := hints["tencent.secretid"]; got != "" {
+		t.Errorf("Expected no suggestion for an unrelated key, got %q", got)
+	}
+	if report := formatProblems(problems); !strings.Contains(report, "baidu.tokne: 未知配置项") {
+		t.Errorf("report missing the misspelled key:\n%s", report)
+	}
+}
+
+func TestValidate_Types(t *testing.T) {
+	v := viper.New()
+	v.Set("baidu.timeout", "3 minutes")
+	v.Set("baidu.submit_qps", "fast")
+	v.Set("baidu.token_file", "/run/secrets/token")
+	v.Set("extraction.defendant_stop_keywords", []any{"性别"})
+	v.Set("extraction.defendant_max_runes", 24)
+	v.Set("extraction.field_priority", map[string]any{"idnumber": "text", "request": "ocr"})
+
+	problems := validate(v)
+	if len(problems) != 2 || problems[0].Key != "baidu.submit_qps" || problems[1].Key != "baidu.timeout" {
+		t.Fatalf("Expected submit_qps and timeout type errors, got %v", problems)
+	}
+	for _, p := range problems {
+		if !p.Invalid {
+			t.Errorf("Expected %s to be invalid", p.Key)
+		}
+	}
+}
+
+func TestInit_InvalidValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.yaml")
+	if err := os.WriteFile(path, []byte("baidu:\n  token: abc\n  timeout: soon\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err := Init(path)
+	if err == nil || !strings.Contains(err.Error(), "baidu.timeout") {
+		t.Fatalf("Expected a validation error naming baidu.timeout, got %v", err)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root struct {
+		Properties map[string]struct {
+			Properties map[string]struct {
+				Type string `json:"type"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("invalid schema JSON: %v", err)
+	}
+	if got := root.Properties["baidu"].Properties["submit_qps"].Type; got != "number" {
+		t.Errorf("baidu.submit_qps type = %q, want number", got)
+	}
+	if _, ok := root.Properties["baidu"].Properties["token_file"]; !ok {
+		t.Error("schema is missing baidu.token_file")
+	}
+}
@@ -0,0 +1,89 @@
+package extractor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// aliasLabels 可配置别名的字段及其在识别结果中的标准标注
+var aliasLabels = map[string]string{
+	"plaintiff":      "原告",
+	"defendant":      "被告",
+	"thirdParty":     "第三人",
+	"idNumber":       "身份证号码",
+	"creditCode":     "统一社会信用代码",
+	"caseCause":      "案由",
+	"agentAuthority": "代理权限",
+	"request":        "诉讼请求",
+	"factsReason":    "事实与理由",
+}
+
+// fieldAliases 一组别名规则：alias 匹配任一别名标注，labels 为别名到标准标注的映射
+type fieldAliases struct {
+	alias  *regexp.Regexp
+	labels map[string]string
+}
+
+// SetFieldAliases 设置识别结果中字段标注的别名 (别名 -> 字段键)，如 {"被起诉人": "defendant"}，
+// 解析前将别名替换为该字段的标准标注，以适应各地法院文书模板的不同写法。
+// 只有后跟冒号或独占一行 (标题) 的别名才会替换。无法识别的字段返回错误，其余条目仍然生效；传入空表清除别名
+func (p *ExtractionPatterns) SetFieldAliases(aliases map[string]string) error {
+	labels := make(map[string]string)
+	var invalid []string
+	for alias, key := range aliases {
+		alias = strings.TrimSpace(alias)
+		label, ok := aliasLabels[canonicalFieldKey(key)]
+		if alias == "" || !ok {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", alias, key))
+			continue
+		}
+		labels[alias] = label
+	}
+
+	p.aliases = nil
+	if len(labels) > 0 {
+		alts := make([]string, 0, len(labels))
+		for alias := range labels {
+			alts = append(alts, alias)
+		}
+		// 较长的别名优先，避免 "被起诉人" 被其中的 "起诉人" 抢先匹配
+		sort.Slice(alts, func(i, j int) bool {
+			if len(alts[i]) != len(alts[j]) {
+				return len(alts[i]) > len(alts[j])
+			}
+			return alts[i] < alts[j]
+		})
+		for i, alias := range alts {
+			alts[i] = regexp.QuoteMeta(alias)
+		}
+		p.aliases = &fieldAliases{
+			alias:  regexp.MustCompile(`(?m)(` + strings.Join(alts, "|") + `)(\s*[:：]|[\s#*]*$)`),
+			labels: labels,
+		}
+	}
+
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		keys := make([]string, 0, len(aliasLabels))
+		for k := range aliasLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return fmt.Errorf("无效的字段别名 (字段应为 %s): %s", strings.Join(keys, "、"), strings.Join(invalid, "，"))
+	}
+	return nil
+}
+
+// applyFieldAliases 将识别结果中的别名标注替换为标准标注
+func (p *ExtractionPatterns) applyFieldAliases(text string) string {
+	if p.aliases == nil {
+		return text
+	}
+	re := p.aliases.alias
+	return re.ReplaceAllStringFunc(text, func(m string) string {
+		sub := re.FindStringSubmatch(m)
+		return p.aliases.labels[sub[1]] + sub[2]
+	})
+}
@@ -0,0 +1,53 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMarkdown_FieldAliases(t *testing.T) {
+	md := "# 民事起诉状\n\n起诉人：某某银行股份有限公司，住所地：北京市西城区\n\n被起诉人：王五，住址：北京市朝阳区\n\n证件号码：110101199001011234\n\n## 请求事项\n\n判令被起诉人偿还借款10000元。\n\n事实与理由：借款到期未还。\n"
+
+	if got := ParseMarkdown(md); len(got) == 1 && got[0]["defendant"] == "王五" {
+		t.Fatalf("Expected 被起诉人 to be unknown without an alias, got %v", got[0])
+	}
+
+	p := DefaultPatterns
+	err := p.SetFieldAliases(map[string]string{
+		"被起诉人": "defendant",
+		"起诉人":  "plaintiff",
+		"证件号码": "idnumber", // 配置文件中的键会被转为小写
+		"请求事项": "request",
+		"案号":   "caseNumber",
+	})
+	if err == nil || !strings.Contains(err.Error(), "案号") {
+		t.Errorf("Expected an error naming the unknown field, got %v", err)
+	}
+
+	records := p.parseMarkdown(md)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	want := map[string]string{
+		"defendant": "王五",
+		"plaintiff": "某某银行股份有限公司",
+		"idNumber":  "110101199001011234",
+	}
+	for k, v := range want {
+		if records[0][k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, records[0][k])
+		}
+	}
+	if !strings.Contains(records[0]["request"], "偿还借款") {
+		t.Errorf("request: expected the 请求事项 section, got %q", records[0]["request"])
+	}
+	// 正文中的 "被起诉人" 后无冒号，不替换
+	if strings.Contains(records[0]["request"], "被告") {
+		t.Errorf("Expected aliases in running text to be left alone, got %q", records[0]["request"])
+	}
+
+	// 别名只作用于设置它的规则副本，内置规则不受影响
+	if got := ParseMarkdown(md); len(got) == 1 && got[0]["defendant"] == "王五" {
+		t.Errorf("Expected DefaultPatterns to stay without aliases, got %v", got[0])
+	}
+}
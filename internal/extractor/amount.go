@@ -0,0 +1,185 @@
+package extractor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Amount 结构化的金额信息
+type Amount struct {
+	Raw      string  // 原文
+	Value    float64 // 归一化后的金额 (以元为单位)；区间时为下限
+	Max      float64 // 区间上限；非区间时等于 Value
+	Currency string  // 币种代码，默认 CNY
+	Capital  bool    // 是否为大写金额 (壹贰叁…)
+	IsRange  bool    // 是否为金额区间 (如 "1万至2万元")
+}
+
+// Decimal 返回两位小数的金额字符串，区间以 "-" 连接
+func (a Amount) Decimal() string {
+	s := strconv.FormatFloat(a.Value, 'f', 2, 64)
+	if a.IsRange {
+		s += "-" + strconv.FormatFloat(a.Max, 'f', 2, 64)
+	}
+	return s
+}
+
+var (
+	cnDigits = map[rune]float64{
+		'零': 0, '〇': 0, '壹': 1, '一': 1, '贰': 2, '二': 2, '两': 2, '叁': 3, '三': 3,
+		'肆': 4, '四': 4, '伍': 5, '五': 5, '陆': 6, '六': 6, '柒': 7, '七': 7,
+		'捌': 8, '八': 8, '玖': 9, '九': 9,
+	}
+	cnSmallUnits = map[rune]float64{'拾': 10, '十': 10, '佰': 100, '百': 100, '仟': 1000, '千': 1000}
+	// capitalRunes 仅出现在大写金额中的字符
+	capitalRunes = "零壹贰叁肆伍陆柒捌玖拾佰仟圆"
+
+	currencyWords = []struct {
+		word string
+		code string
+	}{
+		{"美元", "USD"}, {"USD", "USD"}, {"$", "USD"},
+		{"港币", "HKD"}, {"港元", "HKD"}, {"HKD", "HKD"},
+		{"欧元", "EUR"}, {"EUR", "EUR"}, {"€", "EUR"},
+		{"人民币", "CNY"}, {"RMB", "CNY"}, {"CNY", "CNY"}, {"¥", "CNY"}, {"￥", "CNY"},
+	}
+
+	reArabicAmount = regexp.MustCompile(`^(\d+(?:\.\d+)?)(万|亿|千)?(?:元|圆)?(?:整|正)?$`)
+	reRangeSep     = regexp.MustCompile(`至|到|~|～|—|-`)
+)
+
+// parseAmount 将金额文本解析为结构化金额
+// 支持阿拉伯数字 (含千分位、万/亿单位)、中文大小写数字 (含角/分) 以及金额区间
+func parseAmount(s string) (Amount, bool) {
+	a := Amount{Raw: strings.TrimSpace(s), Currency: "CNY"}
+	if a.Raw == "" {
+		return a, false
+	}
+
+	body := strings.Join(strings.Fields(a.Raw), "")
+	body = strings.NewReplacer(",", "", "，", "").Replace(body)
+	for _, cw := range currencyWords {
+		if strings.Contains(body, cw.word) {
+			if a.Currency == "CNY" {
+				a.Currency = cw.code
+			}
+			body = strings.ReplaceAll(body, cw.word, "")
+		}
+	}
+	a.Capital = strings.ContainsAny(body, capitalRunes)
+
+	parts := reRangeSep.Split(body, -1)
+	switch len(parts) {
+	case 1:
+		v, _, ok := parseAmountValue(parts[0])
+		if !ok {
+			return a, false
+		}
+		a.Value, a.Max = v, v
+	case 2:
+		lo, loUnit, ok1 := parseAmountValue(parts[0])
+		hi, hiUnit, ok2 := parseAmountValue(parts[1])
+		if !ok1 || !ok2 {
+			return a, false
+		}
+		// "1-2万元" 这类写法中下限省略了单位，沿用上限的单位
+		if loUnit == 1 && hiUnit > 1 {
+			lo *= hiUnit
+		}
+		a.Value, a.Max, a.IsRange = lo, hi, true
+	default:
+		return a, false
+	}
+	return a, true
+}
+
+// parseAmountValue 解析单个金额，返回金额 (元) 及其数量级单位
+func parseAmountValue(s string) (float64, float64, bool) {
+	if s == "" {
+		return 0, 0, false
+	}
+	if strings.ContainsAny(s, "0123456789") {
+		m := reArabicAmount.FindStringSubmatch(s)
+		if m == nil {
+			return 0, 0, false
+		}
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		unit := 1.0
+		switch m[2] {
+		case "千":
+			unit = 1e3
+		case "万":
+			unit = 1e4
+		case "亿":
+			unit = 1e8
+		}
+		return v * unit, unit, true
+	}
+	return parseChineseAmount(s)
+}
+
+// parseChineseAmount 解析中文数字金额，如 "壹万贰仟元整"、"叁佰元伍角"
+func parseChineseAmount(s string) (float64, float64, bool) {
+	var total, section, number, fraction float64
+	seenDigit := false
+	unit := 1.0
+
+	for _, r := range strings.TrimRight(s, "整正") {
+		if d, ok := cnDigits[r]; ok {
+			number = d
+			seenDigit = true
+			continue
+		}
+		if u, ok := cnSmallUnits[r]; ok {
+			if number == 0 {
+				number = 1 // "拾万" 即 "壹拾万"
+			}
+			section += number * u
+			number = 0
+			seenDigit = true
+			continue
+		}
+		switch r {
+		case '万':
+			total += (section + number) * 1e4
+			section, number = 0, 0
+			unit = 1e4
+		case '亿':
+			total = (total + section + number) * 1e8
+			section, number = 0, 0
+			unit = 1e8
+		case '元', '圆':
+			total += section + number
+			section, number = 0, 0
+		case '角':
+			fraction += number * 0.1
+			number = 0
+		case '分':
+			fraction += number * 0.01
+			number = 0
+		default:
+			return 0, 0, false
+		}
+	}
+	if !seenDigit {
+		return 0, 0, false
+	}
+	return total + section + number + fraction, unit, true
+}
+
+// fillAmount 从文本中定位首个金额并写入 amount/amountValue/amountCurrency 字段
+func fillAmount(record Record, text string) {
+	raw := DefaultPatterns.Amount.FindString(text)
+	if raw == "" {
+		return
+	}
+	record["amount"] = strings.TrimSpace(raw)
+	if a, ok := parseAmount(raw); ok {
+		record["amountValue"] = a.Decimal()
+		record["amountCurrency"] = a.Currency
+	}
+}
@@ -0,0 +1,74 @@
+package extractor
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in       string
+		ok       bool
+		decimal  string
+		currency string
+		capital  bool
+	}{
+		{in: "10000元", ok: true, decimal: "10000.00", currency: "CNY"},
+		{in: "人民币1,234.5元", ok: true, decimal: "1234.50", currency: "CNY"},
+		{in: "5万元", ok: true, decimal: "50000.00", currency: "CNY"},
+		{in: "1.2亿元", ok: true, decimal: "120000000.00", currency: "CNY"},
+		{in: "壹万贰仟元整", ok: true, decimal: "12000.00", currency: "CNY", capital: true},
+		{in: "人民币叁佰元伍角", ok: true, decimal: "300.50", currency: "CNY", capital: true},
+		{in: "壹亿贰仟万圆", ok: true, decimal: "120000000.00", currency: "CNY", capital: true},
+		{in: "拾万元", ok: true, decimal: "100000.00", currency: "CNY", capital: true},
+		{in: "1万元至2万元", ok: true, decimal: "10000.00-20000.00", currency: "CNY"},
+		{in: "1-2万元", ok: true, decimal: "10000.00-20000.00", currency: "CNY"},
+		{in: "5000美元", ok: true, decimal: "5000.00", currency: "USD"},
+		{in: "", ok: false},
+		{in: "元", ok: false},
+		{in: "1.2.3元", ok: false},
+		{in: "若干元", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			a, ok := parseAmount(tt.in)
+			if ok != tt.ok {
+				t.Fatalf("parseAmount(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got := a.Decimal(); got != tt.decimal {
+				t.Errorf("Decimal() = %q, want %q", got, tt.decimal)
+			}
+			if a.Currency != tt.currency {
+				t.Errorf("Currency = %q, want %q", a.Currency, tt.currency)
+			}
+			if a.Capital != tt.capital {
+				t.Errorf("Capital = %v, want %v", a.Capital, tt.capital)
+			}
+			if a.Raw != tt.in {
+				t.Errorf("Raw = %q, want %q", a.Raw, tt.in)
+			}
+		})
+	}
+}
+
+func TestParseCases_Amount(t *testing.T) {
+	e := NewExtractor(nil)
+	text := `民事起诉状
+被告：张三，住址：北京市
+诉讼请求：
+一、判令被告偿还借款人民币伍万元整；
+事实与理由：
+2020年被告另欠10元。
+此致`
+	result := e.parseCases(text, []string{"amount"})
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(result))
+	}
+	if got := result[0]["amount"]; got != "人民币伍万元整" {
+		t.Errorf("amount: expected raw %q, got %q", "人民币伍万元整", got)
+	}
+	if got := result[0]["amountValue"]; got != "50000.00" {
+		t.Errorf("amountValue: expected %q, got %q", "50000.00", got)
+	}
+}
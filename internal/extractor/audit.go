@@ -0,0 +1,275 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"legal-extractor/internal/config"
+)
+
+// AuditRecord 一次提取的审计记录：说明数据如何取得，不含任何提取到的字段值。
+// 文件名可能包含当事人姓名，只记录扩展名与内容哈希
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	InputHash string    `json:"inputHash"` // 文件内容的 SHA-256
+	FileExt   string    `json:"fileExt"`
+	Size      int       `json:"size"`
+	// Path 实际采用的提取路径 (PlanNative / PlanBridge / PlanOCR)，Provider 为给出结果的识别引擎 (含备用引擎与 winocr)；
+	// 邮件取各附件中开销最大的路径
+	Path       string `json:"path,omitempty"`
+	Provider   string `json:"provider,omitempty"`
+	TotalPages int    `json:"totalPages"`
+	// OCRPages 实际送入识别的页数，结果为空后改用备用引擎时各次调用累计
+	OCRPages int `json:"ocrPages"`
+	// RawText 识别引擎未能结构化解析，记录按其返回的原始文字在本地提取
+	RawText bool `json:"rawText,omitempty"`
+	// CacheHit 结果来自内容哈希缓存，本次没有解析或识别
+	CacheHit bool `json:"cacheHit,omitempty"`
+	Records  int  `json:"records"`
+	// FieldsFound 至少一条记录中非空的字段键 (不含取值)，按字母顺序排列
+	FieldsFound []string `json:"fieldsFound"`
+	// Warnings 记录中的提示，其中的号码、日期等取值已隐去
+	Warnings   []string `json:"warnings,omitempty"`
+	DurationMs int64    `json:"durationMs"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// AuditSink 接收审计记录。与调试日志不同，审计记录需要留存，实现应尽力持久化且不影响提取流程
+type AuditSink interface {
+	Audit(rec AuditRecord)
+}
+
+// AuditFileSink 以 JSON Lines 格式将审计记录追加写入文件
+type AuditFileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewAuditFileSink 创建写入 path 的审计记录器，所在目录不存在时自动创建
+func NewAuditFileSink(path string) *AuditFileSink {
+	return &AuditFileSink{path: path}
+}
+
+// Audit 实现 AuditSink，写入失败时静默忽略
+func (s *AuditFileSink) Audit(rec AuditRecord) {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(line, '\n'))
+}
+
+// AuditLogKey 审计日志条目的专用属性，值为 true，便于日志系统将审计记录与调试日志分开留存
+const AuditLogKey = "audit"
+
+// AuditLogSink 将审计记录写入 slog 日志，每条带 AuditLogKey 属性
+type AuditLogSink struct {
+	logger *slog.Logger
+}
+
+// NewAuditLogSink 创建写入 logger 的审计记录器，logger 为 nil 时使用 slog.Default()
+func NewAuditLogSink(logger *slog.Logger) *AuditLogSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AuditLogSink{logger: logger}
+}
+
+// Audit 实现 AuditSink
+func (s *AuditLogSink) Audit(rec AuditRecord) {
+	attrs := []slog.Attr{
+		slog.Bool(AuditLogKey, true),
+		slog.String("inputHash", rec.InputHash),
+		slog.String("fileExt", rec.FileExt),
+		slog.Int("size", rec.Size),
+		slog.String("path", rec.Path),
+		slog.String("provider", rec.Provider),
+		slog.Int("totalPages", rec.TotalPages),
+		slog.Int("ocrPages", rec.OCRPages),
+		slog.Bool("rawText", rec.RawText),
+		slog.Bool("cacheHit", rec.CacheHit),
+		slog.Int("records", rec.Records),
+		slog.Any("fieldsFound", rec.FieldsFound),
+		slog.Any("warnings", rec.Warnings),
+		slog.Int64("durationMs", rec.DurationMs),
+	}
+	if rec.Error != "" {
+		attrs = append(attrs, slog.String("error", rec.Error))
+	}
+	s.logger.LogAttrs(context.Background(), slog.LevelInfo, "提取审计记录", attrs...)
+}
+
+// AuditLogToSlog extraction.audit_log 取该值时审计记录写入程序日志，而非单独的文件
+const AuditLogToSlog = "log"
+
+// AuditSinkFromConfig 按 conf.yaml 中的 extraction.audit_log 创建审计记录器：为空时不记录 (返回 nil)，
+// 为 "log" 时写入 logger，否则视为文件路径 (相对路径相对于配置目录)
+func AuditSinkFromConfig(logger *slog.Logger) AuditSink {
+	switch target := strings.TrimSpace(config.Get().Extraction.AuditLog); target {
+	case "":
+		return nil
+	case AuditLogToSlog:
+		return NewAuditLogSink(logger)
+	default:
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(config.Dir(), target)
+		}
+		return NewAuditFileSink(target)
+	}
+}
+
+// SetAuditSink 设置审计记录器 (传入 nil 表示不记录)
+func (e *Extractor) SetAuditSink(s AuditSink) {
+	e.audit = s
+}
+
+// extractionRoute 一次提取实际采用的路径，由 extractPdf、ocrWithFallback 等在作出选择时写入，
+// 供审计记录、批量提取的 OCRUsed 与缓存使用。方法对 nil 接收者不做任何事
+type extractionRoute struct {
+	path       string
+	provider   string
+	totalPages int
+	ocrPages   int
+	rawText    bool
+	cacheHit   bool
+}
+
+type routeKey struct{}
+
+// withRoute 返回携带新 extractionRoute 的 ctx
+func withRoute(ctx context.Context) (context.Context, *extractionRoute) {
+	r := &extractionRoute{}
+	return context.WithValue(ctx, routeKey{}, r), r
+}
+
+// routeFrom 取出 ctx 中的 extractionRoute，没有时返回 nil
+func routeFrom(ctx context.Context) *extractionRoute {
+	r, _ := ctx.Value(routeKey{}).(*extractionRoute)
+	return r
+}
+
+// use 记录采用的路径，多次记录 (邮件的多个附件) 时保留开销最大者
+func (r *extractionRoute) use(path string) {
+	if r != nil && (r.path == "" || planCost[path] > planCost[r.path]) {
+		r.path = path
+	}
+}
+
+// addPages 累计文档页数
+func (r *extractionRoute) addPages(n int) {
+	if r != nil {
+		r.totalPages += n
+	}
+}
+
+// recognized 记录一次识别调用的引擎与送识别的页数
+func (r *extractionRoute) recognized(provider string, pages int) {
+	if r != nil {
+		r.provider = provider
+		r.ocrPages += pages
+	}
+}
+
+// parsedRawText 记录结果按识别引擎返回的原始文字在本地提取
+func (r *extractionRoute) parsedRawText() {
+	if r != nil {
+		r.rawText = true
+	}
+}
+
+// hit 记录结果来自内容哈希缓存，path 为缓存的结果当初实际采用的路径
+func (r *extractionRoute) hit(path string) {
+	if r != nil {
+		r.cacheHit = true
+		r.use(path)
+	}
+}
+
+// auditExtraction 为一次 ExtractDataContext 或 ExtractStream 调用生成审计记录并交给审计记录器
+func (e *Extractor) auditExtraction(fileData []byte, fileName string, started time.Time, route *extractionRoute, records []Record, err error) {
+	if e.audit == nil {
+		return
+	}
+	rec := AuditRecord{
+		Time:        started,
+		InputHash:   e.calculateHash(fileData),
+		FileExt:     strings.ToLower(filepath.Ext(fileName)),
+		Size:        len(fileData),
+		Records:     len(records),
+		FieldsFound: []string{},
+		DurationMs:  time.Since(started).Milliseconds(),
+	}
+	if route != nil {
+		rec.Path, rec.Provider = route.path, route.provider
+		rec.TotalPages, rec.OCRPages = route.totalPages, route.ocrPages
+		rec.RawText, rec.CacheHit = route.rawText, route.cacheHit
+	}
+	if err != nil {
+		rec.Error = redactAudit(err.Error())
+	}
+
+	found := make(map[string]bool)
+	for _, r := range records {
+		for k, v := range r {
+			if !strings.HasPrefix(k, "_") && strings.TrimSpace(v) != "" {
+				found[k] = true
+			}
+		}
+	}
+	for k := range found {
+		rec.FieldsFound = append(rec.FieldsFound, k)
+	}
+	sort.Strings(rec.FieldsFound)
+	for _, w := range batchWarnings(records) {
+		rec.Warnings = append(rec.Warnings, redactAudit(w))
+	}
+
+	e.audit.Audit(rec)
+}
+
+var (
+	// auditValue 提示中括号内的取值，如 "出生日期 (1990-01-01) 与…不一致"
+	auditValue = regexp.MustCompile(`[(（][^)）]*[)）]`)
+	// auditDigits 其余位置出现的号码
+	auditDigits = regexp.MustCompile(`\d[\d\-Xx*]{3,}`)
+)
+
+// redactAudit 隐去审计文本中的号码、日期等个人信息
+func redactAudit(s string) string {
+	s = auditValue.ReplaceAllString(s, "(已隐去)")
+	return auditDigits.ReplaceAllString(s, "***")
+}
+
+// auditTimer 返回记录提取路径的 ctx 与在提取结束时写入审计记录的函数，未设置审计记录器时不做任何事。
+// ctx 中已有 extractionRoute (如批量提取) 时沿用，调用方与审计记录看到同一份路径
+func (e *Extractor) auditTimer(ctx context.Context, fileData []byte, fileName string) (context.Context, func(records []Record, err error)) {
+	if e.audit == nil {
+		return ctx, func([]Record, error) {}
+	}
+	started := time.Now()
+	route := routeFrom(ctx)
+	if route == nil {
+		ctx, route = withRoute(ctx)
+	}
+	return ctx, func(records []Record, err error) {
+		e.auditExtraction(fileData, fileName, started, route, records, err)
+	}
+}
@@ -0,0 +1,135 @@
+package extractor
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// auditRecorder 在内存中收集审计记录
+type auditRecorder struct {
+	records []AuditRecord
+}
+
+func (a *auditRecorder) Audit(rec AuditRecord) {
+	a.records = append(a.records, rec)
+}
+
+func TestAuditExtraction(t *testing.T) {
+	data := readFixture(t, "complaint.docx")
+	sink := &auditRecorder{}
+	e := NewExtractor(nil)
+	e.SetAuditSink(sink)
+
+	records, err := e.ExtractData(data, "张三起诉状.docx", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(sink.records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(sink.records))
+	}
+	rec := sink.records[0]
+	if rec.InputHash != e.calculateHash(data) || rec.FileExt != ".docx" || rec.Size != len(data) {
+		t.Errorf("Unexpected input in audit record: %+v", rec)
+	}
+	if rec.Path != PlanNative || rec.Records != len(records) {
+		t.Errorf("Expected native path with %d records, got %s with %d", len(records), rec.Path, rec.Records)
+	}
+	for _, field := range []string{"defendant", "idNumber"} {
+		if !slices.Contains(rec.FieldsFound, field) {
+			t.Errorf("FieldsFound %v lacks %s", rec.FieldsFound, field)
+		}
+	}
+
+	// 审计记录不含字段取值与文件名
+	line, _ := json.Marshal(rec)
+	for _, pii := range []string{records[0]["defendant"], records[0]["idNumber"], "张三"} {
+		if pii != "" && strings.Contains(string(line), pii) {
+			t.Errorf("Audit record leaks %q: %s", pii, line)
+		}
+	}
+}
+
+func TestAuditFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	sink := NewAuditFileSink(path)
+	sink.Audit(AuditRecord{InputHash: "a", Path: PlanNative})
+	sink.Audit(AuditRecord{InputHash: "b", Path: PlanOCR, Provider: "mock"})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+	var got []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, rec.InputHash)
+	}
+	if !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Expected records a, b, got %v", got)
+	}
+}
+
+func TestRedactAudit(t *testing.T) {
+	in := "身份证号码 (110101199001011234) 校验未通过；出生日期 (1990-01-01) 与身份证号码推导的 (1991-02-03) 不一致；电话 13800138000"
+	got := redactAudit(in)
+	for _, pii := range []string{"110101199001011234", "1990-01-01", "1991-02-03", "13800138000"} {
+		if strings.Contains(got, pii) {
+			t.Errorf("redactAudit kept %q: %s", pii, got)
+		}
+	}
+	if !strings.Contains(got, "校验未通过") {
+		t.Errorf("redactAudit should keep the warning text: %s", got)
+	}
+}
+
+// renamedOCR 以另一个名称报告的识别引擎，用于区分主引擎与备用引擎
+type renamedOCR struct {
+	OCRProvider
+	name string
+}
+
+func (r renamedOCR) Name() string { return r.name }
+
+func TestAuditExtraction_BackupProvider(t *testing.T) {
+	data := readFixture(t, "scanned.pdf")
+	sink := &auditRecorder{}
+	e := NewExtractor(nil)
+	e.SetAuditSink(sink)
+	e.SetOCRProvider(renamedOCR{&MockOCRProvider{Err: &NoDataError{Provider: "primary"}}, "primary"})
+	e.SetFallbackOCRProviders(renamedOCR{&MockOCRProvider{Records: []Record{{"defendant": "王五", "page": "1"}}}, "backup"})
+	e.FallbackOnEmpty = true
+
+	if _, err := e.ExtractData(data, "scanned.pdf", nil, nil); err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	// 再次提取命中缓存，不再调用识别引擎
+	if _, err := e.ExtractData(data, "scanned.pdf", nil, nil); err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(sink.records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(sink.records))
+	}
+
+	rec := sink.records[0]
+	if rec.Path != PlanOCR || rec.Provider != "backup" || rec.CacheHit {
+		t.Errorf("Expected the backup provider on the OCR path, got path %q provider %q cacheHit %v", rec.Path, rec.Provider, rec.CacheHit)
+	}
+	if rec.TotalPages == 0 || rec.OCRPages != 2*rec.TotalPages {
+		t.Errorf("Expected both providers' pages to be counted, got %d OCR pages of %d", rec.OCRPages, rec.TotalPages)
+	}
+
+	cached := sink.records[1]
+	if !cached.CacheHit || cached.Provider != "" || cached.OCRPages != 0 {
+		t.Errorf("Expected a cache hit without OCR, got %+v", cached)
+	}
+}
@@ -2,24 +2,46 @@ package extractor
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"legal-extractor/internal/config"
 	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dslipak/pdf"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
+// baiduMaxPagesPerChunk 长 PDF 物理切片的每片页数 (从50调小为20，以显著提升云端解析的稳定性)
+const baiduMaxPagesPerChunk = 20
+
+// baiduMaxFileSize 百度接口单次请求的文件上限 (按 base64 编码后的大小计)，超过时按页拆分后分别识别
+const baiduMaxFileSize = 10 << 20
+
+// baiduErrDailyLimit 百度接口"每日请求量超限"错误码
+const baiduErrDailyLimit = 17
+
 // BaiduClient 百度 AI Studio PaddleOCR 客户端
 type BaiduClient struct {
 	config     config.BaiduConfig
 	httpClient *http.Client
 	logger     *slog.Logger
+	name       string        // 引擎名称，为空时为 "baidu"；备用接口为 "baidu-fallback-N"，便于区分调用量与校验结果
+	usage      *UsageCounter // 逐次记录实际发出的识别请求 (含分块与重试)，为 nil 时不统计
+
+	maxFileSize int // 单次请求的文件上限，0 表示 baiduMaxFileSize
 }
 
 // BaiduOCRResponse 百度 Layout Parsing 响应结构
@@ -31,26 +53,101 @@ type BaiduOCRResponse struct {
 			Markdown struct {
 				Text string `json:"text"`
 			} `json:"markdown"`
+			PrunedResult struct {
+				// DocPreprocessorRes 仅在开启方向分类或去畸变时返回
+				DocPreprocessorRes *struct {
+					Angle int `json:"angle"`
+				} `json:"doc_preprocessor_res"`
+			} `json:"prunedResult"`
 		} `json:"layoutParsingResults"`
 	} `json:"result"`
 }
 
+// AngleKey 记录云端检测到的页面旋转角度 (90/180/270)，便于用户发现扫描方向有误的页面 (元数据)
+const AngleKey = "_angle"
+
 // NewBaiduClient 创建百度 OCR 客户端
 func NewBaiduClient(logger *slog.Logger) *BaiduClient {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	cfg := config.GetBaidu()
 	return &BaiduClient{
-		config: config.GetBaidu(),
-		httpClient: &http.Client{
-			Timeout: 180 * time.Second, // 增加到 180 秒，为复杂长文档预留充足处理时间
-		},
-		logger: logger,
+		config:     cfg,
+		httpClient: newHTTPClient(cfg, logger),
+		logger:     logger,
+	}
+}
+
+// setUsageCounter 实现 callCounter
+func (c *BaiduClient) setUsageCounter(u *UsageCounter) {
+	c.usage = u
+}
+
+// fallbackClients 按 fallback_api_urls 创建备用客户端，沿用同一 Token、HTTP 客户端与其他设置，
+// 供 FallbackOnEmpty 依次尝试
+func (c *BaiduClient) fallbackClients() []OCRProvider {
+	var backups []OCRProvider
+	for _, apiURL := range c.config.FallbackApiUrls {
+		if apiURL = strings.TrimSpace(apiURL); apiURL == "" {
+			continue
+		}
+		cfg := c.config
+		cfg.ApiUrl, cfg.FallbackApiUrls = apiURL, nil
+		backups = append(backups, &BaiduClient{
+			config:     cfg,
+			httpClient: c.httpClient,
+			logger:     c.logger,
+			name:       fmt.Sprintf("baidu-fallback-%d", len(backups)+1),
+		})
+	}
+	return backups
+}
+
+// newHTTPClient 按配置构造 HTTP 客户端：默认超时 180 秒，为复杂长文档预留充足处理时间；
+// 配置了 proxy 时走指定代理，否则沿用 HTTP_PROXY/HTTPS_PROXY 环境变量
+func newHTTPClient(cfg config.BaiduConfig, logger *slog.Logger) *http.Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 180 * time.Second
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			logger.Warn("代理地址无效，改用环境变量代理设置", "proxy", cfg.Proxy, "error", err)
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// SetHTTPClient 替换底层 HTTP 客户端 (如自定义 Transport、企业网络代理)，传入 nil 时忽略
+func (c *BaiduClient) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		c.httpClient = client
 	}
 }
 
 // ParseDocument 调用百度 Layout Parsing 接口解析文档
 func (c *BaiduClient) ParseDocument(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]Record, error) {
+	pages, err := c.RecognizePages(fileData, isPdf, onProgress)
+	if err != nil {
+		return nil, err
+	}
+	return DefaultPatterns.parseOCRPages(c.logger, c.Name(), pages, onProgress)
+}
+
+// RecognizePages 实现 PageRecognizer：调用百度 Layout Parsing 接口，返回各页的 Markdown 原文
+func (c *BaiduClient) RecognizePages(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]OCRPage, error) {
+	return c.RecognizePagesContext(context.Background(), fileData, isPdf, onProgress)
+}
+
+// RecognizePagesContext 实现 ContextPageRecognizer，同 RecognizePages
+func (c *BaiduClient) RecognizePagesContext(ctx context.Context, fileData []byte, isPdf bool, onProgress ProgressCallback) ([]OCRPage, error) {
 	c.logger.Info("开始调用百度 OCR 接口", "isPdf", isPdf, "dataSize", len(fileData))
 	if len(fileData) == 0 {
 		return nil, fmt.Errorf("文件内容为空")
@@ -60,9 +157,8 @@ func (c *BaiduClient) ParseDocument(fileData []byte, isPdf bool, onProgress Prog
 		return nil, fmt.Errorf("百度 AI Studio Token 未配置，请检查 config/conf.yaml")
 	}
 
-	// 1. 处理超长文档 (百度 API 限制单次 100 页)
-	var allPagesMarkdown []string
-	const maxPagesPerChunk = 20 // 调小切片粒度（从50改为20）以显著提升云端解析的稳定性
+	// 1. 处理超长、超大文档 (百度 API 限制单次 100 页、10MB)
+	var allPages []OCRPage
 
 	if isPdf {
 		// 获取总页数
@@ -71,109 +167,223 @@ func (c *BaiduClient) ParseDocument(fileData []byte, isPdf bool, onProgress Prog
 			totalPages := r.NumPage()
 			c.logger.Info("PDF 页数检测完成", "totalPages", totalPages)
 
-			if totalPages > maxPagesPerChunk {
-				c.logger.Info("启用大文件物理分块处理模式", "chunkSize", maxPagesPerChunk)
-				// 分块处理逻辑
-				for start := 1; start <= totalPages; start += maxPagesPerChunk {
-					end := start + maxPagesPerChunk - 1
-					if end > totalPages {
-						end = totalPages
-					}
-
-					c.logger.Info(fmt.Sprintf("正在处理分块: 第 %d-%d 页", start, end))
-					if onProgress != nil {
-						onProgress(start, totalPages, "正在分析文档结构并准备解析...")
-					}
-
-					// 1. 物理切片操作
-					var chunkBuffer bytes.Buffer
-					pageSelection := []string{fmt.Sprintf("%d-%d", start, end)}
-					err := api.Trim(bytes.NewReader(fileData), &chunkBuffer, pageSelection, nil)
-					if err != nil {
-						return nil, fmt.Errorf("PDF 切片失败: %w", err)
-					}
-
-					// 2. 实施“避让重试”策略处理云端 500 错误
-					var pages []string
-					maxRetries := 2
-					for retry := 0; retry <= maxRetries; retry++ {
-						if retry > 0 {
-							c.logger.Warn(fmt.Sprintf("分块 %d-%d 尝试第 %d 次重试...", start, end, retry))
-							time.Sleep(20 * time.Second) // 收到 500 后重试需等待更久，给服务器释放资源
-						}
-
-						if onProgress != nil {
-							onProgress(start, totalPages, fmt.Sprintf("正在对第 %d-%d 页进行深度识别...", start, end))
-						}
-
-						pages, err = c.callBaiduAPI(chunkBuffer.Bytes(), true, onProgress)
-						if err == nil {
-							break
-						}
-
-						// 如果是 500 错误且还有重试机会
-						if strings.Contains(err.Error(), "500") && retry < maxRetries {
-							continue
-						}
-						return nil, err // 其他严重错误或重试耗尽则退出
-					}
-
-					allPagesMarkdown = append(allPagesMarkdown, pages...)
-
-					// 3. 强制冷却，防止连续高压导致百度后端崩溃
-					if end < totalPages {
-						c.logger.Info("分块处理完成，进入 10 秒冷却期以释放云端算力...")
-						time.Sleep(10 * time.Second)
-					}
-				}
-			} else {
-				if onProgress != nil {
-					onProgress(1, totalPages, "正在进行深度识别与内容校对，请稍候...")
-				}
-				pages, err := c.callBaiduAPI(fileData, true, onProgress)
-				if err != nil {
-					return nil, err
-				}
-				allPagesMarkdown = append(allPagesMarkdown, pages...)
+			chunks, err := c.pdfChunks(fileData, totalPages)
+			if err != nil {
+				return nil, err
+			}
+			allPages, err = c.parseChunks(ctx, chunks, totalPages, onProgress)
+			if err != nil {
+				return nil, err
 			}
 		}
 	} else {
+		if base64.StdEncoding.EncodedLen(len(fileData)) > c.fileSizeLimit() {
+			return nil, fmt.Errorf("图片大小 (%.1f MB) 超过识别接口的上限 (编码后 %d MB)，请压缩后重试",
+				float64(len(fileData))/(1<<20), c.fileSizeLimit()>>20)
+		}
 		if onProgress != nil {
 			onProgress(1, 1, "正在对文档进行语义化识别...")
 		}
-		pages, err := c.callBaiduAPI(fileData, false, onProgress)
+		pages, err := c.callBaiduAPI(ctx, fileData, false, onProgress)
+		c.usage.Record(c.Name(), 1, 1, err)
 		if err != nil {
 			return nil, err
 		}
-		allPagesMarkdown = append(allPagesMarkdown, pages...)
+		allPages = append(allPages, pages...)
 	}
 
-	// 2. 按页解析汇总后的 Markdown
-	c.logger.Info("所有页面识别完成，开始按页提取法律实体", "totalFetchedPages", len(allPagesMarkdown))
-	var allRecords []Record
-	totalPages := len(allPagesMarkdown)
-	for i, pageMd := range allPagesMarkdown {
-		if onProgress != nil {
-			// 增加微小延迟 (50ms)，让前端有足够时间渲染进度条的跳动，避免瞬间完成
-			time.Sleep(50 * time.Millisecond)
-			onProgress(i+1, totalPages, fmt.Sprintf("正在结构化提取第 %d/%d 页的法律信息...", i+1, totalPages))
+	for i := range allPages {
+		allPages[i].Page = i + 1
+	}
+	c.logger.Info("所有页面识别完成", "totalFetchedPages", len(allPages))
+	return allPages, nil
+}
+
+// pdfChunk 送识别的一段连续页面 [start, end]
+type pdfChunk struct {
+	start, end int
+	data       []byte
+}
+
+func (c *BaiduClient) fileSizeLimit() int {
+	if c.maxFileSize > 0 {
+		return c.maxFileSize
+	}
+	return baiduMaxFileSize
+}
+
+// pdfChunks 将 PDF 拆分为每段不超过 baiduMaxPagesPerChunk 页、且编码后不超过文件上限的页面区间；
+// 超限的区间对半拆分，直至单页。单页仍超限时返回错误。无需拆分时返回原文件
+func (c *BaiduClient) pdfChunks(fileData []byte, totalPages int) ([]pdfChunk, error) {
+	limit := c.fileSizeLimit()
+	if totalPages <= baiduMaxPagesPerChunk && base64.StdEncoding.EncodedLen(len(fileData)) <= limit {
+		return []pdfChunk{{start: 1, end: max(totalPages, 1), data: fileData}}, nil
+	}
+
+	var chunks []pdfChunk
+	var split func(start, end int) error
+	split = func(start, end int) error {
+		var buf bytes.Buffer
+		if err := api.Trim(bytes.NewReader(fileData), &buf, []string{fmt.Sprintf("%d-%d", start, end)}, nil); err != nil {
+			return fmt.Errorf("PDF 切片失败: %w", err)
+		}
+		if base64.StdEncoding.EncodedLen(buf.Len()) <= limit {
+			chunks = append(chunks, pdfChunk{start: start, end: end, data: buf.Bytes()})
+			return nil
+		}
+		if start == end {
+			return fmt.Errorf("第 %d 页 (%.1f MB) 超过识别接口的文件大小上限 (编码后 %d MB)，请降低该页的扫描分辨率后重试",
+				start, float64(buf.Len())/(1<<20), limit>>20)
+		}
+		mid := (start + end) / 2
+		if err := split(start, mid); err != nil {
+			return err
+		}
+		return split(mid+1, end)
+	}
+
+	for start := 1; start <= totalPages; start += baiduMaxPagesPerChunk {
+		if err := split(start, min(start+baiduMaxPagesPerChunk-1, totalPages)); err != nil {
+			return nil, err
+		}
+	}
+	return chunks, nil
+}
+
+// parseChunks 识别 PDF 的各个分块：至多 chunk_concurrency 个分块同时在途，提交节奏由 chunk_qps 节流
+// (0 表示不节流)，结果按页码顺序拼接。任一分块失败后不再提交新分块，并返回该错误
+func (c *BaiduClient) parseChunks(ctx context.Context, chunks []pdfChunk, totalPages int, onProgress ProgressCallback) ([]OCRPage, error) {
+	concurrency := min(max(c.config.ChunkConcurrency, 1), len(chunks))
+	if len(chunks) > 1 {
+		c.logger.Info("启用大文件物理分块处理模式", "chunks", len(chunks), "concurrency", concurrency, "qps", c.config.ChunkQPS,
+			"maxPages", baiduMaxPagesPerChunk, "maxSize", c.fileSizeLimit())
+	}
+
+	// 进度回调可能来自多个协程，串行化后再转交
+	var progressMu sync.Mutex
+	progress := onProgress
+	if onProgress != nil {
+		progress = func(current, total int, message string) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			onProgress(current, total, message)
 		}
-		records := ParseMarkdown(pageMd)
-		for _, rec := range records {
-			// 标注准确的页码
-			if rec["page"] == "" {
-				rec["page"] = fmt.Sprintf("%d", i+1)
+	}
+
+	// 提交节流：按固定间隔发放提交时刻，第一个分块立即提交
+	var throttleMu sync.Mutex
+	next := time.Now()
+	waitTurn := func() {
+		if c.config.ChunkQPS <= 0 {
+			return
+		}
+		throttleMu.Lock()
+		now := time.Now()
+		at := next
+		if at.Before(now) {
+			at = now
+		}
+		next = at.Add(time.Duration(float64(time.Second) / c.config.ChunkQPS))
+		throttleMu.Unlock()
+		time.Sleep(at.Sub(now))
+	}
+
+	results := make([][]OCRPage, len(chunks))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+				if failed {
+					continue
+				}
+				waitTurn()
+				pages, err := c.parseChunk(ctx, chunks[i], len(chunks), totalPages, progress)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				results[i] = pages
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	var allPages []OCRPage
+	for _, pages := range results {
+		allPages = append(allPages, pages...)
+	}
+	return allPages, nil
+}
+
+// parseChunk 识别单个分块，云端返回 500 错误时等待后重试
+func (c *BaiduClient) parseChunk(ctx context.Context, chunk pdfChunk, chunkCount, totalPages int, onProgress ProgressCallback) ([]OCRPage, error) {
+	start, end := chunk.start, chunk.end
+	if chunkCount > 1 {
+		c.logger.Info(fmt.Sprintf("正在处理分块: 第 %d-%d 页", start, end), "size", len(chunk.data))
+	}
+
+	// 实施“避让重试”策略处理云端 500 错误
+	maxRetries := 2
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			c.logger.Warn(fmt.Sprintf("分块 %d-%d 尝试第 %d 次重试...", start, end, retry))
+			// 收到 500 后重试需等待更久，给服务器释放资源
+			select {
+			case <-time.After(20 * time.Second):
+			case <-ctx.Done():
+				return nil, ctx.Err()
 			}
-			allRecords = append(allRecords, rec)
 		}
+
+		if onProgress != nil {
+			if chunkCount > 1 {
+				onProgress(start, totalPages, fmt.Sprintf("正在对第 %d-%d 页进行深度识别...", start, end))
+			} else {
+				onProgress(1, totalPages, "正在进行深度识别与内容校对，请稍候...")
+			}
+		}
+
+		pages, err := c.callBaiduAPI(ctx, chunk.data, true, onProgress)
+		c.usage.Record(c.Name(), 1, end-start+1, err)
+		if err == nil {
+			return pages, nil
+		}
+
+		// 如果是 500 错误且还有重试机会
+		if strings.Contains(err.Error(), "500") && retry < maxRetries {
+			continue
+		}
+		return nil, err // 其他严重错误或重试耗尽则退出
 	}
+}
 
-	c.logger.Info("数据提取完成", "recordCount", len(allRecords))
-	return allRecords, nil
+// baiduHTTPError 百度接口返回非 200 状态码
+type baiduHTTPError struct {
+	StatusCode int
 }
 
-// callBaiduAPI 封装底层的 API 调用逻辑
-func (c *BaiduClient) callBaiduAPI(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]string, error) {
+func (e *baiduHTTPError) Error() string {
+	return fmt.Sprintf("百度 API 响应异常 (HTTP %d)", e.StatusCode)
+}
+
+// callBaiduAPI 封装底层的 API 调用逻辑，ctx 中的追踪上下文随请求头传给识别服务
+func (c *BaiduClient) callBaiduAPI(ctx context.Context, fileData []byte, isPdf bool, onProgress ProgressCallback) ([]OCRPage, error) {
 	c.logger.Info("正在向百度 AI Studio 发送 POST 请求...")
 	fileBase64 := base64.StdEncoding.EncodeToString(fileData)
 	fileType := 1
@@ -184,8 +394,8 @@ func (c *BaiduClient) callBaiduAPI(fileData []byte, isPdf bool, onProgress Progr
 	payload := map[string]any{
 		"file":                      fileBase64,
 		"fileType":                  fileType,
-		"useDocOrientationClassify": false,
-		"useDocUnwarping":           false,
+		"useDocOrientationClassify": c.config.OrientationClassify, // 横放、倒置的扫描页先旋转校正再识别
+		"useDocUnwarping":           c.config.DocUnwarping,
 		"useChartRecognition":       false,
 	}
 
@@ -194,13 +404,14 @@ func (c *BaiduClient) callBaiduAPI(fileData []byte, isPdf bool, onProgress Progr
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", c.config.ApiUrl, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.config.ApiUrl, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.config.Token))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	// 开启心跳协程，在长耗时请求期间持续反馈进度，防止 UI “假死”
 	done := make(chan bool)
@@ -239,7 +450,7 @@ func (c *BaiduClient) callBaiduAPI(fileData []byte, isPdf bool, onProgress Progr
 
 	// 增加状态码校验：非 200 状态码一律视为失败，触发重试
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("百度 API 响应异常 (HTTP %d)", resp.StatusCode)
+		return nil, &baiduHTTPError{StatusCode: resp.StatusCode}
 	}
 
 	var ocrResp BaiduOCRResponse
@@ -249,16 +460,104 @@ func (c *BaiduClient) callBaiduAPI(fileData []byte, isPdf bool, onProgress Progr
 		return nil, fmt.Errorf("解析云端数据失败: %w", err)
 	}
 
+	if ocrResp.ErrorCode == baiduErrDailyLimit {
+		return nil, fmt.Errorf("%w (百度 API 错误 %d: %s)", ErrQuotaExceeded, ocrResp.ErrorCode, ocrResp.ErrorMsg)
+	}
 	if ocrResp.ErrorCode != 0 {
 		return nil, fmt.Errorf("百度 API 错误 (%d): %s", ocrResp.ErrorCode, ocrResp.ErrorMsg)
 	}
 
-	var pages []string
+	var pages []OCRPage
 	if len(ocrResp.Result.LayoutParsingResults) == 0 {
 		c.logger.Warn("百度 API 返回结果为空")
 	}
 	for _, result := range ocrResp.Result.LayoutParsingResults {
-		pages = append(pages, result.Markdown.Text)
+		page := OCRPage{Markdown: result.Markdown.Text, Angle: -1}
+		if pre := result.PrunedResult.DocPreprocessorRes; pre != nil {
+			page.Angle = pre.Angle
+		}
+		pages = append(pages, page)
 	}
 	return pages, nil
 }
+
+// BatchDocument 批量解析中的单个待处理文档
+type BatchDocument struct {
+	FileName string
+	Data     []byte
+}
+
+// BatchResult 批量解析中单个文档的结果
+type BatchResult struct {
+	Records []Record
+	Err     error
+}
+
+// defaultBatchConcurrency 未配置 batch_concurrency 时的并发数：不超过 GOMAXPROCS，
+// 也不超过每秒提交上限 (更多的并发只会排队等待提交令牌)
+func defaultBatchConcurrency(qps float64) int {
+	return max(1, min(runtime.GOMAXPROCS(0), int(math.Ceil(qps))))
+}
+
+// ParseBatch 并发解析多个文档，所有请求共享同一客户端与 Token，结果按文件名返回
+// 并发数由 batch_concurrency 控制，提交节奏由 submit_qps 节流，避免触发云端 QPS 限制
+func (c *BaiduClient) ParseBatch(docs []BatchDocument, onProgress ProgressCallback) map[string]BatchResult {
+	results := make(map[string]BatchResult, len(docs))
+	if len(docs) == 0 {
+		return results
+	}
+
+	qps := c.config.SubmitQPS
+	if qps <= 0 {
+		qps = 2
+	}
+	concurrency := c.config.BatchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency(qps)
+	}
+	if concurrency > len(docs) {
+		concurrency = len(docs)
+	}
+
+	c.logger.Info("启动百度批量解析", "documents", len(docs), "concurrency", concurrency, "qps", qps)
+
+	// 提交节流：每个文档提交前需领取一个令牌
+	throttle := time.NewTicker(time.Duration(float64(time.Second) / qps))
+	defer throttle.Stop()
+
+	jobs := make(chan BatchDocument)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	processed := 0
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for doc := range jobs {
+				<-throttle.C
+				isPdf := strings.EqualFold(filepath.Ext(doc.FileName), ".pdf")
+				records, err := c.ParseDocument(doc.Data, isPdf, nil)
+				if errors.Is(err, ErrNoData) {
+					err = nil
+				}
+
+				mu.Lock()
+				results[doc.FileName] = BatchResult{Records: records, Err: err}
+				processed++
+				if onProgress != nil {
+					onProgress(processed, len(docs), fmt.Sprintf("已完成 %s 的云端识别", doc.FileName))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, doc := range docs {
+		jobs <- doc
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
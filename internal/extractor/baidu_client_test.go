@@ -0,0 +1,410 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"legal-extractor/internal/config"
+
+	"github.com/dslipak/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// newTestBaiduClient 构造指向测试服务器的百度客户端
+func newTestBaiduClient(srv *httptest.Server, cfg config.BaiduConfig) *BaiduClient {
+	cfg.Token = "test-token"
+	cfg.ApiUrl = srv.URL
+	return &BaiduClient{
+		config:     cfg,
+		httpClient: srv.Client(),
+		logger:     slog.Default(),
+	}
+}
+
+// echoMarkdownHandler 将上传内容原样作为 Markdown 返回，便于断言结果与文档的对应关系
+func echoMarkdownHandler(t *testing.T, onRequest func()) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if onRequest != nil {
+			onRequest()
+		}
+		var payload struct {
+			File string `json:"file"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		content, _ := base64.StdEncoding.DecodeString(payload.File)
+		fmt.Fprintf(w, `{"error_code":0,"result":{"layoutParsingResults":[{"markdown":{"text":%q}}]}}`, string(content))
+	}
+}
+
+func TestBaiduClient_ParseBatch(t *testing.T) {
+	var inFlight, maxInFlight, total int32
+	var mu sync.Mutex
+	srv := httptest.NewServer(echoMarkdownHandler(t, func() {
+		cur := atomic.AddInt32(&inFlight, 1)
+		atomic.AddInt32(&total, 1)
+		mu.Lock()
+		if cur > maxInFlight {
+			maxInFlight = cur
+		}
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}))
+	defer srv.Close()
+
+	c := newTestBaiduClient(srv, config.BaiduConfig{BatchConcurrency: 2, SubmitQPS: 100})
+
+	var docs []BatchDocument
+	for i := 1; i <= 5; i++ {
+		docs = append(docs, BatchDocument{
+			FileName: fmt.Sprintf("scan%d.png", i),
+			Data:     []byte(fmt.Sprintf("被告：被告%d\n", i)),
+		})
+	}
+
+	results := c.ParseBatch(docs, nil)
+	if len(results) != len(docs) {
+		t.Fatalf("Expected %d results, got %d", len(docs), len(results))
+	}
+	for i := 1; i <= 5; i++ {
+		res := results[fmt.Sprintf("scan%d.png", i)]
+		if res.Err != nil {
+			t.Fatalf("scan%d.png: unexpected error %v", i, res.Err)
+		}
+		if len(res.Records) != 1 || res.Records[0]["defendant"] != fmt.Sprintf("被告%d", i) {
+			t.Errorf("scan%d.png: unexpected records %v", i, res.Records)
+		}
+	}
+	if total != 5 {
+		t.Errorf("Expected 5 API calls, got %d", total)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("Concurrency limit exceeded: %d in flight", maxInFlight)
+	}
+}
+
+// countingTransport 记录经过的请求数，用于断言注入的客户端确实被使用
+type countingTransport struct {
+	calls int32
+	next  http.RoundTripper
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return t.next.RoundTrip(req)
+}
+
+func TestBaiduClient_SetHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(echoMarkdownHandler(t, nil))
+	defer srv.Close()
+
+	client := newTestBaiduClient(srv, config.BaiduConfig{})
+	transport := &countingTransport{next: srv.Client().Transport}
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	if _, err := client.ParseDocument([]byte("被告：张三"), false, nil); err != nil {
+		t.Fatalf("ParseDocument failed: %v", err)
+	}
+	if atomic.LoadInt32(&transport.calls) != 1 {
+		t.Errorf("Expected injected transport to handle 1 request, got %d", transport.calls)
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	client := newHTTPClient(config.BaiduConfig{}, slog.Default())
+	if client.Timeout != 180*time.Second {
+		t.Errorf("Expected default timeout 180s, got %s", client.Timeout)
+	}
+
+	client = newHTTPClient(config.BaiduConfig{Timeout: 5 * time.Second, Proxy: "http://proxy.local:3128"}, slog.Default())
+	if client.Timeout != 5*time.Second {
+		t.Errorf("Expected configured timeout 5s, got %s", client.Timeout)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	proxyURL, err := client.Transport.(*http.Transport).Proxy(req)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.local:3128" {
+		t.Errorf("Expected configured proxy, got %v (err %v)", proxyURL, err)
+	}
+}
+
+func TestBaiduClient_OrientationAngle(t *testing.T) {
+	var gotClassify bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			UseDocOrientationClassify bool `json:"useDocOrientationClassify"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		gotClassify = payload.UseDocOrientationClassify
+		fmt.Fprint(w, `{"error_code":0,"result":{"layoutParsingResults":[`+
+			`{"markdown":{"text":"被告：张三\n"},"prunedResult":{"doc_preprocessor_res":{"angle":180}}},`+
+			`{"markdown":{"text":"被告：李四\n"},"prunedResult":{"doc_preprocessor_res":{"angle":0}}}]}}`)
+	}))
+	defer srv.Close()
+
+	c := newTestBaiduClient(srv, config.BaiduConfig{OrientationClassify: true})
+	records, err := c.ParseDocument([]byte("scan"), false, nil)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if !gotClassify {
+		t.Error("Expected useDocOrientationClassify to be sent as true")
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %v", records)
+	}
+	if records[0][AngleKey] != "180" {
+		t.Errorf("Expected rotated page to carry angle 180, got %q", records[0][AngleKey])
+	}
+	if _, ok := records[1][AngleKey]; ok {
+		t.Errorf("Upright page should not carry an angle, got %q", records[1][AngleKey])
+	}
+}
+
+func TestExtractor_TestProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"error_code":0,"result":{"layoutParsingResults":[{"markdown":{"text":""}}]}}`)
+	}))
+	defer srv.Close()
+
+	e := NewExtractor(nil)
+	e.SetOCRProvider(newTestBaiduClient(srv, config.BaiduConfig{}))
+	if got := e.TestProvider("baidu"); !got.OK || got.Error != "" {
+		t.Errorf("Expected valid credentials, got %+v", got)
+	}
+
+	bad := newTestBaiduClient(srv, config.BaiduConfig{})
+	bad.config.Token = "expired-secret"
+	e.SetOCRProvider(bad)
+	got := e.TestProvider("baidu")
+	if got.OK || !strings.Contains(got.Error, "401") || !strings.Contains(got.Hint, "Token") {
+		t.Errorf("Expected auth failure with a hint, got %+v", got)
+	}
+	if strings.Contains(got.Error+got.Hint, "expired-secret") {
+		t.Errorf("Result leaks the token: %+v", got)
+	}
+
+	if got := e.TestProvider("tencent"); got.OK || got.Error == "" {
+		t.Errorf("Expected error for an unconfigured provider, got %+v", got)
+	}
+}
+
+func TestBaiduClient_ParseDocument_SplitsOversizedPDF(t *testing.T) {
+	data, pages := mergedFixture(t, "complaint.pdf", 4)
+	var mu sync.Mutex
+	var sizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			File string `json:"file"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		chunk, _ := base64.StdEncoding.DecodeString(payload.File)
+		pr, err := pdf.NewReader(bytes.NewReader(chunk), int64(len(chunk)))
+		if err != nil {
+			t.Errorf("chunk is not a valid PDF: %v", err)
+			return
+		}
+		mu.Lock()
+		sizes = append(sizes, len(payload.File))
+		mu.Unlock()
+		results := make([]string, pr.NumPage())
+		for i := range results {
+			results[i] = `{"markdown":{"text":"被告：张三"}}`
+		}
+		fmt.Fprintf(w, `{"error_code":0,"result":{"layoutParsingResults":[%s]}}`, strings.Join(results, ","))
+	}))
+	defer srv.Close()
+
+	c := newTestBaiduClient(srv, config.BaiduConfig{})
+	// 上限小于整份文件切片后的大小，迫使按页拆分 (切片结果的大小每次略有浮动，留出余量)
+	var whole bytes.Buffer
+	if err := api.Trim(bytes.NewReader(data), &whole, []string{fmt.Sprintf("1-%d", pages)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.maxFileSize = base64.StdEncoding.EncodedLen(min(len(data), whole.Len())) * 9 / 10
+	usage := NewUsageCounter("")
+	c.setUsageCounter(usage)
+	records, err := c.ParseDocument(data, true, nil)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if len(sizes) < 2 {
+		t.Fatalf("requests = %d, want the oversized PDF split into several", len(sizes))
+	}
+	// 调用量按实际发出的请求统计，每个分块计一次
+	if got := usage.Snapshot()["baidu"]; got.Calls != int64(len(sizes)) || got.Pages != int64(pages) {
+		t.Errorf("usage = %+v, want %d calls covering %d pages", got, len(sizes), pages)
+	}
+	for _, n := range sizes {
+		if n > c.maxFileSize {
+			t.Errorf("chunk size %d exceeds limit %d", n, c.maxFileSize)
+		}
+	}
+	if len(records) != pages {
+		t.Fatalf("records = %d, want one per page", len(records))
+	}
+	for i, r := range records {
+		if want := strconv.Itoa(i + 1); r["page"] != want {
+			t.Errorf("record %d page = %q, want %q", i, r["page"], want)
+		}
+	}
+
+	c.maxFileSize = 16
+	if _, err := c.ParseDocument(data, true, nil); err == nil || !strings.Contains(err.Error(), "第 1 页") {
+		t.Errorf("err = %v, want single-page size error", err)
+	}
+}
+
+func TestBaiduClient_ParseChunks_BoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		mu.Lock()
+		maxInFlight = max(maxInFlight, cur)
+		mu.Unlock()
+
+		var payload struct {
+			File string `json:"file"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		content, _ := base64.StdEncoding.DecodeString(payload.File)
+		// 靠前的分块返回更慢，检验结果仍按页码顺序拼接
+		n, _ := strconv.Atoi(strings.TrimPrefix(string(content), "被告：张"))
+		time.Sleep(time.Duration(10-n) * 5 * time.Millisecond)
+		fmt.Fprintf(w, `{"error_code":0,"result":{"layoutParsingResults":[{"markdown":{"text":%q}}]}}`, string(content))
+	}))
+	defer srv.Close()
+
+	c := newTestBaiduClient(srv, config.BaiduConfig{ChunkConcurrency: 3, ChunkQPS: 100})
+	var chunks []pdfChunk
+	for i := 1; i <= 8; i++ {
+		chunks = append(chunks, pdfChunk{start: i, end: i, data: []byte(fmt.Sprintf("被告：张%d", i))})
+	}
+	start := time.Now()
+	pages, err := c.parseChunks(context.Background(), chunks, len(chunks), func(int, int, string) {})
+	if err != nil {
+		t.Fatalf("parseChunks: %v", err)
+	}
+	if maxInFlight != 3 {
+		t.Errorf("max in-flight = %d, want 3", maxInFlight)
+	}
+	// 8 次提交按 100 QPS 节流，首次立即提交，至少间隔 70ms
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("elapsed = %v, want submissions throttled", elapsed)
+	}
+	if len(pages) != len(chunks) {
+		t.Fatalf("pages = %d, want %d", len(pages), len(chunks))
+	}
+	for i, p := range pages {
+		if want := fmt.Sprintf("被告：张%d", i+1); p.Markdown != want {
+			t.Errorf("page %d = %q, want %q", i, p.Markdown, want)
+		}
+	}
+}
+
+// 配置的备用接口地址沿用同一 Token，主接口未识别出记录时依次尝试
+func TestBaiduClient_FallbackClients(t *testing.T) {
+	markdownServer := func(text string, calls *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(calls, 1)
+			if got := r.Header.Get("Authorization"); got != "token test-token" {
+				t.Errorf("Authorization = %q, want the primary token", got)
+			}
+			fmt.Fprintf(w, `{"error_code":0,"result":{"layoutParsingResults":[{"markdown":{"text":%q}}]}}`, text)
+		}))
+	}
+	var primaryCalls, backupCalls int32
+	primarySrv := markdownServer("（空白页）", &primaryCalls)
+	defer primarySrv.Close()
+	backupSrv := markdownServer("被告：张三，住址：北京市\n", &backupCalls)
+	defer backupSrv.Close()
+
+	primary := newTestBaiduClient(primarySrv, config.BaiduConfig{FallbackApiUrls: []string{" ", backupSrv.URL}})
+	backups := primary.fallbackClients()
+	if len(backups) != 1 || backups[0].Name() != "baidu-fallback-1" {
+		t.Fatalf("Expected 1 backup client named baidu-fallback-1, got %v", backups)
+	}
+
+	e := NewExtractor(nil)
+	e.SetOCRProvider(primary)
+	e.SetFallbackOCRProviders(backups...)
+	e.FallbackOnEmpty = true
+	records, err := e.ExtractData(readFixture(t, "scanned.pdf"), "scanned.pdf", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 || records[0]["defendant"] != "张三" {
+		t.Errorf("Expected the backup endpoint's record, got %v", records)
+	}
+	if primaryCalls != 1 || backupCalls != 1 {
+		t.Errorf("Expected one call to each endpoint, got %d primary and %d backup", primaryCalls, backupCalls)
+	}
+
+	// 备用引擎以各自的名称参与凭据校验，不与主引擎混淆
+	checks := e.TestProviders()
+	if len(checks) != 2 || checks[0].Provider != "baidu" || checks[1].Provider != "baidu-fallback-1" {
+		t.Fatalf("Expected the primary and the backup to be checked, got %+v", checks)
+	}
+	if primaryCalls != 2 || backupCalls != 2 {
+		t.Errorf("Expected one check per endpoint, got %d primary and %d backup calls", primaryCalls, backupCalls)
+	}
+}
+
+func TestBaiduClient_CheckCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{"missing file rejected after auth", http.StatusBadRequest, `{"errorMsg":"file is required"}`, false},
+		{"invalid token", http.StatusUnauthorized, "", true},
+		{"daily limit", http.StatusOK, `{"error_code":17,"error_msg":"Open api daily request limit reached"}`, true},
+		{"wrong endpoint", http.StatusNotFound, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if strings.Contains(string(body), `"file"`) {
+					t.Errorf("credential check must not upload a file, got %s", body)
+				}
+				if r.Header.Get("Authorization") != "token test-token" {
+					t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			err := newTestBaiduClient(srv, config.BaiduConfig{}).CheckCredentials()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCredentials() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
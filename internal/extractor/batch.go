@@ -0,0 +1,76 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DocumentResult 批量提取中单个源文件的结果，记录始终归属于其来源文件，便于回溯
+type DocumentResult struct {
+	FileName string   `json:"fileName"`
+	Records  []Record `json:"records"`
+	// OCRUsed 该文件是否经过识别 (云端引擎或桥接工具)，取自提取实际采用的路径，命中缓存时为当初提取时的路径
+	OCRUsed bool `json:"ocrUsed"`
+	// Warnings 需要人工核对的提示：记录中的 WarningsKey 去重汇总，以及未提取到记录等
+	Warnings []string `json:"warnings,omitempty"`
+	// Error 提取失败的原因，失败时 Records 为空，其余文件不受影响
+	Error string `json:"error,omitempty"`
+}
+
+// ExtractBatch 依次提取多个文件，按输入顺序返回每个文件的结果 (不合并为一个列表)，
+// 每条记录另以 SourceKey 标注来源文件。单个文件失败只记录在其 Error 中；ctx 取消时其余文件标记为已取消
+func (e *Extractor) ExtractBatch(ctx context.Context, docs []BatchDocument, fields []string, onProgress ProgressCallback) []DocumentResult {
+	results := make([]DocumentResult, 0, len(docs))
+	for i, doc := range docs {
+		res := DocumentResult{FileName: doc.FileName, Records: []Record{}}
+		if err := ctx.Err(); err != nil {
+			res.Error = fmt.Sprintf("已取消: %v", err)
+			results = append(results, res)
+			continue
+		}
+
+		docCtx, route := withRoute(ctx)
+		records, err := e.ExtractDataContext(docCtx, doc.Data, doc.FileName, fields, nil)
+		// 混合解析的路径为本地解析，但同样送了识别
+		res.OCRUsed = (route.path != "" && route.path != PlanNative) || route.ocrPages > 0
+		if err != nil {
+			e.logger.Warn("批量提取中的文件解析失败", "file", doc.FileName, "error", err)
+			res.Error = err.Error()
+		} else {
+			for _, r := range records {
+				tagged := make(Record, len(r)+1)
+				for k, v := range r {
+					tagged[k] = v
+				}
+				tagged[SourceKey] = doc.FileName
+				res.Records = append(res.Records, tagged)
+			}
+			res.Warnings = batchWarnings(records)
+		}
+		results = append(results, res)
+
+		if onProgress != nil {
+			onProgress(i+1, len(docs), fmt.Sprintf("已完成 %s 的提取", doc.FileName))
+		}
+	}
+	return results
+}
+
+// batchWarnings 汇总一个文件的各条记录中的提示 (按首次出现的顺序去重)，没有记录时提示人工检查
+func batchWarnings(records []Record) []string {
+	if len(records) == 0 {
+		return []string{"未提取到任何记录"}
+	}
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, r := range records {
+		for _, w := range strings.Split(r[WarningsKey], "；") {
+			if w = strings.TrimSpace(w); w != "" && !seen[w] {
+				seen[w] = true
+				warnings = append(warnings, w)
+			}
+		}
+	}
+	return warnings
+}
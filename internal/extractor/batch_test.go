@@ -0,0 +1,51 @@
+package extractor
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExtractBatch_GroupsBySourceFile(t *testing.T) {
+	e := NewExtractor(nil)
+	e.SetOCRProvider(NewMockOCRProvider())
+
+	docs := []BatchDocument{
+		{FileName: "two_cases.docx", Data: readFixture(t, "two_cases.docx")},
+		{FileName: "scanned.pdf", Data: readFixture(t, "scanned.pdf")},
+		{FileName: "notes.xlsx", Data: []byte("PK")},
+	}
+	want := map[string]int{"two_cases.docx": 2, "scanned.pdf": 0, "notes.xlsx": 0}
+	wantRecords, err := e.ExtractData(docs[1].Data, docs[1].FileName, nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData(scanned.pdf) returned error: %v", err)
+	}
+	want["scanned.pdf"] = len(wantRecords)
+
+	results := e.ExtractBatch(context.Background(), docs, nil, nil)
+	if len(results) != len(docs) {
+		t.Fatalf("Expected %d results, got %d", len(docs), len(results))
+	}
+	for i, res := range results {
+		if res.FileName != docs[i].FileName {
+			t.Errorf("result %d: expected %s, got %s", i, docs[i].FileName, res.FileName)
+		}
+		if len(res.Records) != want[res.FileName] {
+			t.Errorf("%s: expected %d records, got %d", res.FileName, want[res.FileName], len(res.Records))
+		}
+		for _, r := range res.Records {
+			if r[SourceKey] != res.FileName {
+				t.Errorf("%s: record tagged with source %q", res.FileName, r[SourceKey])
+			}
+		}
+	}
+
+	if results[0].OCRUsed || results[0].Error != "" {
+		t.Errorf("two_cases.docx: expected native extraction without error, got %+v", results[0])
+	}
+	if !results[1].OCRUsed || results[1].Error != "" || len(results[1].Records) == 0 {
+		t.Errorf("scanned.pdf: expected OCR records, got %+v", results[1])
+	}
+	if results[2].Error == "" || len(results[2].Warnings) != 0 {
+		t.Errorf("notes.xlsx: expected an error and no warnings, got %+v", results[2])
+	}
+}
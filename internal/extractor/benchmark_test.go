@@ -0,0 +1,44 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// benchCorpusCopies 合成语料中 complaint.pdf 的重复份数 (份数过多时 dslipak/pdf 会在合并文件上卡死)
+const benchCorpusCopies = 16
+
+// BenchmarkBatchExtractLocalPdf 比较不同并发数下本地解析多页 PDF 的吞吐量 (records/s)
+func BenchmarkBatchExtractLocalPdf(b *testing.B) {
+	corpus, pages := mergedFixture(b, "complaint.pdf", benchCorpusCopies)
+
+	for _, workers := range benchWorkers() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			e := NewExtractor(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			e.Workers = workers
+
+			records := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				out, err := e.batchExtractLocalPdf(context.Background(), corpus, ScanFieldKeys, pages, nil, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				records += len(out)
+			}
+			b.ReportMetric(float64(records)/b.Elapsed().Seconds(), "records/s")
+		})
+	}
+}
+
+// benchWorkers 返回去重排序后的并发数 1、4、NumCPU
+func benchWorkers() []int {
+	workers := []int{1, 4, runtime.NumCPU()}
+	slices.Sort(workers)
+	return slices.Compact(workers)
+}
@@ -0,0 +1,39 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// DefaultBridgeTimeout 本地桥接工具识别单页的默认超时时间
+const DefaultBridgeTimeout = 120 * time.Second
+
+// bridgeWaitDelay 终止进程组后等待输出管道关闭的最长时间，避免残留子进程占用管道导致永久阻塞
+const bridgeWaitDelay = 5 * time.Second
+
+// ErrBridgeTimeout 本地桥接工具超时未返回，已终止其进程组
+var ErrBridgeTimeout = errors.New("本地识别桥接工具超时")
+
+// runBridge 执行桥接工具并返回合并后的输出。超过 timeout (非正数时使用 DefaultBridgeTimeout) 或 ctx 取消时，
+// 终止整个进程组 (而非仅父进程)，以免其启动的识别子进程残留；超时返回包装了 ErrBridgeTimeout 的错误
+func runBridge(ctx context.Context, timeout time.Duration, name string, args ...string) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = DefaultBridgeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd) }
+	cmd.WaitDelay = bridgeWaitDelay
+
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, fmt.Errorf("%w (%s)，已终止进程: %s", ErrBridgeTimeout, timeout, name)
+	}
+	return output, err
+}
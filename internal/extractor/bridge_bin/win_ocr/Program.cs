@@ -11,6 +11,12 @@ class Program
 {
     static async Task<int> Main(string[] args)
     {
+        if (args.Length == 1 && args[0] == "--version")
+        {
+            Console.WriteLine(typeof(Program).Assembly.GetName().Version);
+            return 0;
+        }
+
         if (args.Length < 2)
         {
             Console.WriteLine("Usage: WinOcrBridge <pdfPath> <pageNumber>");
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package extractor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让桥接工具在独立的进程组中运行，便于整体终止
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup 向桥接工具所在进程组发送 SIGKILL
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,68 @@
+//go:build !windows
+
+package extractor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunBridge_TimeoutKillsProcessGroup(t *testing.T) {
+	// 桩命令在后台启动一个子进程后自身也长时间阻塞，模拟卡住的桥接工具
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	start := time.Now()
+	_, err := runBridge(context.Background(), 200*time.Millisecond, "sh", "-c", "sleep 30 & echo $! > "+pidFile+"; sleep 30")
+	if !errors.Is(err, ErrBridgeTimeout) {
+		t.Fatalf("Expected ErrBridgeTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("runBridge returned after %s, expected the timeout to fire", elapsed)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("read child pid: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("parse child pid: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("child process %d survived the timeout", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRunBridge_Output(t *testing.T) {
+	out, err := runBridge(context.Background(), time.Second, "sh", "-c", "echo 被告：张三")
+	if err != nil {
+		t.Fatalf("runBridge: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "被告：张三" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+// processAlive 判断进程是否仍在运行 (已退出但未回收的僵尸进程视为已终止)
+func processAlive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
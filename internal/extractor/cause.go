@@ -0,0 +1,75 @@
+package extractor
+
+import (
+	"sort"
+	"strings"
+
+	"legal-extractor/internal/editdist"
+)
+
+// CommonCaseCauses 常见民事案由 (依《民事案件案由规定》的名称)，提取到的案由按此归一化
+var CommonCaseCauses = []string{
+	"民间借贷纠纷",
+	"金融借款合同纠纷",
+	"小额借款合同纠纷",
+	"借款合同纠纷",
+	"信用卡纠纷",
+	"保证合同纠纷",
+	"追偿权纠纷",
+	"融资租赁合同纠纷",
+	"买卖合同纠纷",
+	"房屋租赁合同纠纷",
+	"物业服务合同纠纷",
+	"建设工程施工合同纠纷",
+	"承揽合同纠纷",
+	"服务合同纠纷",
+	"股权转让纠纷",
+	"劳动争议",
+	"离婚纠纷",
+	"继承纠纷",
+	"机动车交通事故责任纠纷",
+	"生命权、身体权、健康权纠纷",
+}
+
+// causeMaxDistance 案由与常见案由的最大编辑距离 (按字符，可不计 "纠纷" 后缀)，容忍错别字与识别误差
+const causeMaxDistance = 1
+
+// NormalizeCaseCause 将提取到的案由归一化为 CommonCaseCauses 中的名称：
+// 去除空白与 "一案" 等后缀后完全一致、包含某个常见案由 (取最长者)，或仅相差 causeMaxDistance 个字时返回该名称；
+// 无法对应时原样返回 (去除首尾空白)
+func NormalizeCaseCause(cause string) string {
+	cause = strings.TrimSpace(cause)
+	key := strings.Join(strings.Fields(cause), "")
+	key = strings.TrimSuffix(key, "一案")
+	if key == "" {
+		return cause
+	}
+	if c := findCaseCause(key); c != "" {
+		return c
+	}
+
+	best, bestDist := "", causeMaxDistance+1
+	core := strings.TrimSuffix(key, "纠纷")
+	for _, c := range CommonCaseCauses {
+		d := min(editdist.Distance(key, c), editdist.Distance(core, strings.TrimSuffix(c, "纠纷")))
+		if d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return cause
+}
+
+// findCaseCause 返回 text 中出现的最长的常见案由，没有时返回空串
+func findCaseCause(text string) string {
+	causes := append([]string(nil), CommonCaseCauses...)
+	sort.SliceStable(causes, func(i, j int) bool { return len(causes[i]) > len(causes[j]) })
+	for _, c := range causes {
+		if strings.Contains(text, c) {
+			return c
+		}
+	}
+	return ""
+}
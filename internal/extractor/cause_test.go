@@ -0,0 +1,40 @@
+package extractor
+
+import "testing"
+
+func TestExtractData_DOCX_CaseCauseAndAgentAuthority(t *testing.T) {
+	records, err := NewExtractor(nil).ExtractData(readFixture(t, "cause_authority.docx"), "cause_authority.docx", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	want := map[string]string{
+		"caseCause":      "民间借贷纠纷", // 原文 "民间借货纠纷" 归一化
+		"agentAuthority": "特别授权",
+		"plaintiffAgent": "钱二",
+		"defendant":      "孙三",
+	}
+	for k, v := range want {
+		if got := records[0][k]; got != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestNormalizeCaseCause(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"民间借贷纠纷", "民间借贷纠纷"},
+		{"民间借贷 一案", "民间借贷纠纷"},
+		{"信用卡 纠纷", "信用卡纠纷"},
+		{"原告诉被告金融借款合同纠纷", "金融借款合同纠纷"},
+		{"房屋租货合同纠纷", "房屋租赁合同纠纷"},
+		{"名誉权纠纷", "名誉权纠纷"}, // 不在常见案由中，原样保留
+	}
+	for _, tt := range tests {
+		if got := NormalizeCaseCause(tt.in); got != tt.want {
+			t.Errorf("NormalizeCaseCause(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
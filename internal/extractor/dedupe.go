@@ -0,0 +1,116 @@
+package extractor
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"legal-extractor/internal/config"
+	"legal-extractor/internal/editdist"
+)
+
+// SourceKey 记录所属的源文件名 (元数据)，由 ExtractBatch 写入
+const SourceKey = "_source"
+
+// DuplicatesKey 保留记录上被合并掉的重复记录来源 (文件名或页码)，多个以 RepeatSeparator 连接
+const DuplicatesKey = "_duplicates"
+
+// DedupeOptions 跨文件模糊去重参数，用于合并同一案件的重复扫描件
+type DedupeOptions struct {
+	// NameDistance 缺少身份证号码时，被告姓名的编辑距离 (按字符) 不超过该值即视为同一案件，
+	// 用于容忍识别误差；0 表示姓名须完全一致。短姓名每 3 个字至多容忍 1 处差异 (见 nameDistanceLimit)，
+	// 避免 "张三" 与 "李三" 这样的不同当事人被合并
+	NameDistance int
+}
+
+// DedupeOptionsFromConfig 返回 conf.yaml 中配置的去重参数，未开启 extraction.dedupe 时返回 nil
+func DedupeOptionsFromConfig() *DedupeOptions {
+	cfg := config.Get().Extraction
+	if !cfg.Dedupe {
+		return nil
+	}
+	return &DedupeOptions{NameDistance: cfg.DedupeNameDistance}
+}
+
+// MergeBatch 按输入顺序合并 ExtractBatch 的结果 (记录已以 SourceKey 标注来源文件)，提取失败的文件被跳过。
+// dedupe 非 nil 时再按 DedupeRecords 合并重复案件
+func MergeBatch(results []DocumentResult, dedupe *DedupeOptions) []Record {
+	merged := []Record{}
+	for _, res := range results {
+		if res.Error != "" {
+			continue
+		}
+		merged = append(merged, res.Records...)
+	}
+	if dedupe != nil {
+		merged = DedupeRecords(merged, *dedupe)
+	}
+	return merged
+}
+
+// DedupeRecords 合并重复的案件记录，保留首次出现的一条，并在其 DuplicatesKey 中记下被合并记录的来源。
+// 双方都有身份证号码 (企业为统一社会信用代码) 时按规范化后的号码精确比较；否则比较被告姓名，编辑距离不超过 opts.NameDistance 即为重复。
+// 双方都有金额数值且不一致时视为不同案件 (同一被告被多次起诉)
+func DedupeRecords(records []Record, opts DedupeOptions) []Record {
+	var kept []Record
+	for _, r := range records {
+		dup := -1
+		for i, k := range kept {
+			if isDuplicate(k, r, opts) {
+				dup = i
+				break
+			}
+		}
+		if dup < 0 {
+			kept = append(kept, r)
+			continue
+		}
+
+		k := make(Record, len(kept[dup])+1)
+		for key, v := range kept[dup] {
+			k[key] = v
+		}
+		source := r[SourceKey]
+		if source == "" {
+			source = r["page"]
+		}
+		if source != "" {
+			if k[DuplicatesKey] != "" {
+				source = k[DuplicatesKey] + RepeatSeparator + source
+			}
+			k[DuplicatesKey] = source
+		}
+		kept[dup] = k
+	}
+	return kept
+}
+
+// isDuplicate 判断两条记录是否为同一案件
+func isDuplicate(a, b Record, opts DedupeOptions) bool {
+	if av, bv := a["amountValue"], b["amountValue"]; av != "" && bv != "" && av != bv {
+		return false
+	}
+	if aid, bid := normalizeDedupeKey(a["idNumber"]), normalizeDedupeKey(b["idNumber"]); aid != "" && bid != "" {
+		return strings.EqualFold(aid, bid)
+	}
+	// 企业被告以统一社会信用代码代替身份证号码
+	if ac, bc := normalizeDedupeKey(a["creditCode"]), normalizeDedupeKey(b["creditCode"]); ac != "" && bc != "" {
+		return strings.EqualFold(ac, bc)
+	}
+	an, bn := normalizeDedupeKey(a["defendant"]), normalizeDedupeKey(b["defendant"])
+	if an == "" || bn == "" {
+		return false
+	}
+	return editdist.Distance(an, bn) <= nameDistanceLimit(an, bn, opts.NameDistance)
+}
+
+// nameDistanceLimit 按较短姓名的长度收紧允许的编辑距离：每 3 个字至多 1 处差异，且不超过 limit。
+// 两个字的姓名 (如 "张三" 与 "李三") 须完全一致，三个字的姓名可容忍 1 处识别误差
+func nameDistanceLimit(a, b string, limit int) int {
+	n := min(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	return min(limit, n/3)
+}
+
+// normalizeDedupeKey 去除空白，消除识别结果中的断行、空格差异
+func normalizeDedupeKey(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
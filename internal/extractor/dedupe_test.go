@@ -0,0 +1,81 @@
+package extractor
+
+import "testing"
+
+func TestDedupeRecords_NameOCRVariance(t *testing.T) {
+	// 同一份起诉状扫描两次，第二次把"张三丰"识别成了"张三半"，且都没有识别出身份证号码
+	records := []Record{
+		{"defendant": "张三丰", "request": "偿还借款", SourceKey: "scan_a.pdf"},
+		{"defendant": "张三半", "request": "偿还借款", SourceKey: "scan_b.pdf"},
+		{"defendant": "李四", "request": "支付货款", SourceKey: "scan_b.pdf"},
+	}
+	got := DedupeRecords(records, DedupeOptions{NameDistance: 1})
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 records after dedupe, got %d: %v", len(got), got)
+	}
+	if got[0]["defendant"] != "张三丰" || got[0][DuplicatesKey] != "scan_b.pdf" {
+		t.Errorf("Expected the first record kept with scan_b.pdf as duplicate, got %v", got[0])
+	}
+	if records[0][DuplicatesKey] != "" {
+		t.Error("DedupeRecords modified its input")
+	}
+
+	if got := DedupeRecords(records, DedupeOptions{}); len(got) != 3 {
+		t.Errorf("Expected exact name matching with NameDistance 0, got %d records", len(got))
+	}
+}
+
+func TestDedupeRecords_IDNumber(t *testing.T) {
+	records := []Record{
+		{"defendant": "王五", "idNumber": "11010119900307663x"},
+		{"defendant": "王 吾", "idNumber": "110101 19900307663X"}, // 姓名识别差异较大，但号码一致
+		{"defendant": "王五", "idNumber": "110101199003071234"},   // 同名不同人
+		{"defendant": "赵六", "amountValue": "5000"},
+		{"defendant": "赵六", "amountValue": "8000"}, // 同一被告的另一起案件
+	}
+	got := DedupeRecords(records, DedupeOptions{NameDistance: 1})
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 records, got %d: %v", len(got), got)
+	}
+}
+
+func TestDedupeRecords_CreditCode(t *testing.T) {
+	records := []Record{
+		{"defendant": "北京某某商贸有限公司", "creditCode": "91110105MA00XYZ12K"},
+		{"defendant": "北京某某商货有限公司", "creditCode": "91110105ma00xyz12k"}, // 识别差异，代码一致
+		{"defendant": "北京某某商贸有限公司", "creditCode": "91110105MA00XYZ99Q"}, // 同名的另一家企业
+	}
+	got := DedupeRecords(records, DedupeOptions{NameDistance: 1})
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 records, got %d: %v", len(got), got)
+	}
+}
+
+func TestMergeBatch(t *testing.T) {
+	results := []DocumentResult{
+		{FileName: "a.pdf", Records: []Record{{"defendant": "张三半", SourceKey: "a.pdf"}}},
+		{FileName: "b.pdf", Records: []Record{{"defendant": "张三丰", SourceKey: "b.pdf"}}},
+		{FileName: "c.pdf", Records: []Record{}, Error: "识别失败"},
+	}
+	all := MergeBatch(results, nil)
+	if len(all) != 2 || all[0][SourceKey] != "a.pdf" || all[1][SourceKey] != "b.pdf" {
+		t.Fatalf("Expected records in input order tagged with their source, got %v", all)
+	}
+	deduped := MergeBatch(results, &DedupeOptions{NameDistance: 1})
+	if len(deduped) != 1 || deduped[0][DuplicatesKey] != "b.pdf" {
+		t.Errorf("Expected b.pdf collapsed into a.pdf, got %v", deduped)
+	}
+}
+
+// 两个字的姓名只差一个字多半是不同当事人，不按默认的编辑距离合并
+func TestDedupeRecords_ShortNames(t *testing.T) {
+	records := []Record{
+		{"defendant": "张三", SourceKey: "a.pdf"},
+		{"defendant": "李三", SourceKey: "b.pdf"},
+		{"defendant": "张 三", SourceKey: "c.pdf"},
+	}
+	got := DedupeRecords(records, DedupeOptions{NameDistance: 1})
+	if len(got) != 2 || got[0][DuplicatesKey] != "c.pdf" {
+		t.Errorf("Expected only the identical short name merged, got %v", got)
+	}
+}
@@ -0,0 +1,161 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+// DeskewKey 识别前本地纠偏时校正的倾斜角度 (度，保留一位小数)，未纠偏时不设置 (元数据)
+const DeskewKey = "_deskew"
+
+const (
+	// deskewMaxAngle 检测的最大倾斜角度 (度)，手工扫描的倾斜一般在此范围内；更大的旋转交由识别引擎的方向校正处理
+	deskewMaxAngle = 10.0
+	// deskewStep 检测角度的步长 (度)
+	deskewStep = 0.25
+	// deskewMinAngle 倾斜小于该角度 (度) 时不做校正，避免无谓的重新编码
+	deskewMinAngle = 0.5
+	// deskewSampleSize 检测角度前将图片缩小到的最长边像素数，以控制大图的耗时
+	deskewSampleSize = 800
+	// deskewJPEGQuality 校正后重新编码 JPEG 的质量
+	deskewJPEGQuality = 90
+)
+
+// ocrImage 将单张图片交由识别引擎处理；开启 Deskew 时先在本地纠偏，并在记录上以 DeskewKey 标注校正角度
+func (e *Extractor) ocrImage(ctx context.Context, data []byte, onProgress ProgressCallback) ([]Record, error) {
+	var angle float64
+	if e.Deskew {
+		corrected, a, err := deskewImage(data)
+		switch {
+		case err != nil:
+			e.logger.Warn("图片纠偏失败，使用原图识别", "error", err)
+		case a != 0:
+			e.logger.Info("已校正图片倾斜", "angle", a)
+			data, angle = corrected, a
+		}
+	}
+
+	records, err := e.ocrWithFallback(ctx, data, false, 1, onProgress)
+	if err != nil || angle == 0 {
+		return records, err
+	}
+	for _, r := range records {
+		r[DeskewKey] = strconv.FormatFloat(angle, 'f', 1, 64)
+	}
+	return records, nil
+}
+
+// deskewImage 检测图片中文字行的倾斜角度并反向旋转校正，返回按原格式 (JPEG 或 PNG) 编码的灰度图片与校正的角度 (度，顺时针为正)。
+// 倾斜小于 deskewMinAngle 时原样返回，角度为 0；重新编码后超出识别接口的大小上限且比原图更大时也放弃校正，原样返回
+func deskewImage(data []byte) ([]byte, float64, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	angle := estimateSkew(img)
+	if math.Abs(angle) < deskewMinAngle {
+		return data, 0, nil
+	}
+
+	var buf bytes.Buffer
+	rotated := rotateImage(img, angle)
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, rotated, &jpeg.Options{Quality: deskewJPEGQuality})
+	} else {
+		err = png.Encode(&buf, rotated)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if buf.Len() > max(len(data), baiduMaxFileSize) {
+		return data, 0, nil
+	}
+	return buf.Bytes(), angle, nil
+}
+
+// estimateSkew 用投影法估计倾斜角度：文字行与水平方向平行时，各行深色像素的水平投影最为集中 (平方和最大)。
+// 返回文字行相对水平方向的角度 (度)，图像坐标 y 轴向下，行向右下倾斜时为正
+func estimateSkew(img image.Image) float64 {
+	small := downscaleGray(img, deskewSampleSize)
+	b := small.Bounds()
+
+	type point struct{ x, y float64 }
+	var points []point
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if small.GrayAt(x, y).Y < 128 {
+				points = append(points, point{float64(x), float64(y)})
+			}
+		}
+	}
+	if len(points) < 50 {
+		return 0
+	}
+
+	bestAngle, bestScore := 0.0, -1.0
+	bins := make(map[int]float64)
+	for a := -deskewMaxAngle; a <= deskewMaxAngle+1e-9; a += deskewStep {
+		sin, cos := math.Sincos(a * math.Pi / 180)
+		clear(bins)
+		for _, p := range points {
+			bins[int(math.Floor(p.y*cos-p.x*sin))]++
+		}
+		score := 0.0
+		for _, n := range bins {
+			score += n * n
+		}
+		// 得分相同时取绝对值较小的角度
+		if score > bestScore || (score == bestScore && math.Abs(a) < math.Abs(bestAngle)) {
+			bestAngle, bestScore = a, score
+		}
+	}
+	return bestAngle
+}
+
+// downscaleGray 将图片转为灰度并按整数倍缩小到最长边不超过 maxSide 像素，每个像素取对应区域中最深的灰度，
+// 使细笔画在缩小后仍能保留
+func downscaleGray(img image.Image, maxSide int) *image.Gray {
+	b := img.Bounds()
+	f := max(1, (max(b.Dx(), b.Dy())+maxSide-1)/maxSide)
+	out := image.NewGray(image.Rect(0, 0, (b.Dx()+f-1)/f, (b.Dy()+f-1)/f))
+	for y := 0; y < out.Rect.Dy(); y++ {
+		for x := 0; x < out.Rect.Dx(); x++ {
+			darkest := uint8(255)
+			for sy := b.Min.Y + y*f; sy < min(b.Min.Y+(y+1)*f, b.Max.Y); sy++ {
+				for sx := b.Min.X + x*f; sx < min(b.Min.X+(x+1)*f, b.Max.X); sx++ {
+					darkest = min(darkest, color.GrayModel.Convert(img.At(sx, sy)).(color.Gray).Y)
+				}
+			}
+			out.SetGray(x, y, color.Gray{Y: darkest})
+		}
+	}
+	return out
+}
+
+// rotateImage 以图片中心将图片旋转 -angle 度 (抵消倾斜)，尺寸不变，超出原图的区域填充白色
+func rotateImage(img image.Image, angle float64) *image.Gray {
+	b := img.Bounds()
+	out := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	cx, cy := float64(b.Dx())/2, float64(b.Dy())/2
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			// 目标像素对应的原图位置
+			dx, dy := float64(x)-cx, float64(y)-cy
+			sx := int(math.Round(dx*cos - dy*sin + cx))
+			sy := int(math.Round(dx*sin + dy*cos + cy))
+			if sx < 0 || sy < 0 || sx >= b.Dx() || sy >= b.Dy() {
+				out.SetGray(x, y, color.Gray{Y: 255})
+				continue
+			}
+			out.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return out
+}
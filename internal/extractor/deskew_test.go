@@ -0,0 +1,119 @@
+package extractor
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// skewedImage 生成模拟文字行 (4 像素高的水平黑条) 的图片，尺寸为 400x300 的 scale 倍，并整体倾斜 angle 度
+func skewedImage(angle float64, scale int) image.Image {
+	page := image.NewGray(image.Rect(0, 0, 400*scale, 300*scale))
+	for y := 0; y < 300*scale; y++ {
+		for x := 0; x < 400*scale; x++ {
+			c := color.Gray{Y: 255}
+			if x >= 40*scale && x < 360*scale && y >= 40*scale && y < 260*scale && y%(20*scale) < 4 {
+				c = color.Gray{Y: 0}
+			}
+			page.SetGray(x, y, c)
+		}
+	}
+	return rotateImage(page, -angle)
+}
+
+// skewedPage 生成倾斜 angle 度的 PNG 页面
+func skewedPage(t *testing.T, angle float64) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, skewedImage(angle, 1)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// captureOCR 记录收到的图片，返回固定记录
+type captureOCR struct {
+	data []byte
+}
+
+func (c *captureOCR) Name() string { return "capture" }
+
+func (c *captureOCR) ParseDocument(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]Record, error) {
+	c.data = fileData
+	return []Record{{"defendant": "张三"}}, nil
+}
+
+func TestDeskewImage(t *testing.T) {
+	out, angle, err := deskewImage(skewedPage(t, 3))
+	if err != nil {
+		t.Fatalf("deskewImage: %v", err)
+	}
+	if math.Abs(angle-3) > deskewStep {
+		t.Errorf("angle = %v, want about 3", angle)
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("decode corrected image: %v", err)
+	}
+	if residual := estimateSkew(img); math.Abs(residual) >= deskewMinAngle {
+		t.Errorf("residual skew after correction = %v", residual)
+	}
+
+	// 端正的图片原样返回
+	straight := skewedPage(t, 0)
+	if out, angle, err := deskewImage(straight); err != nil || angle != 0 || !bytes.Equal(out, straight) {
+		t.Errorf("straight page: angle %v, err %v, changed %v", angle, err, !bytes.Equal(out, straight))
+	}
+
+	// JPEG 按原格式重新编码；大图缩小后检测，细于缩小倍数的笔画不丢失
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, skewedImage(2, 5), &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	out, angle, err = deskewImage(buf.Bytes())
+	if err != nil {
+		t.Fatalf("deskewImage (jpeg): %v", err)
+	}
+	if math.Abs(angle-2) > deskewStep {
+		t.Errorf("jpeg angle = %v, want about 2", angle)
+	}
+	if _, format, err := image.DecodeConfig(bytes.NewReader(out)); err != nil || format != "jpeg" {
+		t.Errorf("corrected image format = %q (%v), want jpeg", format, err)
+	}
+}
+
+func TestExtractImage_Deskew(t *testing.T) {
+	data := skewedPage(t, -4)
+
+	ocr := &captureOCR{}
+	e := NewExtractor(nil)
+	e.SetOCRProvider(ocr)
+	records, err := e.ExtractImage(data, allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractImage: %v", err)
+	}
+	if !bytes.Equal(ocr.data, data) || records[0][DeskewKey] != "" {
+		t.Errorf("deskew applied while disabled: %v", records[0])
+	}
+
+	e.Deskew = true
+	records, err = e.ExtractImage(data, allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractImage: %v", err)
+	}
+	if bytes.Equal(ocr.data, data) {
+		t.Fatal("OCR received the original image, want the deskewed one")
+	}
+	if got := records[0][DeskewKey]; got != "-4.0" {
+		t.Errorf("%s = %q, want -4.0", DeskewKey, got)
+	}
+
+	// 无法解码的图片照常识别
+	if _, err := e.ExtractImage([]byte("not an image"), allFields, nil); err != nil {
+		t.Errorf("ExtractImage with undecodable data: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package extractor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatchStrategy 比对两组提取结果时配对记录的方式
+type MatchStrategy string
+
+const (
+	// MatchByID 按身份证号码配对，缺少号码的记录按顺序与剩余记录配对 (默认)
+	MatchByID MatchStrategy = "id"
+	// MatchByOrder 按记录顺序逐条配对
+	MatchByOrder MatchStrategy = "order"
+)
+
+// 差异类型
+const (
+	DiffChanged = "changed" // 字段值不同
+	DiffMissing = "missing" // 基准中的记录在实际结果中不存在
+	DiffExtra   = "extra"   // 实际结果多出的记录
+)
+
+// FieldDiff 一处字段差异。记录整体缺失或多出时 Field 为空
+type FieldDiff struct {
+	Index       int    `json:"index"`       // 基准记录的序号，多出的记录为 -1
+	ActualIndex int    `json:"actualIndex"` // 实际记录的序号，缺失的记录为 -1
+	Kind        string `json:"kind"`
+	Field       string `json:"field,omitempty"`
+	Expected    string `json:"expected"`
+	Actual      string `json:"actual"`
+}
+
+// ParseMatchStrategy 解析配对方式，空字符串为 MatchByID
+func ParseMatchStrategy(s string) (MatchStrategy, error) {
+	switch MatchStrategy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", MatchByID:
+		return MatchByID, nil
+	case MatchByOrder:
+		return MatchByOrder, nil
+	default:
+		return "", fmt.Errorf("不支持的配对方式: %s，可选 id、order", s)
+	}
+}
+
+// DiffRecords 以 expected (如人工核对过的 CSV) 为基准比对 actual，逐字段报告差异。
+// 只比对基准记录中存在的字段 (页码与 "_" 开头的元数据除外)，基准缺少的列不做检查；
+// 值比较前去除首尾空白
+func DiffRecords(expected, actual []Record, match MatchStrategy) []FieldDiff {
+	pairs := pairRecords(expected, actual, match)
+
+	var diffs []FieldDiff
+	matched := make(map[int]bool, len(actual))
+	for i, exp := range expected {
+		j, ok := pairs[i]
+		if !ok {
+			diffs = append(diffs, FieldDiff{
+				Index: i, ActualIndex: -1, Kind: DiffMissing,
+				Expected: recordLabel(exp),
+			})
+			continue
+		}
+		matched[j] = true
+		act := actual[j]
+		for _, field := range diffFields(exp) {
+			e, a := strings.TrimSpace(exp[field]), strings.TrimSpace(act[field])
+			if e != a {
+				diffs = append(diffs, FieldDiff{
+					Index: i, ActualIndex: j, Kind: DiffChanged,
+					Field: field, Expected: e, Actual: a,
+				})
+			}
+		}
+	}
+	for j, act := range actual {
+		if !matched[j] {
+			diffs = append(diffs, FieldDiff{
+				Index: -1, ActualIndex: j, Kind: DiffExtra,
+				Actual: recordLabel(act),
+			})
+		}
+	}
+	return diffs
+}
+
+// pairRecords 返回基准记录序号到实际记录序号的映射
+func pairRecords(expected, actual []Record, match MatchStrategy) map[int]int {
+	pairs := make(map[int]int)
+	used := make(map[int]bool)
+
+	if match != MatchByOrder {
+		byID := make(map[string]int)
+		for j, r := range actual {
+			if id := normalizeID(r["idNumber"]); id != "" {
+				if _, dup := byID[id]; !dup {
+					byID[id] = j
+				}
+			}
+		}
+		for i, r := range expected {
+			if j, ok := byID[normalizeID(r["idNumber"])]; ok && !used[j] {
+				pairs[i] = j
+				used[j] = true
+			}
+		}
+	}
+
+	// 剩余记录按顺序配对
+	next := 0
+	for i := range expected {
+		if _, ok := pairs[i]; ok {
+			continue
+		}
+		for next < len(actual) && used[next] {
+			next++
+		}
+		if next >= len(actual) {
+			break
+		}
+		// 双方都有号码却不一致，说明不是同一当事人，不强行配对
+		if match != MatchByOrder && normalizeID(expected[i]["idNumber"]) != "" && normalizeID(actual[next]["idNumber"]) != "" {
+			continue
+		}
+		pairs[i] = next
+		used[next] = true
+	}
+	return pairs
+}
+
+// diffFields 返回参与比对的字段，按导出列顺序排列
+func diffFields(r Record) []string {
+	rank := make(map[string]int, len(exportFieldOrder))
+	for i, k := range exportFieldOrder {
+		rank[k] = i
+	}
+
+	var fields []string
+	for k := range r {
+		if k == "page" || strings.HasPrefix(k, "_") {
+			continue
+		}
+		fields = append(fields, k)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		ri, iok := rank[fields[i]]
+		rj, jok := rank[fields[j]]
+		if iok != jok {
+			return iok
+		}
+		if iok && ri != rj {
+			return ri < rj
+		}
+		return fields[i] < fields[j]
+	})
+	return fields
+}
+
+func normalizeID(id string) string {
+	return strings.ToUpper(strings.TrimSpace(id))
+}
+
+// recordLabel 用于在缺失、多出的差异中标识记录
+func recordLabel(r Record) string {
+	label := strings.TrimSpace(r["defendant"])
+	if id := strings.TrimSpace(r["idNumber"]); id != "" {
+		label = strings.TrimSpace(label + " " + id)
+	}
+	return label
+}
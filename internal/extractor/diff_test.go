@@ -0,0 +1,39 @@
+package extractor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffRecords(t *testing.T) {
+	expected := []Record{
+		{"defendant": "张三", "idNumber": "110101199001011234", "amount": "10000元"},
+		{"defendant": "李四", "idNumber": "110101198505052345", "amount": "5000元"},
+	}
+	// 顺序颠倒且李四的金额识别有误
+	actual := []Record{
+		{"page": "2", "defendant": "李四", "idNumber": "110101198505052345", "amount": "500元", "_confidence": "0.8"},
+		{"page": "1", "defendant": "张三 ", "idNumber": "110101199001011234", "amount": "10000元"},
+	}
+
+	got := DiffRecords(expected, actual, MatchByID)
+	want := []FieldDiff{{Index: 1, ActualIndex: 0, Kind: DiffChanged, Field: "amount", Expected: "5000元", Actual: "500元"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchByID: got %+v, want %+v", got, want)
+	}
+
+	// 按顺序配对时两条记录错位，每个字段都不同
+	if got := DiffRecords(expected, actual, MatchByOrder); len(got) != 6 {
+		t.Errorf("MatchByOrder: expected 6 diffs, got %+v", got)
+	}
+
+	// 缺失与多出的记录
+	got = DiffRecords(expected[:1], actual[:1], MatchByID)
+	if len(got) != 2 || got[0].Kind != DiffMissing || got[1].Kind != DiffExtra {
+		t.Errorf("Expected one missing and one extra record, got %+v", got)
+	}
+
+	if _, err := ParseMatchStrategy("fuzzy"); err == nil {
+		t.Error("Expected error for unknown match strategy")
+	}
+}
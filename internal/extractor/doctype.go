@@ -0,0 +1,59 @@
+package extractor
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DocTypeKey 记录所属文书的类型 (元数据)，取值见 DocTypeComplaint 等；无法判断时不设置
+const DocTypeKey = "_docType"
+
+// 文书类型，即 DocTypeKey 的取值
+const (
+	DocTypeComplaint   = "起诉状"
+	DocTypeArbitration = "仲裁申请书"
+	DocTypeJudgment    = "判决书"
+	DocTypeRuling      = "裁定书"
+)
+
+// DocTypes 返回全部文书类型
+func DocTypes() []string {
+	return []string{DocTypeComplaint, DocTypeArbitration, DocTypeJudgment, DocTypeRuling}
+}
+
+// docTypeTitle 文书标题，允许 OCR 在字间插入空白
+var docTypeTitle = regexp.MustCompile(`起\s*诉\s*[状书]|仲\s*裁\s*申\s*请\s*书|判\s*决\s*书|裁\s*定\s*书`)
+
+// detectDocType 按文本中最先出现的文书标题判断类型；标题通常位于开头，正文中引用的其他文书不影响结果
+func detectDocType(text string) string {
+	title := docTypeTitle.FindString(text)
+	if title == "" {
+		return ""
+	}
+	title = strings.Join(strings.Fields(title), "")
+	switch {
+	case strings.HasPrefix(title, "起诉"):
+		return DocTypeComplaint
+	case title == DocTypeArbitration:
+		return DocTypeArbitration
+	case title == DocTypeJudgment:
+		return DocTypeJudgment
+	default:
+		return DocTypeRuling
+	}
+}
+
+// FilterDocType 返回 DocTypeKey 属于 types 的记录；types 为空时原样返回
+func FilterDocType(records []Record, types ...string) []Record {
+	if len(types) == 0 {
+		return records
+	}
+	var kept []Record
+	for _, r := range records {
+		if slices.Contains(types, r[DocTypeKey]) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,67 @@
+package extractor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilterDocType_MixedBatch(t *testing.T) {
+	e := NewExtractor(nil)
+	docs := []struct{ name, text string }{
+		{"a_complaint.txt", "民事起诉状\n原告：某银行股份有限公司\n被告：张三，男，1990年1月1日出生\n诉讼请求：\n请求判令被告偿还借款10000元。"},
+		{"b_arbitration.txt", "仲裁申请书\n申请人：某银行股份有限公司\n被申请人：李四，女，1985年3月2日出生\n仲裁请求：\n请求裁决被申请人偿还借款5000元。"},
+		{"c_judgment.txt", "北京市朝阳区人民法院\n民事判决书\n（2023）京0105民初123号\n原告：某银行股份有限公司\n被告：王五，男，1980年6月1日出生\n本院认为，依照民事起诉状所述事实……"},
+	}
+	var results []DocumentResult
+	for _, doc := range docs {
+		records, err := e.ExtractText(doc.text, allFields)
+		if err != nil {
+			t.Fatalf("%s: ExtractText returned error: %v", doc.name, err)
+		}
+		for _, r := range records {
+			r[SourceKey] = doc.name
+		}
+		results = append(results, DocumentResult{FileName: doc.name, Records: records})
+	}
+	merged := MergeBatch(results, nil)
+
+	wantTypes := []string{DocTypeComplaint, DocTypeArbitration, DocTypeJudgment}
+	if len(merged) != len(wantTypes) {
+		t.Fatalf("merged = %d records, want %d: %v", len(merged), len(wantTypes), merged)
+	}
+	for i, want := range wantTypes {
+		if merged[i][DocTypeKey] != want {
+			t.Errorf("%s: docType = %q, want %q", merged[i][SourceKey], merged[i][DocTypeKey], want)
+		}
+	}
+
+	complaints := FilterDocType(merged, DocTypeComplaint)
+	if len(complaints) != 1 || complaints[0]["defendant"] != "张三" {
+		t.Errorf("FilterDocType(起诉状) = %v, want only 张三", complaints)
+	}
+	if got := FilterDocType(merged, DocTypeComplaint, DocTypeArbitration); len(got) != 2 {
+		t.Errorf("FilterDocType(起诉状, 仲裁申请书) = %d records, want 2", len(got))
+	}
+	if got := FilterDocType(merged); len(got) != len(merged) {
+		t.Errorf("FilterDocType() = %d records, want all %d", len(got), len(merged))
+	}
+
+	// 导出时按 DocTypes 筛选，长格式带文书类型行
+	path := filepath.Join(t.TempDir(), "complaints.csv")
+	opts := DefaultExportOptions()
+	opts.Long = true
+	opts.DocTypes = []string{DocTypeComplaint}
+	if err := ExportCSVWithOptions(path, merged, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions: %v", err)
+	}
+	rows := readCSVRows(t, path)
+	if len(rows) < 2 || strings.Join(rows[1], "|") != "1|docType|文书类型|起诉状" {
+		t.Fatalf("first data row = %v, want the docType row", rows)
+	}
+	for _, row := range rows[1:] {
+		if row[0] != "1" || row[3] == "王五" || row[3] == "李四" {
+			t.Errorf("unexpected row from another document: %v", row)
+		}
+	}
+}
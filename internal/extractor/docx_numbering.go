@@ -0,0 +1,161 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// docxNumbering 解析自 word/numbering.xml 的自动编号定义，用于还原屏幕上可见的 "一、" "1." 等序号。
+// Word 的自动编号不写入 <w:t>，若不还原，诉讼请求的各条目会在 smartMerge 中被合并成一行。
+type docxNumbering struct {
+	levels   map[string]map[int]numberingLevel // numId -> ilvl -> 级别定义
+	counters map[string][]int                  // numId -> 各级当前计数
+}
+
+// numberingLevel 单个编号级别的格式定义
+type numberingLevel struct {
+	Format string // numFmt，如 decimal、chineseCounting、bullet
+	Text   string // lvlText，如 "%1."、"%1、"、"（%1）"
+	Start  int
+}
+
+// numberingXML 仅映射还原序号所需的节点
+type numberingXML struct {
+	Abstracts []struct {
+		ID     string `xml:"abstractNumId,attr"`
+		Levels []struct {
+			Ilvl   int    `xml:"ilvl,attr"`
+			Start  xmlVal `xml:"start"`
+			NumFmt xmlVal `xml:"numFmt"`
+			Text   xmlVal `xml:"lvlText"`
+		} `xml:"lvl"`
+	} `xml:"abstractNum"`
+	Nums []struct {
+		ID       string `xml:"numId,attr"`
+		Abstract xmlVal `xml:"abstractNumId"`
+	} `xml:"num"`
+}
+
+type xmlVal struct {
+	Val string `xml:"val,attr"`
+}
+
+// loadDocxNumbering 读取 word/numbering.xml，文档未使用自动编号时返回 nil
+func loadDocxNumbering(r *zip.Reader) *docxNumbering {
+	for _, f := range r.File {
+		if f.Name != "word/numbering.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil
+		}
+		defer rc.Close()
+
+		var doc numberingXML
+		if err := xml.NewDecoder(rc).Decode(&doc); err != nil {
+			return nil
+		}
+
+		abstracts := make(map[string]map[int]numberingLevel)
+		for _, a := range doc.Abstracts {
+			levels := make(map[int]numberingLevel)
+			for _, l := range a.Levels {
+				start, err := strconv.Atoi(l.Start.Val)
+				if err != nil {
+					start = 1
+				}
+				levels[l.Ilvl] = numberingLevel{Format: l.NumFmt.Val, Text: l.Text.Val, Start: start}
+			}
+			abstracts[a.ID] = levels
+		}
+
+		n := &docxNumbering{
+			levels:   make(map[string]map[int]numberingLevel),
+			counters: make(map[string][]int),
+		}
+		for _, num := range doc.Nums {
+			if levels, ok := abstracts[num.Abstract.Val]; ok {
+				n.levels[num.ID] = levels
+			}
+		}
+		return n
+	}
+	return nil
+}
+
+// next 推进指定列表的计数并返回该段落的可见序号；项目符号或未知编号返回空串
+func (n *docxNumbering) next(numID string, ilvl int) string {
+	levels, ok := n.levels[numID]
+	if !ok || ilvl < 0 || ilvl > 8 {
+		return ""
+	}
+	lvl, ok := levels[ilvl]
+	if !ok || lvl.Format == "bullet" || lvl.Format == "none" {
+		return ""
+	}
+
+	counters := n.counters[numID]
+	if counters == nil {
+		counters = make([]int, 9)
+		n.counters[numID] = counters
+	}
+	if counters[ilvl] == 0 {
+		counters[ilvl] = lvl.Start
+	} else {
+		counters[ilvl]++
+	}
+	// 上级条目推进后，下级编号重新开始
+	for i := ilvl + 1; i < len(counters); i++ {
+		counters[i] = 0
+	}
+
+	marker := lvl.Text
+	for i := 0; i <= ilvl; i++ {
+		placeholder := "%" + strconv.Itoa(i+1)
+		if !strings.Contains(marker, placeholder) {
+			continue
+		}
+		value := counters[i]
+		if value == 0 {
+			value = levels[i].Start
+		}
+		marker = strings.ReplaceAll(marker, placeholder, formatListNumber(value, levels[i].Format))
+	}
+	return marker
+}
+
+// formatListNumber 按 numFmt 格式化序号，未支持的格式回退为阿拉伯数字
+func formatListNumber(n int, format string) string {
+	switch format {
+	case "chineseCounting", "chineseCountingThousand":
+		if s := chineseNumeral(n); s != "" {
+			return s
+		}
+	case "lowerLetter":
+		if n >= 1 && n <= 26 {
+			return string(rune('a' + n - 1))
+		}
+	case "upperLetter":
+		if n >= 1 && n <= 26 {
+			return string(rune('A' + n - 1))
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// chineseNumeral 将 1-99 转为中文小写数字，超出范围返回空串
+func chineseNumeral(n int) string {
+	digits := []string{"", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	switch {
+	case n >= 1 && n <= 9:
+		return digits[n]
+	case n >= 10 && n <= 19:
+		return "十" + digits[n%10]
+	case n >= 20 && n <= 99:
+		return digits[n/10] + "十" + digits[n%10]
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package extractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	relTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	relTypeAltChunk       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/aFChunk"
+)
+
+// maxAltChunkDepth altChunk 嵌入的 DOCX 再嵌入 altChunk 时最多展开的层数
+const maxAltChunkDepth = 2
+
+// maxAltChunkSize 单个嵌入文档的解压上限，防止异常文件占用过多内存
+const maxAltChunkSize = 32 << 20
+
+// extraDocumentPart 匹配部分模板生成工具拆分出的额外主文档部件，如 word/document2.xml
+var extraDocumentPart = regexp.MustCompile(`^word/document\d+\.xml$`)
+
+// docxRelationship .rels 文件中的一条关系
+type docxRelationship struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+// mainDocumentParts 返回需要提取正文的主文档部件：_rels/.rels 声明的主文档 (缺省为 word/document.xml)，
+// 以及其后按名称排序的 word/document2.xml 等额外部件
+func mainDocumentParts(r *zip.Reader) []string {
+	main := "word/document.xml"
+	for _, rel := range readRelationships(r, "_rels/.rels") {
+		if rel.Type == relTypeOfficeDocument {
+			main = strings.TrimPrefix(path.Clean("/"+rel.Target), "/")
+			break
+		}
+	}
+
+	var parts, extra []string
+	for _, f := range r.File {
+		switch {
+		case f.Name == main:
+			parts = append(parts, main)
+		case extraDocumentPart.MatchString(f.Name) || (f.Name == "word/document.xml" && main != f.Name):
+			extra = append(extra, f.Name)
+		}
+	}
+	sort.Strings(extra)
+	return append(parts, extra...)
+}
+
+// partRelationships 返回文档部件中 altChunk 关系 ID 到嵌入文件路径的映射
+func partRelationships(r *zip.Reader, part string) map[string]string {
+	dir, file := path.Split(part)
+	rels := make(map[string]string)
+	for _, rel := range readRelationships(r, dir+"_rels/"+file+".rels") {
+		if rel.Type == relTypeAltChunk {
+			rels[rel.ID] = strings.TrimPrefix(path.Clean("/"+path.Join(dir, rel.Target)), "/")
+		}
+	}
+	return rels
+}
+
+// readRelationships 读取 .rels 文件，文件不存在或无法解析时返回 nil
+func readRelationships(r *zip.Reader, name string) []docxRelationship {
+	rc, err := openZipFile(r, name)
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	var rels struct {
+		Relationships []docxRelationship `xml:"Relationship"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&rels); err != nil {
+		return nil
+	}
+	return rels.Relationships
+}
+
+// altChunkText 提取 altChunk 嵌入文档的纯文本，支持 DOCX、HTML/MHT 与纯文本；其他格式 (如 RTF) 返回空串
+func altChunkText(r *zip.Reader, name string, resolveNumbering bool, depth int) string {
+	rc, err := openZipFile(r, name)
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxAltChunkSize))
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".docx", ".docm", ".dotx":
+		if depth >= maxAltChunkDepth {
+			return ""
+		}
+		text, err := docxText(data, resolveNumbering, depth+1)
+		if err != nil {
+			return ""
+		}
+		return text
+	case ".htm", ".html", ".xhtml", ".mht", ".mhtml":
+		return htmlText(string(data))
+	case ".txt":
+		return string(data)
+	default:
+		return ""
+	}
+}
+
+var (
+	htmlHidden = regexp.MustCompile(`(?is)<(?:head|style|script)\b.*?</(?:head|style|script)>`)
+	htmlBreak  = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|div|li|tr|h[1-6])>`)
+)
+
+// htmlText 将 HTML 转为纯文本：块级元素结束处换行，去除标签并还原字符实体
+func htmlText(s string) string {
+	s = htmlHidden.ReplaceAllString(s, "")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(stripHTML(s))
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// openZipFile 打开压缩包中的指定文件
+func openZipFile(r *zip.Reader, name string) (io.ReadCloser, error) {
+	for _, f := range r.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
@@ -0,0 +1,204 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// AttachmentKey 记录所属的邮件附件文件名 (元数据)
+const AttachmentKey = "_attachment"
+
+// maxEmailDepth 转发邮件 (message/rfc822) 嵌套展开的最大层数
+const maxEmailDepth = 3
+
+// emailAttachment 邮件中的一个附件
+type emailAttachment struct {
+	Name   string
+	Data   []byte
+	Inline bool // 正文内嵌的图片 (Content-Disposition: inline 或带 Content-ID)
+}
+
+// extractEmail 解析 .eml 邮件，依次提取其中的 PDF、DOCX 附件，记录以 AttachmentKey 标注附件文件名。
+// 图片附件 (含正文内嵌图片) 仅在配置了云端识别引擎时识别，否则跳过。
+// 单个附件失败时记录警告并继续；全部失败时返回第一个错误
+func (e *Extractor) extractEmail(ctx context.Context, fileData []byte, fields []string, onProgress ProgressCallback) ([]Record, error) {
+	attachments, err := emailAttachments(fileData)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	var firstErr error
+	processed := 0
+	for i, a := range attachments {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if onProgress != nil {
+			onProgress(i, len(attachments), "正在处理附件 "+a.Name)
+		}
+
+		ext := strings.ToLower(filepath.Ext(a.Name))
+		var recs []Record
+		switch {
+		case ext == ".pdf" || ext == ".docx":
+			recs, err = e.extractData(ctx, a.Data, a.Name, fields, nil, nil)
+		case slices.Contains(ImageExtensions(), ext) && e.ocr != nil:
+			routeFrom(ctx).addPages(1)
+			recs, err = e.ocrImage(ctx, a.Data, nil)
+			recs = keepFields(recs, fields)
+		default:
+			e.logger.Debug("跳过不支持的附件", "attachment", a.Name, "inline", a.Inline)
+			continue
+		}
+		processed++
+		if err != nil {
+			e.logger.Warn("附件提取失败", "attachment", a.Name, "error", err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("附件 %s: %w", a.Name, err)
+			}
+			continue
+		}
+		for _, r := range recs {
+			tagged := make(Record, len(r)+1)
+			for k, v := range r {
+				tagged[k] = v
+			}
+			tagged[AttachmentKey] = a.Name
+			records = append(records, tagged)
+		}
+	}
+
+	if processed == 0 {
+		return nil, fmt.Errorf("邮件中没有可提取的 PDF 或 DOCX 附件")
+	}
+	if len(records) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return records, nil
+}
+
+// emailAttachments 解析 MIME 邮件，按出现顺序返回全部附件 (含转发邮件中的附件)；正文部分不作为附件返回
+func emailAttachments(data []byte) ([]emailAttachment, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("无法解析邮件: %w", err)
+	}
+	var out []emailAttachment
+	if err := walkMIMEPart(mimeHeader(msg.Header), msg.Body, 0, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// mimeHeader 适配 mail.Header 与 multipart.Part 的头部
+type mimeHeader map[string][]string
+
+func (h mimeHeader) get(key string) string {
+	if v := h[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func walkMIMEPart(h mimeHeader, body io.Reader, depth int, out *[]emailAttachment) error {
+	mediaType, params, err := mime.ParseMediaType(h.get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("无法解析邮件: %w", err)
+			}
+			if err := walkMIMEPart(mimeHeader(p.Header), p, depth, out); err != nil {
+				return err
+			}
+		}
+	}
+
+	decoded := decodeTransfer(h.get("Content-Transfer-Encoding"), body)
+	if mediaType == "message/rfc822" && depth < maxEmailDepth {
+		msg, err := mail.ReadMessage(decoded)
+		if err != nil {
+			return nil
+		}
+		return walkMIMEPart(mimeHeader(msg.Header), msg.Body, depth+1, out)
+	}
+
+	disposition, dparams, _ := mime.ParseMediaType(h.get("Content-Disposition"))
+	name := decodeHeaderWord(dparams["filename"])
+	if name == "" {
+		name = decodeHeaderWord(params["name"])
+	}
+	if name == "" {
+		// 没有文件名的部分是正文
+		return nil
+	}
+
+	data, err := io.ReadAll(decoded)
+	if err != nil {
+		return fmt.Errorf("读取附件 %s 失败: %w", name, err)
+	}
+	*out = append(*out, emailAttachment{
+		Name:   filepath.Base(name),
+		Data:   data,
+		Inline: disposition == "inline" || h.get("Content-Id") != "",
+	})
+	return nil
+}
+
+// decodeTransfer 按 Content-Transfer-Encoding 解码正文
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r) // 解码器会忽略换行
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+// decodeHeaderWord 解码 RFC 2047 编码的文件名 (如 "=?UTF-8?B?...?=")，常见的 GBK 编码同样支持
+func decodeHeaderWord(s string) string {
+	if s == "" {
+		return s
+	}
+	dec := mime.WordDecoder{CharsetReader: charsetReader}
+	if decoded, err := dec.DecodeHeader(s); err == nil {
+		return decoded
+	}
+	return s
+}
+
+// charsetReader 支持国内邮件客户端常用的 GB 系列编码
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "gbk", "gb2312", "cp936":
+		return transform.NewReader(input, simplifiedchinese.GBK.NewDecoder()), nil
+	case "gb18030":
+		return transform.NewReader(input, simplifiedchinese.GB18030.NewDecoder()), nil
+	}
+	return nil, fmt.Errorf("不支持的字符集: %s", charset)
+}
@@ -0,0 +1,125 @@
+package extractor
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"legal-extractor/internal/config"
+)
+
+// CostEstimate 处理前的页数与识别费用估算
+type CostEstimate struct {
+	TotalPages    int     `json:"totalPages"`
+	TextPages     int     `json:"textPages"`     // 带原生文本层的页数
+	ScannedPages  int     `json:"scannedPages"`  // 文本过少、视为扫描件的页数
+	NeedsOCR      bool    `json:"needsOcr"`      // 与 ExtractData 的判断一致：前几页均无文本层时整份文档走识别
+	Provider      string  `json:"provider"`      // 将使用的识别引擎，无需识别时为空
+	OCRPages      int     `json:"ocrPages"`      // 预计送入识别引擎的页数
+	OCRCalls      int     `json:"ocrCalls"`      // 预计的接口调用次数
+	EstimatedCost float64 `json:"estimatedCost"` // 预计费用 (元)，按 ocr.cost_per_page 计算，本地识别为 0
+}
+
+// EstimateCost 在提取前估算页数与识别开销，只读取本地文本层，不调用任何识别接口
+func (e *Extractor) EstimateCost(fileData []byte, fileName string) (CostEstimate, error) {
+	var est CostEstimate
+	switch ext := strings.ToLower(filepath.Ext(fileName)); ext {
+	case ".docx":
+		// DOCX 始终本地解析
+		return est, nil
+	case ".eml":
+		return e.estimateEmail(fileData)
+	case ".pdf":
+	default:
+		return est, fmt.Errorf("不支持的文件格式: %s", ext)
+	}
+
+	doc, err := e.pdfText().Open(fileData)
+	if err != nil {
+		if isPdfEncryptionError(err) {
+			return est, ErrPDFEncrypted
+		}
+		return est, fmt.Errorf("无法读取 PDF: %w", err)
+	}
+
+	est.TotalPages = doc.NumPage()
+	for i := 1; i <= est.TotalPages; i++ {
+		text, _ := doc.PageText(i)
+		if e.hasTextLayer(text) {
+			est.TextPages++
+		} else {
+			est.ScannedPages++
+		}
+	}
+	est.NeedsOCR = !e.probeTextLayer(doc, est.TotalPages)
+	if !est.NeedsOCR {
+		return est, nil
+	}
+
+	est.OCRPages = est.TotalPages
+	est.OCRCalls = ocrCalls(e.ocr, est.TotalPages)
+	switch p := e.ocr.(type) {
+	case nil:
+		// 本地系统识别逐页调用桥接工具，不产生费用
+		est.Provider = "winocr"
+	case *MockOCRProvider:
+		est.Provider = p.Name()
+	default:
+		est.Provider = p.Name()
+		est.EstimatedCost = float64(est.OCRPages) * config.Get().OCR.CostPerPage
+	}
+	return est, nil
+}
+
+// estimateEmail 汇总邮件中各 PDF 附件的估算；图片附件按一页识别计入
+func (e *Extractor) estimateEmail(fileData []byte) (CostEstimate, error) {
+	var est CostEstimate
+	attachments, err := emailAttachments(fileData)
+	if err != nil {
+		return est, err
+	}
+	for _, a := range attachments {
+		var sub CostEstimate
+		switch ext := strings.ToLower(filepath.Ext(a.Name)); {
+		case ext == ".pdf":
+			if sub, err = e.EstimateCost(a.Data, a.Name); err != nil {
+				return est, fmt.Errorf("附件 %s: %w", a.Name, err)
+			}
+		case slices.Contains(ImageExtensions(), ext) && e.ocr != nil:
+			sub = CostEstimate{TotalPages: 1, ScannedPages: 1, NeedsOCR: true, Provider: e.ocr.Name(), OCRPages: 1, OCRCalls: 1}
+			if _, mock := e.ocr.(*MockOCRProvider); !mock {
+				sub.EstimatedCost = config.Get().OCR.CostPerPage
+			}
+		default:
+			continue
+		}
+		est.TotalPages += sub.TotalPages
+		est.TextPages += sub.TextPages
+		est.ScannedPages += sub.ScannedPages
+		est.NeedsOCR = est.NeedsOCR || sub.NeedsOCR
+		if sub.Provider != "" {
+			est.Provider = sub.Provider
+		}
+		est.OCRPages += sub.OCRPages
+		est.OCRCalls += sub.OCRCalls
+		est.EstimatedCost += sub.EstimatedCost
+	}
+	return est, nil
+}
+
+// ocrCalls 估算识别 pages 页所需的接口调用次数，p 为 nil 表示本地系统识别 (逐页调用)
+func ocrCalls(p OCRProvider, pages int) int {
+	switch p.(type) {
+	case nil:
+		return pages
+	case *BaiduClient:
+		// 超过单次页数上限的 PDF 按块调用
+		if pages > baiduMaxPagesPerChunk {
+			return (pages + baiduMaxPagesPerChunk - 1) / baiduMaxPagesPerChunk
+		}
+		return 1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,33 @@
+package extractor
+
+import "testing"
+
+func TestEstimateCost(t *testing.T) {
+	e := NewExtractor(nil)
+
+	est, err := e.EstimateCost(readFixture(t, "complaint.pdf"), "complaint.pdf")
+	if err != nil {
+		t.Fatalf("EstimateCost returned error: %v", err)
+	}
+	if est.TotalPages != 1 || est.TextPages != 1 || est.NeedsOCR || est.OCRPages != 0 || est.OCRCalls != 0 {
+		t.Errorf("Text PDF should need no OCR, got %+v", est)
+	}
+
+	e.SetOCRProvider(NewMockOCRProvider())
+	est, err = e.EstimateCost(readFixture(t, "scanned.pdf"), "scanned.pdf")
+	if err != nil {
+		t.Fatalf("EstimateCost returned error: %v", err)
+	}
+	if est.TotalPages != 2 || est.ScannedPages != 2 || !est.NeedsOCR || est.OCRPages != 2 {
+		t.Errorf("Scanned PDF should send both pages to OCR, got %+v", est)
+	}
+	if est.Provider != "mock" || est.OCRCalls != 1 || est.EstimatedCost != 0 {
+		t.Errorf("Unexpected provider estimate: %+v", est)
+	}
+
+	// 本地系统识别逐页调用
+	e.SetOCRProvider(nil)
+	if est, _ = e.EstimateCost(readFixture(t, "scanned.pdf"), "scanned.pdf"); est.Provider != "winocr" || est.OCRCalls != 2 {
+		t.Errorf("Expected per-page local OCR calls, got %+v", est)
+	}
+}
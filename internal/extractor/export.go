@@ -1,42 +1,271 @@
 package extractor
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"legal-extractor/internal/config"
 
 	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// CSV output encodings
+const (
+	EncodingUTF8 = "utf8"
+	EncodingGBK  = "gbk"
+)
+
+// ExportOptions controls optional behaviour of the exporters
+type ExportOptions struct {
+	// BOM prepends a UTF-8 BOM to CSV output so Excel detects the encoding
+	BOM bool `json:"bom"`
+	// SanitizeFormulas prefixes cells that Excel would treat as a formula
+	// (leading =, +, -, @, tab or CR) with an apostrophe to prevent CSV injection
+	SanitizeFormulas bool `json:"sanitizeFormulas"`
+	// Append adds the records to an existing JSON array file instead of overwriting it
+	Append bool `json:"append"`
+	// Styled applies the configured branding (title row, header fill, frozen header) to Excel exports
+	Styled bool `json:"styled"`
+	// Summary adds a "summary" column that flattens the labeled fields into one line
+	Summary bool `json:"summary"`
+	// SummaryOnly exports the summary column instead of the structured fields (implies Summary)
+	SummaryOnly bool `json:"summaryOnly"`
+	// SummaryTemplate overrides export.summary_template from conf.yaml, see FormatSummary
+	SummaryTemplate string `json:"summaryTemplate"`
+	// Headers overrides the column header of individual fields (field key -> header),
+	// e.g. EnglishHeaders; fields not listed keep their Chinese label
+	Headers map[string]string `json:"headers"`
+	// Encoding selects the CSV/TSV character encoding: EncodingUTF8 (default) or
+	// EncodingGBK for legacy tools; GBK output never has a BOM
+	Encoding string `json:"encoding"`
+	// GroupBy is the field key ExportGrouped splits the records by, e.g. "court"
+	GroupBy string `json:"groupBy"`
+	// Long writes CSV, TSV and Excel in long (tidy) format, one row per field with
+	// the columns record_id, field_key, field_label and value; other formats ignore it
+	Long bool `json:"long"`
+	// DocTypes keeps only the records of the given document types (see DocTypeKey),
+	// e.g. ["起诉状"] to drop judgments from a mixed batch; empty exports every record
+	DocTypes []string `json:"docTypes"`
+	// Checksum writes a SHA-256 manifest (<file>.sha256) next to every file
+	// exported by ExportFile or ExportGrouped, see WriteChecksum and VerifyExport
+	Checksum bool `json:"checksum"`
+}
+
+// EnglishHeaders is a ready-made header set for importing into English-headed systems
+var EnglishHeaders = map[string]string{
+	"page":             "Page",
+	"plaintiff":        "Plaintiff",
+	"plaintiffId":      "Plaintiff ID Number",
+	"plaintiffPhone":   "Plaintiff Phone",
+	"plaintiffAgent":   "Plaintiff Agent",
+	"plaintiffLawFirm": "Plaintiff Law Firm",
+	"defendant":        "Defendant",
+	"gender":           "Gender",
+	"birthday":         "Date of Birth",
+	"idNumber":         "ID Number",
+	"creditCode":       "Unified Social Credit Code",
+	"caseCause":        "Cause of Action",
+	"agentAuthority":   "Agent Authority",
+	"thirdParty":       "Third Party",
+	"thirdPartyId":     "Third Party ID Number",
+	"request":          "Claims",
+	"amount":           "Amount",
+	"amountValue":      "Amount (Value)",
+	"amountCurrency":   "Currency",
+	"factsReason":      "Facts and Reasons",
+	"summary":          "Summary",
+	"docType":          "Document Type",
+	"sourceFile":       "Source File",
+	"sourceHash":       "Source SHA-256",
+	"sourcePage":       "Source Page",
+	"extractedAt":      "Extracted At",
+}
+
+// ExcelStyle describes the branding applied by ExportExcelStyled
+type ExcelStyle struct {
+	// Title is written to a merged first row, e.g. the firm name; empty means no title row
+	Title string
+	// HeaderFill is the header background colour as hex, e.g. "#1F4E78"; empty means no fill
+	HeaderFill string
+	// FreezeHeader keeps the header row visible while scrolling
+	FreezeHeader bool
+	// AutoFit sizes each column to its widest cell instead of the fixed default widths
+	AutoFit bool
+}
+
+// exportFieldOrder is the column order shared by all tabular exporters:
+// the plaintiff-side group, then the defendant-side group, then the case itself,
+// then the provenance columns written by Extractor.IncludeProvenance
+var exportFieldOrder = []string{"page", "plaintiff", "plaintiffId", "plaintiffPhone", "plaintiffAgent", "plaintiffLawFirm", "agentAuthority", "defendant", "gender", "birthday", "idNumber", "creditCode", "thirdParty", "thirdPartyId", "caseCause", "request", "amount", "amountValue", "amountCurrency", "factsReason", "summary", "sourceFile", "sourceHash", "sourcePage", "extractedAt"}
+
+// exportColumns returns the keys present in the first record, in export order,
+// together with their header labels (overridden by opts.Headers). The page column is optional.
+func exportColumns(first Record, includePage bool, opts ExportOptions) (keys []string, headers []string) {
+	for _, k := range exportFieldOrder {
+		if k == "page" && !includePage {
+			continue
+		}
+		if _, ok := first[k]; ok {
+			keys = append(keys, k)
+			header := PatternRegistry[k].Label
+			if h := opts.Headers[k]; h != "" {
+				header = h
+			}
+			headers = append(headers, header)
+		}
+	}
+	return keys, headers
+}
+
+// docTypeField and docTypeLabel name the document type row of the long format
+const (
+	docTypeField = "docType"
+	docTypeLabel = "文书类型"
 )
 
-func writeCSV(path string, records []Record) error {
+// longColumns are the keys and headers of the long format
+var longColumns = []string{"record_id", "field_key", "field_label", "value"}
+
+// tableColumns returns the export columns and the records to lay out under them:
+// the records themselves in wide format, or one record per field when opts.Long is set.
+// In long format every field present on a record gives a row, in export order, and
+// field_label honours opts.Headers; record_id numbers the records from 1. A record's
+// document type, when detected, comes first as field_key "docType".
+func tableColumns(records []Record, includePage bool, opts ExportOptions) (keys, headers []string, rows []Record) {
+	if !opts.Long {
+		if len(records) == 0 {
+			return nil, nil, records
+		}
+		keys, headers = exportColumns(records[0], includePage, opts)
+		return keys, headers, records
+	}
+
+	for i, r := range records {
+		if docType := r[DocTypeKey]; docType != "" {
+			label := docTypeLabel
+			if h := opts.Headers[docTypeField]; h != "" {
+				label = h
+			}
+			rows = append(rows, Record{
+				"record_id":   strconv.Itoa(i + 1),
+				"field_key":   docTypeField,
+				"field_label": label,
+				"value":       docType,
+			})
+		}
+		fields, labels := exportColumns(r, includePage, opts)
+		for j, k := range fields {
+			rows = append(rows, Record{
+				"record_id":   strconv.Itoa(i + 1),
+				"field_key":   k,
+				"field_label": labels[j],
+				"value":       r[k],
+			})
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil, nil
+	}
+	return longColumns, longColumns, rows
+}
+
+// exportRecords applies the record-level options shared by every format:
+// the DocTypes filter, then the summary column
+func exportRecords(records []Record, opts ExportOptions) []Record {
+	return withSummary(FilterDocType(records, opts.DocTypes...), opts)
+}
+
+// DefaultExportOptions returns the options used by the plain Export* functions
+func DefaultExportOptions() ExportOptions {
+	return ExportOptions{BOM: true, SanitizeFormulas: true}
+}
+
+// sanitizeCell guards a value against formula interpretation
+func sanitizeCell(v string) string {
+	if v == "" {
+		return v
+	}
+	switch v[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + v
+	}
+	return v
+}
+
+// csvEncoder returns the encoder for opts.Encoding, or nil for UTF-8.
+// Characters GBK cannot represent (some rare name characters) become "?" instead of failing the export.
+func csvEncoder(opts ExportOptions) (*encoding.Encoder, error) {
+	switch strings.ToLower(opts.Encoding) {
+	case "", EncodingUTF8, "utf-8":
+		return nil, nil
+	case EncodingGBK:
+		return encoding.ReplaceUnsupported(simplifiedchinese.GBK.NewEncoder()), nil
+	default:
+		return nil, fmt.Errorf("unsupported CSV encoding: %s", opts.Encoding)
+	}
+}
+
+// writeCSV writes records as delimiter-separated values to the file at path
+func writeCSV(path string, records []Record, comma rune, opts ExportOptions) error {
+	if _, err := csvEncoder(opts); err != nil {
+		return err
+	}
 	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	return writeDelimited(file, records, comma, opts)
+}
 
-	file.WriteString("\xEF\xBB\xBF") // BOM for Excel
-
-	w := csv.NewWriter(file)
-	defer w.Flush()
-
-	if len(records) == 0 {
-		return nil
+// writeDelimited writes records as delimiter-separated values.
+// comma selects the field delimiter; opts controls the encoding, BOM and formula guarding.
+func writeDelimited(dst io.Writer, records []Record, comma rune, opts ExportOptions) (err error) {
+	records = exportRecords(records, opts)
+	enc, err := csvEncoder(opts)
+	if err != nil {
+		return err
 	}
 
-	// 1. Determine Headers from the first record and PatternRegistry
-	// We want to keep a consistent order if possible
-	var keys []string
-	var headers []string
+	out := dst
+	if enc != nil {
+		tw := transform.NewWriter(dst, enc)
+		defer func() {
+			if closeErr := tw.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = tw
+	} else if opts.BOM {
+		if _, err := io.WriteString(dst, "\xEF\xBB\xBF"); err != nil { // BOM for Excel
+			return err
+		}
+	}
 
-	// Order based on PatternRegistry for consistency
-	orderedKeys := []string{"defendant", "idNumber", "request", "factsReason"}
-	for _, k := range orderedKeys {
-		if _, ok := records[0][k]; ok {
-			keys = append(keys, k)
-			headers = append(headers, PatternRegistry[k].Label)
+	w := csv.NewWriter(out)
+	w.Comma = comma
+	defer func() {
+		w.Flush()
+		if err == nil {
+			err = w.Error()
 		}
+	}()
+
+	// 1. Determine Headers from the first record, in a consistent order
+	keys, headers, records := tableColumns(records, false, opts)
+	if len(keys) == 0 {
+		return nil
 	}
 
 	if err := w.Write(headers); err != nil {
@@ -48,6 +277,9 @@ func writeCSV(path string, records []Record) error {
 		row := make([]string, len(keys))
 		for i, k := range keys {
 			row[i] = r[k]
+			if opts.SanitizeFormulas {
+				row[i] = sanitizeCell(row[i])
+			}
 		}
 		if err := w.Write(row); err != nil {
 			return err
@@ -58,96 +290,389 @@ func writeCSV(path string, records []Record) error {
 
 // ExportCSV exports records to a CSV file
 func ExportCSV(path string, records []Record) error {
-	return writeCSV(path, records)
+	return ExportCSVWithOptions(path, records, DefaultExportOptions())
+}
+
+// ExportCSVWithOptions exports records to a CSV file using the given options
+func ExportCSVWithOptions(path string, records []Record, opts ExportOptions) error {
+	return writeCSV(path, records, ',', opts)
+}
+
+// WriteCSV writes records as CSV to w, e.g. an in-memory buffer or an HTTP response
+func WriteCSV(w io.Writer, records []Record, opts ExportOptions) error {
+	return writeDelimited(w, records, ',', opts)
+}
+
+// ExportTSV exports records to a tab-separated file without BOM,
+// which pastes cleanly into web spreadsheets such as Google Sheets
+func ExportTSV(path string, records []Record) error {
+	return ExportTSVWithOptions(path, records, DefaultExportOptions())
+}
+
+// ExportTSVWithOptions exports records to a tab-separated file; the BOM option is ignored
+func ExportTSVWithOptions(path string, records []Record, opts ExportOptions) error {
+	opts.BOM = false
+	return writeCSV(path, records, '\t', opts)
+}
+
+// WriteTSV writes records as tab-separated values to w; the BOM option is ignored
+func WriteTSV(w io.Writer, records []Record, opts ExportOptions) error {
+	opts.BOM = false
+	return writeDelimited(w, records, '\t', opts)
 }
 
 // ExportJSON exports records to a JSON file
 func ExportJSON(path string, records []Record) error {
+	return ExportJSONWithOptions(path, records, DefaultExportOptions())
+}
+
+// ExportJSONWithOptions exports records to a JSON file. In append mode the
+// existing file must hold a JSON array; the new records are appended to it and
+// the file is rewritten atomically. A missing or empty file is treated as [].
+func ExportJSONWithOptions(path string, records []Record, opts ExportOptions) error {
+	if !opts.Append {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return WriteJSON(file, records, opts)
+	}
+	records = exportRecords(records, opts)
+
+	var existing []json.RawMessage
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("existing file %s is not a JSON array: %w", filepath.Base(path), err)
+		}
+	}
+
+	combined := make([]any, 0, len(existing)+len(records))
+	for _, raw := range existing {
+		combined = append(combined, raw)
+	}
+	for _, r := range records {
+		combined = append(combined, r)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(combined); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteJSON writes records to w as an indented JSON array; the Append option is ignored
+func WriteJSON(w io.Writer, records []Record, opts ExportOptions) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exportRecords(records, opts))
+}
+
+// ExportText writes a human-readable report with one labeled block per case,
+// intended for paralegals to skim or print rather than for data import
+func ExportText(path string, records []Record) error {
 	file, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
+	return WriteText(file, records)
+}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(records)
+// WriteText writes the ExportText report to dst
+func WriteText(dst io.Writer, records []Record) error {
+	w := bufio.NewWriter(dst)
+	orderedKeys := []string{"plaintiff", "plaintiffId", "plaintiffPhone", "plaintiffAgent", "plaintiffLawFirm", "agentAuthority", "defendant", "gender", "birthday", "idNumber", "creditCode", "thirdParty", "thirdPartyId", "caseCause", "request", "amount", "factsReason", "sourceFile", "sourceHash", "extractedAt"}
+	for i, r := range records {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		heading := fmt.Sprintf("案件%d", i+1)
+		if page := r["page"]; page != "" {
+			heading += fmt.Sprintf("（第 %s 页）", page)
+		}
+		fmt.Fprintf(w, "%s\n%s\n", heading, strings.Repeat("=", 20))
+
+		for _, k := range orderedKeys {
+			value := smartMerge(r[k])
+			if value == "" {
+				continue
+			}
+			label := PatternRegistry[k].Label
+			if strings.Contains(value, "\n") {
+				fmt.Fprintf(w, "%s：\n%s\n", label, value)
+			} else {
+				fmt.Fprintf(w, "%s：%s\n", label, value)
+			}
+		}
+	}
+	return w.Flush()
 }
 
-// ExportExcel exports records to an Excel file
+// ExportExcel exports records to an Excel file.
+// Cells are stored as string values, which Excel never evaluates, so formula
+// guarding is off here; use ExportExcelWithOptions to enable it.
 func ExportExcel(path string, records []Record) error {
+	opts := DefaultExportOptions()
+	opts.SanitizeFormulas = false
+	return ExportExcelWithOptions(path, records, opts)
+}
+
+// ExportExcelWithOptions exports records to an Excel file using the given options
+func ExportExcelWithOptions(path string, records []Record, opts ExportOptions) error {
+	return writeExcel(path, records, opts, ExcelStyle{})
+}
+
+// BrandedExcelStyle returns the style configured under "branding" in conf.yaml
+func BrandedExcelStyle() ExcelStyle {
+	branding := config.Get().Branding
+	return ExcelStyle{
+		Title:        branding.FirmName,
+		HeaderFill:   branding.HeaderColor,
+		FreezeHeader: true,
+		AutoFit:      true,
+	}
+}
+
+// ExportExcelStyled exports records to an Excel file with a title row and styled header
+func ExportExcelStyled(path string, records []Record, opts ExportOptions, style ExcelStyle) error {
+	return writeExcel(path, records, opts, style)
+}
+
+// writeExcel is shared by the Excel file exporters; nothing is written when there are no records
+func writeExcel(path string, records []Record, opts ExportOptions, style ExcelStyle) error {
+	f, err := buildExcel(records, opts, style)
+	if err != nil {
+		return err
+	}
+	defer closeExcel(f)
+
+	if len(records) == 0 {
+		return nil
+	}
+	return f.SaveAs(path)
+}
+
+// WriteExcel writes the workbook to w; a zero ExcelStyle gives the plain layout
+func WriteExcel(w io.Writer, records []Record, opts ExportOptions, style ExcelStyle) error {
+	f, err := buildExcel(records, opts, style)
+	if err != nil {
+		return err
+	}
+	defer closeExcel(f)
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+func closeExcel(f *excelize.File) {
+	if err := f.Close(); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// buildExcel lays out the workbook; a zero ExcelStyle gives the plain layout.
+// The caller must close the returned file.
+func buildExcel(records []Record, opts ExportOptions, style ExcelStyle) (*excelize.File, error) {
+	keys, headers, rows := tableColumns(exportRecords(records, opts), true, opts)
+	return newWorkbook("Sheet1", keys, headers, rows, opts, style)
+}
+
+// newWorkbook creates a workbook with a single sheet holding the given columns;
+// a blank key gives an empty column. Nothing is laid out when there are no columns.
+// The caller must close the returned file.
+func newWorkbook(sheetName string, keys, headers []string, records []Record, opts ExportOptions, style ExcelStyle) (*excelize.File, error) {
 	f := excelize.NewFile()
-	defer func() {
-		if err := f.Close(); err != nil {
-			fmt.Println(err)
+	if sheetName != "Sheet1" {
+		if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+			closeExcel(f)
+			return nil, err
 		}
-	}()
+	}
 
 	// Create a new sheet.
-	sheetName := "Sheet1"
 	index, err := f.NewSheet(sheetName)
 	if err != nil {
-		return err
+		closeExcel(f)
+		return nil, err
 	}
 
 	// Set active sheet of the workbook.
 	f.SetActiveSheet(index)
 
-	if len(records) == 0 {
-		return nil
+	if len(keys) == 0 {
+		return f, nil
 	}
 
-	// 1. Determine Headers
-	var keys []string
-	var headers []string
-	orderedKeys := []string{"page", "defendant", "idNumber", "request", "factsReason"}
-	for _, k := range orderedKeys {
-		if _, ok := records[0][k]; ok {
-			keys = append(keys, k)
-			headers = append(headers, PatternRegistry[k].Label)
+	if err := fillExcel(f, sheetName, keys, headers, records, opts, style); err != nil {
+		closeExcel(f)
+		return nil, err
+	}
+	return f, nil
+}
+
+// fillExcel writes the title, header and data rows of sheetName
+func fillExcel(f *excelize.File, sheetName string, keys, headers []string, records []Record, opts ExportOptions, style ExcelStyle) error {
+	headerRow, err := writeExcelHeader(f, sheetName, headers, style)
+	if err != nil {
+		return err
+	}
+	wrapStyle, _ := newWrapStyle(f)
+	widths := headerWidths(headers)
+	if err := writeExcelRows(f, sheetName, keys, records, headerRow+1, opts, wrapStyle, widths); err != nil {
+		return err
+	}
+	return finishExcelSheet(f, sheetName, headerRow, widths, style)
+}
+
+// writeExcelHeader writes the optional title row and the header row, returning the header row number
+func writeExcelHeader(f *excelize.File, sheetName string, headers []string, style ExcelStyle) (int, error) {
+	lastCol, err := excelize.ColumnNumberToName(len(headers))
+	if err != nil {
+		return 0, err
+	}
+
+	// Optional title row above the header
+	headerRow := 1
+	if style.Title != "" {
+		headerRow = 2
+		if err := f.SetCellValue(sheetName, "A1", style.Title); err != nil {
+			return 0, err
 		}
+		if err := f.MergeCell(sheetName, "A1", lastCol+"1"); err != nil {
+			return 0, err
+		}
+		titleStyle, _ := f.NewStyle(&excelize.Style{
+			Font:      &excelize.Font{Bold: true, Size: 14},
+			Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+		})
+		f.SetCellStyle(sheetName, "A1", "A1", titleStyle)
+		f.SetRowHeight(sheetName, 1, 24)
 	}
 
 	// Set headers
 	for i, header := range headers {
-		cell, err := excelize.CoordinatesToCellName(i+1, 1)
+		cell, err := excelize.CoordinatesToCellName(i+1, headerRow)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if err := f.SetCellValue(sheetName, cell, header); err != nil {
-			return err
+			return 0, err
+		}
+	}
+	if style.HeaderFill != "" {
+		headerStyle, err := f.NewStyle(&excelize.Style{
+			Font: &excelize.Font{Bold: true},
+			Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{style.HeaderFill}},
+		})
+		if err != nil {
+			return 0, err
 		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", headerRow), fmt.Sprintf("%s%d", lastCol, headerRow), headerStyle)
 	}
+	return headerRow, nil
+}
 
-	// 2. Set values
-	wrapStyle, _ := f.NewStyle(&excelize.Style{
+// newWrapStyle registers the top-aligned wrapping style used for data cells
+func newWrapStyle(f *excelize.File) (int, error) {
+	return f.NewStyle(&excelize.Style{
 		Alignment: &excelize.Alignment{
 			WrapText: true,
 			Vertical: "top",
 		},
 	})
+}
 
+// headerWidths returns the initial column widths, those of the headers
+func headerWidths(headers []string) []int {
+	widths := make([]int, len(headers))
+	for j, h := range headers {
+		widths[j] = displayWidth(h)
+	}
+	return widths
+}
+
+// writeExcelRows writes records from row firstRow on, widening widths to the longest line of each column
+func writeExcelRows(f *excelize.File, sheetName string, keys []string, records []Record, firstRow int, opts ExportOptions, wrapStyle int, widths []int) error {
 	for i, r := range records {
-		row := i + 2
+		row := firstRow + i
 		for j, k := range keys {
 			cell, err := excelize.CoordinatesToCellName(j+1, row)
 			if err != nil {
 				return err
 			}
-			if err := f.SetCellValue(sheetName, cell, r[k]); err != nil {
+			value := r[k]
+			if opts.SanitizeFormulas {
+				value = sanitizeCell(value)
+			}
+			if err := f.SetCellValue(sheetName, cell, value); err != nil {
 				return err
 			}
 			// Apply wrap text style
 			f.SetCellStyle(sheetName, cell, cell, wrapStyle)
+			for _, line := range strings.Split(value, "\n") {
+				if w := displayWidth(line); w > widths[j] {
+					widths[j] = w
+				}
+			}
 		}
 	}
+	return nil
+}
 
-	// Set column widths for better readability
-	f.SetColWidth(sheetName, "A", "B", 20)
-	f.SetColWidth(sheetName, "C", "D", 50)
+// finishExcelSheet sets the column widths and freezes the header once all rows are written
+func finishExcelSheet(f *excelize.File, sheetName string, headerRow int, widths []int, style ExcelStyle) error {
+	if style.AutoFit {
+		for j, w := range widths {
+			col, _ := excelize.ColumnNumberToName(j + 1)
+			// Clamp so long request text wraps instead of producing a huge column
+			f.SetColWidth(sheetName, col, col, float64(min(max(w+2, 8), 60)))
+		}
+	} else {
+		// Set column widths for better readability
+		f.SetColWidth(sheetName, "A", "B", 20)
+		f.SetColWidth(sheetName, "C", "D", 50)
+	}
 
-	if err := f.SaveAs(path); err != nil {
-		return err
+	if style.FreezeHeader {
+		if err := f.SetPanes(sheetName, &excelize.Panes{
+			Freeze:      true,
+			YSplit:      headerRow,
+			TopLeftCell: fmt.Sprintf("A%d", headerRow+1),
+			ActivePane:  "bottomLeft",
+		}); err != nil {
+			return err
+		}
 	}
+
 	return nil
 }
+
+// displayWidth approximates the column width of s, counting wide (CJK) runes as two
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		if r > 0x2E80 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	return w
+}
@@ -0,0 +1,130 @@
+package extractor
+
+import (
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ExcelBatchWriter collects the records of a batch run into one open workbook
+// and saves it once at the end, instead of rewriting the file for every document:
+// one header, an AddRecords call per processed file, then a single Save.
+//
+// The columns are fixed by the first AddRecords call that has records: every
+// field present in that call, in export order. Fields first seen later are not
+// exported, so pass fields explicitly to NewExcelBatchWriter when the first file
+// may lack some. With opts.Long the columns are the long-format ones and record_id
+// keeps counting across calls.
+//
+// An ExcelBatchWriter is not safe for concurrent use: call AddRecords from the
+// single goroutine collecting batch results, and Close it when done.
+type ExcelBatchWriter struct {
+	f      *excelize.File
+	fields []string
+	opts   ExportOptions
+	style  ExcelStyle
+
+	keys      []string
+	headers   []string
+	headerRow int
+	nextRow   int
+	records   int // records added so far, for long-format record_id
+	wrapStyle int
+	widths    []int
+}
+
+// NewExcelBatchWriter returns a writer for a workbook with a single "Sheet1".
+// fields fixes the exported fields (in export order regardless of the given order);
+// nil takes them from the first AddRecords call.
+func NewExcelBatchWriter(fields []string, opts ExportOptions, style ExcelStyle) *ExcelBatchWriter {
+	return &ExcelBatchWriter{f: excelize.NewFile(), fields: fields, opts: opts, style: style}
+}
+
+// AddRecords appends the records of one processed file below those already added
+func (w *ExcelBatchWriter) AddRecords(records []Record) error {
+	records = exportRecords(records, w.opts)
+	if len(records) == 0 {
+		return nil
+	}
+
+	rows := records
+	if w.opts.Long {
+		_, _, rows = tableColumns(records, true, w.opts)
+		for _, r := range rows {
+			id, _ := strconv.Atoi(r["record_id"])
+			r["record_id"] = strconv.Itoa(id + w.records)
+		}
+	}
+	w.records += len(records)
+
+	if w.keys == nil {
+		if err := w.writeHeader(records); err != nil || w.keys == nil {
+			return err
+		}
+	}
+	if err := writeExcelRows(w.f, "Sheet1", w.keys, rows, w.nextRow, w.opts, w.wrapStyle, w.widths); err != nil {
+		return err
+	}
+	w.nextRow += len(rows)
+	return nil
+}
+
+// writeHeader fixes the columns from the first records added and writes the header;
+// it leaves w.keys nil while there is nothing to export
+func (w *ExcelBatchWriter) writeHeader(records []Record) error {
+	if w.opts.Long {
+		w.keys, w.headers = longColumns, longColumns
+	} else {
+		present := make(Record)
+		if w.fields != nil {
+			for _, k := range w.fields {
+				present[k] = ""
+			}
+			if w.opts.Summary || w.opts.SummaryOnly {
+				present[SummaryKey] = ""
+			}
+		} else {
+			for _, r := range records {
+				for k := range r {
+					present[k] = ""
+				}
+			}
+		}
+		w.keys, w.headers = exportColumns(present, true, w.opts)
+	}
+	if len(w.keys) == 0 {
+		w.keys, w.headers = nil, nil
+		return nil
+	}
+
+	headerRow, err := writeExcelHeader(w.f, "Sheet1", w.headers, w.style)
+	if err != nil {
+		return err
+	}
+	w.headerRow, w.nextRow = headerRow, headerRow+1
+	w.wrapStyle, _ = newWrapStyle(w.f)
+	w.widths = headerWidths(w.headers)
+	return nil
+}
+
+// Records returns the number of records added so far
+func (w *ExcelBatchWriter) Records() int {
+	return w.records
+}
+
+// Save lays out the column widths and writes the workbook to path.
+// Nothing is written when no records were added, like ExportExcel.
+func (w *ExcelBatchWriter) Save(path string) error {
+	if w.keys == nil {
+		return nil
+	}
+	if err := finishExcelSheet(w.f, "Sheet1", w.headerRow, w.widths, w.style); err != nil {
+		return err
+	}
+	return w.f.SaveAs(path)
+}
+
+// Close releases the workbook; the writer cannot be used afterwards
+func (w *ExcelBatchWriter) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,67 @@
+package extractor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumExt is appended to an export's path to name its checksum manifest
+const ChecksumExt = ".sha256"
+
+// WriteChecksum writes the SHA-256 manifest of the file at path to path+ChecksumExt
+// and returns the manifest path. The manifest uses the sha256sum format
+// ("<hex digest>  <file name>"), so `sha256sum -c` can verify it as well.
+func WriteChecksum(path string) (string, error) {
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	manifest := path + ChecksumExt
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(path))
+	if err := os.WriteFile(manifest, []byte(line), 0644); err != nil {
+		return "", err
+	}
+	return manifest, nil
+}
+
+// VerifyExport reports whether the file at path still matches the digest in its
+// path+ChecksumExt manifest. A mismatch (the file was altered) returns false and
+// a nil error; a missing or malformed manifest returns an error.
+func VerifyExport(path string) (bool, error) {
+	data, err := os.ReadFile(path + ChecksumExt)
+	if err != nil {
+		return false, fmt.Errorf("read checksum manifest: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+		return false, fmt.Errorf("malformed checksum manifest %s", path+ChecksumExt)
+	}
+	if name := strings.TrimPrefix(fields[1], "*"); name != filepath.Base(path) {
+		return false, fmt.Errorf("checksum manifest is for %s, not %s", name, filepath.Base(path))
+	}
+
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(sum, fields[0]), nil
+}
+
+// fileSHA256 returns the hex SHA-256 digest of the file at path
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
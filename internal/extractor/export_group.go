@@ -0,0 +1,112 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UngroupedName is the file name used by ExportGrouped for records missing the group field
+const UngroupedName = "未分类"
+
+// maxGroupNameRunes caps the group part of a file name
+const maxGroupNameRunes = 64
+
+// ExportFile exports records to path, choosing the format from its extension
+// (.xlsx, .json, .txt, .tsv, .parquet, otherwise CSV). Excel honours opts.Styled
+// and never sanitizes formulas, since cells are written as typed strings.
+// With opts.Checksum a checksum manifest is written next to the file, see WriteChecksum.
+func ExportFile(path string, records []Record, opts ExportOptions) error {
+	if err := exportFile(path, records, opts); err != nil {
+		return err
+	}
+	if !opts.Checksum {
+		return nil
+	}
+	// some formats write nothing for an empty record set
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	_, err := WriteChecksum(path)
+	return err
+}
+
+// exportFile writes path in the format given by its extension
+func exportFile(path string, records []Record, opts ExportOptions) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ExportJSONWithOptions(path, records, opts)
+	case ".xlsx":
+		opts.SanitizeFormulas = false
+		if opts.Styled {
+			return ExportExcelStyled(path, records, opts, BrandedExcelStyle())
+		}
+		return ExportExcelWithOptions(path, records, opts)
+	case ".txt":
+		return ExportText(path, records)
+	case ".tsv":
+		return ExportTSVWithOptions(path, records, opts)
+	case ".parquet":
+		return ExportParquetWithOptions(path, records, opts)
+	default:
+		return ExportCSVWithOptions(path, records, opts)
+	}
+}
+
+// ExportGrouped writes one file per distinct value of the opts.GroupBy field into
+// dir, named after the value (e.g. "北京市朝阳区人民法院.xlsx"); records missing the
+// field go to UngroupedName. format is a file extension such as "xlsx" or "csv".
+// Groups keep the order in which they first appear, and the written paths are
+// returned in that order. dir is created if needed.
+func ExportGrouped(dir, format string, records []Record, opts ExportOptions) ([]string, error) {
+	if opts.GroupBy == "" {
+		return nil, fmt.Errorf("no group field given")
+	}
+	format = strings.TrimPrefix(strings.ToLower(format), ".")
+	if format == "" {
+		return nil, fmt.Errorf("no export format given")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var order []string
+	groups := make(map[string][]Record)
+	for _, r := range records {
+		name := groupFileName(r[opts.GroupBy])
+		if _, ok := groups[name]; !ok {
+			order = append(order, name)
+		}
+		groups[name] = append(groups[name], r)
+	}
+
+	paths := make([]string, 0, len(order))
+	for _, name := range order {
+		path := filepath.Join(dir, name+"."+format)
+		if err := ExportFile(path, groups[name], opts); err != nil {
+			return paths, fmt.Errorf("export group %s: %w", name, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// groupFileName turns a group value into a safe file name; values that differ
+// only in characters invalid in file names share a file
+func groupFileName(value string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`\/:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.Join(strings.Fields(value), " "))
+	name = strings.Trim(name, " .")
+	name = truncateRunes(name, maxGroupNameRunes)
+	if name == "" {
+		return UngroupedName
+	}
+	return name
+}
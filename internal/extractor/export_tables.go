@@ -0,0 +1,102 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ExportTablesExcel exports tables to an Excel file, one sheet per table;
+// nothing is written when there are no tables
+func ExportTablesExcel(path string, tables []Table) error {
+	if len(tables) == 0 {
+		return nil
+	}
+	f, err := buildTablesExcel(tables)
+	if err != nil {
+		return err
+	}
+	defer closeExcel(f)
+	return f.SaveAs(path)
+}
+
+// WriteTablesExcel writes the tables workbook to w
+func WriteTablesExcel(w io.Writer, tables []Table) error {
+	f, err := buildTablesExcel(tables)
+	if err != nil {
+		return err
+	}
+	defer closeExcel(f)
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// buildTablesExcel lays out one sheet per table, named after its title
+// (falling back to "表格N"). The caller must close the returned file.
+func buildTablesExcel(tables []Table) (*excelize.File, error) {
+	f := excelize.NewFile()
+	used := make(map[string]bool)
+	for i, t := range tables {
+		name := tableSheetName(t, used)
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", name); err != nil {
+				closeExcel(f)
+				return nil, err
+			}
+		} else if _, err := f.NewSheet(name); err != nil {
+			closeExcel(f)
+			return nil, err
+		}
+
+		wrapStyle, _ := newWrapStyle(f)
+		var widths []int
+		for r, row := range t.Rows {
+			for c, value := range row {
+				cell, err := excelize.CoordinatesToCellName(c+1, r+1)
+				if err != nil {
+					closeExcel(f)
+					return nil, err
+				}
+				if err := f.SetCellValue(name, cell, value); err != nil {
+					closeExcel(f)
+					return nil, err
+				}
+				f.SetCellStyle(name, cell, cell, wrapStyle)
+				if c >= len(widths) {
+					widths = append(widths, 0)
+				}
+				for _, line := range strings.Split(value, "\n") {
+					widths[c] = max(widths[c], displayWidth(line))
+				}
+			}
+		}
+		for c, w := range widths {
+			col, _ := excelize.ColumnNumberToName(c + 1)
+			f.SetColWidth(name, col, col, float64(min(max(w+2, 8), 60)))
+		}
+	}
+	return f, nil
+}
+
+// tableSheetName returns a unique, valid sheet name for t: its title without the
+// characters Excel forbids, cut to the 31-character limit
+func tableSheetName(t Table, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/?*[]:`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(t.Title))
+	if name == "" {
+		name = fmt.Sprintf("表格%d", t.Index)
+	}
+	name = truncateRunes(name, 28)
+	base := name
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,136 @@
+package extractor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ExcelTemplate is a mandated spreadsheet layout, such as a court's filing
+// register: fixed headers in a fixed order, some of them left blank for manual entry.
+type ExcelTemplate struct {
+	// Sheet names the worksheet; empty means "Sheet1"
+	Sheet string
+	// Columns lists the template columns from left to right
+	Columns []TemplateColumn
+}
+
+// TemplateColumn maps one template header to a record field
+type TemplateColumn struct {
+	Header string
+	// Field is the record field key filling the column; empty leaves it blank
+	Field string
+}
+
+// templateFile is the on-disk form of an ExcelTemplate. Columns is a mapping
+// from header to field key, read in document order:
+//
+//	sheet: 立案登记
+//	columns:
+//	  序号: ""
+//	  被告姓名: defendant
+//	  身份证号码: idNumber
+//	  承办人: ""
+type templateFile struct {
+	Sheet   string    `yaml:"sheet"`
+	Columns yaml.Node `yaml:"columns"`
+}
+
+// LoadExcelTemplate reads a template from a YAML or JSON file (JSON is parsed as YAML)
+func LoadExcelTemplate(path string) (ExcelTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ExcelTemplate{}, err
+	}
+	return ParseExcelTemplate(data)
+}
+
+// ParseExcelTemplate parses a YAML or JSON template and checks that every field key is known
+func ParseExcelTemplate(data []byte) (ExcelTemplate, error) {
+	var file templateFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return ExcelTemplate{}, fmt.Errorf("invalid template: %w", err)
+	}
+	if file.Columns.Kind != yaml.MappingNode || len(file.Columns.Content) == 0 {
+		return ExcelTemplate{}, fmt.Errorf("invalid template: columns must map each header to a field key")
+	}
+
+	tmpl := ExcelTemplate{Sheet: file.Sheet}
+	for i := 0; i+1 < len(file.Columns.Content); i += 2 {
+		var col TemplateColumn
+		if err := file.Columns.Content[i].Decode(&col.Header); err != nil {
+			return ExcelTemplate{}, fmt.Errorf("invalid template header: %w", err)
+		}
+		if err := file.Columns.Content[i+1].Decode(&col.Field); err != nil {
+			return ExcelTemplate{}, fmt.Errorf("invalid template column %q: %w", col.Header, err)
+		}
+		tmpl.Columns = append(tmpl.Columns, col)
+	}
+	return tmpl, tmpl.validate()
+}
+
+// validate rejects unknown field keys, which are almost always typos
+func (t ExcelTemplate) validate() error {
+	for _, c := range t.Columns {
+		if c.Field == "" {
+			continue
+		}
+		if _, ok := PatternRegistry[c.Field]; !ok && !slices.Contains(exportFieldOrder, c.Field) && c.Field != SourceKey {
+			return fmt.Errorf("template column %q: unknown field %q", c.Header, c.Field)
+		}
+	}
+	return nil
+}
+
+// ExportExcelTemplate exports records to an Excel file laid out by the template:
+// recognized columns are filled from the records and blank columns stay empty.
+// The header row is written even when there are no records, giving an empty form.
+func ExportExcelTemplate(path string, records []Record, tmpl ExcelTemplate) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteExcelTemplate(file, records, tmpl); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteExcelTemplate writes the ExportExcelTemplate workbook to w
+func WriteExcelTemplate(w io.Writer, records []Record, tmpl ExcelTemplate) error {
+	if len(tmpl.Columns) == 0 {
+		return fmt.Errorf("template has no columns")
+	}
+	if err := tmpl.validate(); err != nil {
+		return err
+	}
+
+	keys := make([]string, len(tmpl.Columns))
+	headers := make([]string, len(tmpl.Columns))
+	opts := ExportOptions{}
+	for i, c := range tmpl.Columns {
+		keys[i], headers[i] = c.Field, c.Header
+		if c.Field == "summary" {
+			opts.Summary = true
+		}
+	}
+	records = exportRecords(records, opts)
+
+	sheet := strings.TrimSpace(tmpl.Sheet)
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	f, err := newWorkbook(sheet, keys, headers, records, opts, ExcelStyle{AutoFit: true})
+	if err != nil {
+		return err
+	}
+	defer closeExcel(f)
+
+	_, err = f.WriteTo(w)
+	return err
+}
@@ -0,0 +1,576 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+var sampleRecords = []Record{
+	{"defendant": "张三", "idNumber": "110101199001011234", "request": "偿还借款", "factsReason": "借款未还"},
+}
+
+// readExport 读取导出文件内容
+func readExport(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	return data
+}
+
+// readCSVRows 解析导出的 CSV (忽略 BOM)
+func readCSVRows(t *testing.T, path string) [][]string {
+	t.Helper()
+	data := bytes.TrimPrefix(readExport(t, path), []byte("\xEF\xBB\xBF"))
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	return rows
+}
+
+func TestExportTSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.tsv")
+	if err := ExportTSV(path, sampleRecords); err != nil {
+		t.Fatalf("ExportTSV returned error: %v", err)
+	}
+
+	data := readExport(t, path)
+	if bytes.HasPrefix(data, []byte("\xEF\xBB\xBF")) {
+		t.Error("TSV output must not start with a UTF-8 BOM")
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header + 1 row, got %d lines", len(lines))
+	}
+	if want := "被告\t身份证号码\t诉讼请求\t事实与理由"; lines[0] != want {
+		t.Errorf("header: expected %q, got %q", want, lines[0])
+	}
+	if got := strings.Split(lines[1], "\t"); len(got) != 4 || got[0] != "张三" {
+		t.Errorf("row not tab separated: %q", lines[1])
+	}
+}
+
+func TestExportCSVWithOptions_BOM(t *testing.T) {
+	tests := []struct {
+		name    string
+		bom     bool
+		wantBOM bool
+	}{
+		{name: "BOM on", bom: true, wantBOM: true},
+		{name: "BOM off", bom: false, wantBOM: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.csv")
+			opts := DefaultExportOptions()
+			opts.BOM = tt.bom
+			if err := ExportCSVWithOptions(path, sampleRecords, opts); err != nil {
+				t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+			}
+
+			data := readExport(t, path)
+			if got := bytes.HasPrefix(data, []byte("\xEF\xBB\xBF")); got != tt.wantBOM {
+				t.Errorf("BOM present = %v, want %v", got, tt.wantBOM)
+			}
+			if !bytes.Contains(data, []byte("被告,身份证号码")) {
+				t.Errorf("Unexpected header: %q", data)
+			}
+		})
+	}
+}
+
+func TestExportCSV_SanitizesFormulas(t *testing.T) {
+	dangerous := []string{"=SUM(A1:A2)", "+8613800000000", "-1+1", "@cmd", "\tindent", "\rcr"}
+
+	var records []Record
+	for _, v := range dangerous {
+		records = append(records, Record{"defendant": "张三", "request": v})
+	}
+
+	path := filepath.Join(t.TempDir(), "out.csv")
+	if err := ExportCSV(path, records); err != nil {
+		t.Fatalf("ExportCSV returned error: %v", err)
+	}
+
+	rows := readCSVRows(t, path)
+	for i, v := range dangerous {
+		if got := rows[i+1][1]; got != "'"+v {
+			t.Errorf("row %d: expected guarded %q, got %q", i+1, "'"+v, got)
+		}
+		if got := rows[i+1][0]; got != "张三" {
+			t.Errorf("row %d: safe value altered to %q", i+1, got)
+		}
+	}
+
+	// 关闭后应原样输出
+	opts := DefaultExportOptions()
+	opts.SanitizeFormulas = false
+	if err := ExportCSVWithOptions(path, records, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+	}
+	rows = readCSVRows(t, path)
+	if got := rows[1][1]; got != dangerous[0] {
+		t.Errorf("Expected raw %q when sanitization is disabled, got %q", dangerous[0], got)
+	}
+}
+
+func TestExportExcelWithOptions_SanitizesFormulas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	records := []Record{{"defendant": "=HYPERLINK(\"http://x\")", "idNumber": "110101199001011234"}}
+	if err := ExportExcelWithOptions(path, records, DefaultExportOptions()); err != nil {
+		t.Fatalf("ExportExcelWithOptions returned error: %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+
+	got, _ := f.GetCellValue("Sheet1", "A2")
+	if got != "'=HYPERLINK(\"http://x\")" {
+		t.Errorf("Expected guarded cell, got %q", got)
+	}
+}
+
+func TestExportText(t *testing.T) {
+	records := []Record{
+		{"page": "1", "defendant": "张三", "idNumber": "110101199001011234", "request": "一、偿还借款；\n二、承担诉讼费。"},
+		{"page": "3", "defendant": "李四", "factsReason": "借款未还。"},
+	}
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := ExportText(path, records); err != nil {
+		t.Fatalf("ExportText returned error: %v", err)
+	}
+
+	report := string(readExport(t, path))
+	for _, want := range []string{
+		"案件1（第 1 页）",
+		"被告：张三",
+		"身份证号码：110101199001011234",
+		"诉讼请求：\n一、偿还借款；\n二、承担诉讼费。",
+		"案件2（第 3 页）",
+		"被告：李四",
+		"事实与理由：借款未还。",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Count(report, "案件") != len(records) {
+		t.Errorf("Expected %d case blocks:\n%s", len(records), report)
+	}
+}
+
+func TestExportJSONWithOptions_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	if err := os.WriteFile(path, []byte(`[{"defendant":"已有记录","note":"保留未知字段"}]`), 0644); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+
+	opts := DefaultExportOptions()
+	opts.Append = true
+	if err := ExportJSONWithOptions(path, sampleRecords, opts); err != nil {
+		t.Fatalf("ExportJSONWithOptions returned error: %v", err)
+	}
+
+	var got []Record
+	if err := json.Unmarshal(readExport(t, path), &got); err != nil {
+		t.Fatalf("result is not a JSON array: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 records after append, got %d", len(got))
+	}
+	if got[0]["defendant"] != "已有记录" || got[0]["note"] != "保留未知字段" {
+		t.Errorf("Existing record altered: %v", got[0])
+	}
+	if got[1]["defendant"] != "张三" {
+		t.Errorf("Appended record missing: %v", got[1])
+	}
+
+	// 文件不存在时等同于新建
+	fresh := filepath.Join(t.TempDir(), "fresh.json")
+	if err := ExportJSONWithOptions(fresh, sampleRecords, opts); err != nil {
+		t.Fatalf("append to missing file: %v", err)
+	}
+	if err := json.Unmarshal(readExport(t, fresh), &got); err != nil || len(got) != 1 {
+		t.Errorf("Expected 1 record in fresh file, got %d (err=%v)", len(got), err)
+	}
+
+	// 已有文件不是数组时应拒绝追加
+	notArray := filepath.Join(t.TempDir(), "object.json")
+	os.WriteFile(notArray, []byte(`{"defendant":"x"}`), 0644)
+	if err := ExportJSONWithOptions(notArray, sampleRecords, opts); err == nil {
+		t.Error("Expected error when appending to a non-array JSON file")
+	}
+}
+
+func TestExportExcelStyled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "styled.xlsx")
+	style := ExcelStyle{Title: "某某律师事务所", HeaderFill: "#1F4E78", FreezeHeader: true, AutoFit: true}
+	if err := ExportExcelStyled(path, sampleRecords, DefaultExportOptions(), style); err != nil {
+		t.Fatalf("ExportExcelStyled returned error: %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+
+	if got, _ := f.GetCellValue("Sheet1", "A1"); got != style.Title {
+		t.Errorf("Expected title %q in A1, got %q", style.Title, got)
+	}
+	if got, _ := f.GetCellValue("Sheet1", "A2"); got != PatternRegistry["defendant"].Label {
+		t.Errorf("Expected header row below title, got %q in A2", got)
+	}
+
+	panes, err := f.GetPanes("Sheet1")
+	if err != nil {
+		t.Fatalf("GetPanes: %v", err)
+	}
+	if !panes.Freeze || panes.YSplit != 2 || panes.TopLeftCell != "A3" {
+		t.Errorf("Expected header frozen below row 2, got %+v", panes)
+	}
+}
+
+func TestFormatSummary(t *testing.T) {
+	r := Record{"defendant": "张三", "idNumber": "110101199001011234", "request": "一、偿还借款\n二、承担诉讼费"}
+	got := FormatSummary(r, "")
+	want := "被告：张三；身份证：110101199001011234；诉讼请求：一、偿还借款 二、承担诉讼费"
+	if got != want {
+		t.Errorf("FormatSummary = %q, want %q", got, want)
+	}
+
+	if got := FormatSummary(r, "{defendant}（{idNumber}）；金额：{amount}"); got != "张三（110101199001011234）" {
+		t.Errorf("custom template: got %q", got)
+	}
+}
+
+func TestExportCSVWithOptions_Summary(t *testing.T) {
+	dir := t.TempDir()
+	opts := DefaultExportOptions()
+	opts.Summary = true
+
+	path := filepath.Join(dir, "summary.csv")
+	if err := ExportCSVWithOptions(path, sampleRecords, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+	}
+	rows := readCSVRows(t, path)
+	if want := []string{"被告", "身份证号码", "诉讼请求", "事实与理由", "摘要"}; strings.Join(rows[0], ",") != strings.Join(want, ",") {
+		t.Errorf("header: expected %v, got %v", want, rows[0])
+	}
+	if want := "被告：张三；身份证：110101199001011234；诉讼请求：偿还借款；事实与理由：借款未还"; rows[1][4] != want {
+		t.Errorf("summary: expected %q, got %q", want, rows[1][4])
+	}
+	if _, ok := sampleRecords[0][SummaryKey]; ok {
+		t.Error("exporting with a summary must not modify the input records")
+	}
+
+	opts.SummaryOnly = true
+	opts.SummaryTemplate = "{defendant}：{request}"
+	path = filepath.Join(dir, "summary_only.csv")
+	if err := ExportCSVWithOptions(path, sampleRecords, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+	}
+	rows = readCSVRows(t, path)
+	if len(rows[0]) != 1 || rows[0][0] != "摘要" || rows[1][0] != "张三：偿还借款" {
+		t.Errorf("summary only: got %v", rows)
+	}
+}
+
+func TestExportCSVWithOptions_CustomHeaders(t *testing.T) {
+	opts := DefaultExportOptions()
+	opts.Headers = EnglishHeaders
+
+	path := filepath.Join(t.TempDir(), "en.csv")
+	if err := ExportCSVWithOptions(path, sampleRecords, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+	}
+	rows := readCSVRows(t, path)
+	if want := "Defendant,ID Number,Claims,Facts and Reasons"; strings.Join(rows[0], ",") != want {
+		t.Errorf("header: expected %q, got %q", want, strings.Join(rows[0], ","))
+	}
+	if rows[1][0] != "张三" {
+		t.Errorf("data should be unchanged, got %v", rows[1])
+	}
+
+	// 只覆盖部分字段时，其余字段保留中文标签
+	opts.Headers = map[string]string{"defendant": "Party"}
+	if err := ExportCSVWithOptions(path, sampleRecords, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+	}
+	if rows := readCSVRows(t, path); rows[0][0] != "Party" || rows[0][1] != "身份证号码" {
+		t.Errorf("partial override: got %v", rows[0])
+	}
+}
+
+func TestExportCSVWithOptions_GBK(t *testing.T) {
+	opts := DefaultExportOptions()
+	opts.Encoding = EncodingGBK
+
+	path := filepath.Join(t.TempDir(), "gbk.csv")
+	if err := ExportCSVWithOptions(path, sampleRecords, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+	}
+	data := readExport(t, path)
+	if bytes.HasPrefix(data, []byte("\xEF\xBB\xBF")) {
+		t.Error("GBK output should not start with a UTF-8 BOM")
+	}
+	if utf8.Valid(data) {
+		t.Error("GBK output should not be valid UTF-8")
+	}
+
+	decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(data)
+	if err != nil {
+		t.Fatalf("decode GBK: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(decoded)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if rows[0][0] != "被告" || rows[1][0] != "张三" {
+		t.Errorf("unexpected decoded rows: %v", rows[:2])
+	}
+
+	opts.Encoding = "big5"
+	if err := ExportCSVWithOptions(path, sampleRecords, opts); err == nil {
+		t.Error("Expected error for unsupported encoding")
+	}
+}
+
+func TestExportCSVWithOptions_Long(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.csv")
+	opts := DefaultExportOptions()
+	opts.Long = true
+	if err := ExportCSVWithOptions(path, sampleRecords, opts); err != nil {
+		t.Fatalf("ExportCSVWithOptions returned error: %v", err)
+	}
+
+	rows := readCSVRows(t, path)
+	want := [][]string{
+		{"record_id", "field_key", "field_label", "value"},
+		{"1", "defendant", "被告", "张三"},
+		{"1", "idNumber", "身份证号码", "110101199001011234"},
+		{"1", "request", "诉讼请求", "偿还借款"},
+		{"1", "factsReason", "事实与理由", "借款未还"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %v", len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d: expected %q, got %q", i, want[i], rows[i])
+		}
+	}
+
+	// Excel 同样按长格式输出，并保留页码行
+	xlsx := filepath.Join(t.TempDir(), "long.xlsx")
+	if err := ExportExcelWithOptions(xlsx, []Record{{"page": "2", "defendant": "张三"}}, opts); err != nil {
+		t.Fatalf("ExportExcelWithOptions returned error: %v", err)
+	}
+	f, err := excelize.OpenFile(xlsx)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+	if got, _ := f.GetRows("Sheet1"); len(got) != 3 || got[1][1] != "page" || got[2][3] != "张三" {
+		t.Errorf("Unexpected long Excel rows: %v", got)
+	}
+}
+
+func TestExportGrouped(t *testing.T) {
+	records := []Record{
+		{"defendant": "张三", "court": "北京市朝阳区人民法院"},
+		{"defendant": "李四", "court": "上海市徐汇区人民法院"},
+		{"defendant": "王五", "court": "北京市朝阳区人民法院"},
+		{"defendant": "赵六"},
+		{"defendant": "钱七", "court": "a/b"},
+	}
+	dir := filepath.Join(t.TempDir(), "out")
+	paths, err := ExportGrouped(dir, "csv", records, ExportOptions{GroupBy: "court"})
+	if err != nil {
+		t.Fatalf("ExportGrouped: %v", err)
+	}
+
+	want := []string{"北京市朝阳区人民法院.csv", "上海市徐汇区人民法院.csv", "未分类.csv", "a_b.csv"}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %d files, got %v", len(want), paths)
+	}
+	for i, p := range paths {
+		if filepath.Base(p) != want[i] {
+			t.Errorf("file %d = %s, want %s", i, filepath.Base(p), want[i])
+		}
+	}
+
+	f, err := os.Open(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[1][0] != "张三" || rows[2][0] != "王五" {
+		t.Errorf("Expected both 朝阳区 records in the first file, got %v", rows)
+	}
+
+	if _, err := ExportGrouped(dir, "csv", records, ExportOptions{}); err == nil {
+		t.Error("Expected an error without a group field")
+	}
+}
+
+func TestExportFile_ChecksumManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xlsx")
+	records := []Record{{"defendant": "张三", "idNumber": "110101199001011234"}}
+	if err := ExportFile(path, records, ExportOptions{Checksum: true}); err != nil {
+		t.Fatalf("ExportFile: %v", err)
+	}
+
+	manifest, err := os.ReadFile(path + ChecksumExt)
+	if err != nil {
+		t.Fatalf("Expected a checksum manifest: %v", err)
+	}
+	if !strings.HasSuffix(string(manifest), "  out.xlsx\n") {
+		t.Errorf("Expected sha256sum format, got %q", manifest)
+	}
+	if ok, err := VerifyExport(path); !ok || err != nil {
+		t.Fatalf("VerifyExport = %v, %v; want true", ok, err)
+	}
+
+	// 篡改导出文件
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0})
+	f.Close()
+	if ok, err := VerifyExport(path); ok || err != nil {
+		t.Errorf("VerifyExport after tampering = %v, %v; want false, nil", ok, err)
+	}
+
+	if _, err := VerifyExport(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("Expected an error without a manifest")
+	}
+}
+
+func TestExportExcelTemplate(t *testing.T) {
+	// JSON 与 YAML 写法等价；列顺序与字段顺序不同，"承办人" 留空供手工填写
+	tmpl, err := ParseExcelTemplate([]byte(`{"sheet": "立案登记", "columns": {"诉讼请求": "request", "承办人": "", "被告姓名": "defendant", "身份证号码": "idNumber"}}`))
+	if err != nil {
+		t.Fatalf("ParseExcelTemplate returned error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "template.xlsx")
+	if err := ExportExcelTemplate(path, sampleRecords, tmpl); err != nil {
+		t.Fatalf("ExportExcelTemplate returned error: %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+
+	if sheets := f.GetSheetList(); len(sheets) != 1 || sheets[0] != "立案登记" {
+		t.Fatalf("Expected a single sheet named by the template, got %v", sheets)
+	}
+	rows, err := f.GetRows("立案登记")
+	if err != nil {
+		t.Fatalf("GetRows: %v", err)
+	}
+	want := [][]string{
+		{"诉讼请求", "承办人", "被告姓名", "身份证号码"},
+		{"偿还借款", "", "张三", "110101199001011234"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %v", len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d: expected %q, got %q", i+1, want[i], rows[i])
+		}
+	}
+
+	if _, err := ParseExcelTemplate([]byte("columns:\n  被告: defendent\n")); err == nil || !strings.Contains(err.Error(), "defendent") {
+		t.Errorf("Expected an unknown field error, got %v", err)
+	}
+}
+
+func TestExcelBatchWriter(t *testing.T) {
+	files := [][]Record{
+		{{"page": "1", "defendant": "张三", "idNumber": "110101199001011237"}},
+		{},
+		{{"page": "1", "defendant": "李四"}, {"page": "2", "defendant": "王五", "idNumber": "110101198501011234"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "batch.xlsx")
+	w := NewExcelBatchWriter(nil, DefaultExportOptions(), ExcelStyle{FreezeHeader: true})
+	defer w.Close()
+	for _, records := range files {
+		if err := w.AddRecords(records); err != nil {
+			t.Fatalf("AddRecords: %v", err)
+		}
+	}
+	if err := w.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+	got, _ := f.GetRows("Sheet1")
+	want := [][]string{
+		{"页码", "被告", "身份证号码"},
+		{"1", "张三", "110101199001011237"},
+		{"1", "李四"},
+		{"2", "王五", "110101198501011234"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+	for i := range want {
+		if strings.Join(got[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// 长格式的 record_id 跨文件连续编号
+	long := DefaultExportOptions()
+	long.Long = true
+	lw := NewExcelBatchWriter(nil, long, ExcelStyle{})
+	defer lw.Close()
+	for _, records := range files {
+		if err := lw.AddRecords(records); err != nil {
+			t.Fatalf("AddRecords: %v", err)
+		}
+	}
+	longPath := filepath.Join(t.TempDir(), "long.xlsx")
+	if err := lw.Save(longPath); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	lf, err := excelize.OpenFile(longPath)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer lf.Close()
+	rows, _ := lf.GetRows("Sheet1")
+	if last := rows[len(rows)-1]; lw.Records() != 3 || last[0] != "3" || last[3] != "110101198501011234" {
+		t.Errorf("last long row = %v (records %d), want record 3", last, lw.Records())
+	}
+}
@@ -4,31 +4,115 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
-	"crypto/sha256"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+
+	"legal-extractor/internal/config"
 
 	"github.com/dslipak/pdf"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrPDFEncrypted PDF 受密码保护，无法读取内容
+	ErrPDFEncrypted = errors.New("PDF 已加密，请先移除密码保护")
+	// ErrBridgeNotFound 扫描件需要本地识别，但找不到 Windows OCR 桥接工具
+	ErrBridgeNotFound = errors.New("找不到 Windows OCR 桥接工具 (WinOcrBridge.exe)")
+	// ErrOCRNotConfigured 图片识别需要云端识别引擎，但尚未配置
+	ErrOCRNotConfigured = errors.New("未配置云端识别引擎，无法识别图片，请检查百度 Token 配置")
 )
 
 // Extractor 处理器，负责协调不同格式的提取策略
 type Extractor struct {
-	logger      *slog.Logger
-	baiduClient *BaiduClient
-	cache       map[string][]Record
-	cacheMu     sync.RWMutex
+	// RequiredFields 记录必须具备的字段；为空时沿用默认行为 (任一字段非空即保留)
+	RequiredFields []string
+	// RequireAny 为 true 时只需具备 RequiredFields 中的任一字段，否则需全部具备
+	RequireAny bool
+	// KeepIncomplete 为 true 时不丢弃缺少必填字段的记录，而是以 IncompleteKey 标记
+	KeepIncomplete bool
+	// Strict 为 true 时任一记录缺少必填字段 (按 RequiredFields 与 RequireAny 判定) 即返回 *MissingFieldsError，
+	// 不再过滤或标记后返回其余记录，供不允许输出不完整数据的自动化流程使用；单次提取可经 WithStrict 覆盖
+	Strict bool
+	// ResolveDocxNumbering 为 true 时根据 word/numbering.xml 还原自动编号的序号 (NewExtractor 默认开启)
+	ResolveDocxNumbering bool
+	// RepeatableFields 需要提取全部出现项的字段 (支持的字段见 RepeatableFieldKeys)，多个值以 RepeatSeparator 连接；
+	// 为空时每个字段只取第一处匹配。NewExtractor 读取 extraction.repeatable_fields
+	RepeatableFields []string
+	// PreferIDDerived 为 true 时，若提取到的性别、出生日期与有效身份证号码推导出的不一致，以身份证号码为准；
+	// 无论是否开启，不一致时都会在 WarningsKey 中记录提示
+	PreferIDDerived bool
+	// SplitDefendants 为 true 时，同一文书中的多名被告 (如"被告一""被告二") 各生成一条记录，
+	// 各自的身份证号、性别、出生日期取自本人信息段，诉讼请求、事实与理由、金额等共同部分由每条记录继承
+	SplitDefendants bool
+	// FallbackOnEmpty 为 true 时，若识别引擎调用成功却未识别出任何记录，依次改用备用引擎
+	// (默认取自 baidu.fallback_api_urls，见 SetFallbackOCRProviders)，扫描版 PDF 最后再尝试本地系统识别；引擎报错时不会切换
+	FallbackOnEmpty bool
+	// ParseRawTextOnEmpty 为 true 时，识别引擎未能结构化解析出记录 (返回 NoDataError) 则先按其识别出的原始文字在本地提取，
+	// 不额外调用引擎；仍无记录时才按 FallbackOnEmpty 改用备用引擎
+	ParseRawTextOnEmpty bool
+	// Workers 本地并行解析 PDF 页面的协程数，0 表示自动 (见 DefaultWorkers)
+	Workers int
+	// BridgeTimeout 本地桥接工具识别单页的超时时间，超时后终止其进程组；0 表示 DefaultBridgeTimeout
+	BridgeTimeout time.Duration
+	// StreamOCRPages 为 true 时扫描版 PDF 逐页送识别 (切出一页、识别、释放后再处理下一页)，
+	// 而非整份文件一次编码提交，内存占用与页数无关，适用于数百页的扫描件；请求次数相应增加
+	StreamOCRPages bool
+	// Hybrid 为 true 且配置了云端识别引擎时，带文本层的 PDF 在本地解析之外再调用识别引擎，
+	// 两份结果按 FieldPriority 逐字段合并 (见 ResolveSources)。此时记录在全部完成后才输出
+	Hybrid bool
+	// FieldPriority 混合解析时各字段优先采用的来源，为 nil 时使用 DefaultFieldPriority
+	FieldPriority FieldPriority
+	// Dedupe 合并批量结果时的跨文件去重参数 (见 MergeBatch)，为 nil 时不去重
+	Dedupe *DedupeOptions
+	// Transforms 提取完成后、返回 (及导出) 之前依次对每条记录执行的后处理，先于 RequiredFields 过滤；
+	// 内置与自定义的具名后处理见 RegisterTransform、LookupTransforms
+	Transforms []Transform
+	// ScoreQuality 为 true 时为每个字段附带启发式质量评分 (见 QualityKeyPrefix、FieldQuality)，
+	// 低于 QualityThreshold 的字段列入 LowQualityKey，便于挑出识别乱码人工核对
+	ScoreQuality bool
+	// QualityThreshold 低质量阈值，0 表示 DefaultQualityThreshold
+	QualityThreshold float64
+	// Deskew 为 true 时，图片 (含邮件中的图片附件) 在送识别前先于本地检测并校正倾斜，校正角度记入 DeskewKey。
+	// 较耗时，默认关闭；扫描版 PDF 不在本地栅格化，其方向校正依赖识别引擎 (见 baidu.orientation_classify)
+	Deskew bool
+	// MinTextChars 页面至少包含多少个非空白字符才视为文本层，0 表示 DefaultMinTextChars
+	MinTextChars int
+	// IncludeProvenance 为 true 时在每条记录中写入来源信息：源文件名、内容哈希、页码与提取时间
+	// (见 SourceFileKey 等)，合并导出多个文件的结果后仍可追溯每条记录的出处
+	IncludeProvenance bool
+	// PDFText 读取 PDF 文本层的后端，为 nil 时使用纯 Go 实现的 GoPDFTextExtractor
+	PDFText PDFTextExtractor
+	// TextCacheTTL 提取结果与原文 (供 ReparseFields 使用) 在内存中保留的时间，0 表示 DefaultTextCacheTTL。
+	// 两者同时过期，命中结果缓存时总能重新解析
+	TextCacheTTL time.Duration
+	// TextCacheSize 最多缓存多少份文档的提取结果与原文，0 表示 DefaultTextCacheSize
+	TextCacheSize int
+
+	logger     *slog.Logger
+	patterns   *ExtractionPatterns    // 本地解析规则：DefaultPatterns 的副本，含配置覆盖，创建后不再修改
+	prefixes   fieldPrefixes          // 提取完成后去除的各字段标注 (见 SetFieldPrefixes)
+	ocr        OCRProvider            // 云端识别引擎，为 nil 时扫描件回退至本地系统识别
+	backups    []OCRProvider          // FallbackOnEmpty 时依次尝试的备用引擎
+	cache      map[string]*cacheEntry // 提取结果与原文 (按文件内容哈希)，供重复提取与 ReparseFields 使用
+	cacheOrder []string               // cache 的写入顺序，超出容量时淘汰最早的条目
+	cacheMu    sync.RWMutex
+	usage      *UsageCounter // 识别调用量统计，为 nil 时不统计
+	audit      AuditSink     // 审计记录器，为 nil 时不记录
 }
 
 // NewExtractor 创建一个新的提取器实例
@@ -36,11 +120,103 @@ func NewExtractor(logger *slog.Logger) *Extractor {
 	if logger == nil {
 		logger = slog.Default()
 	}
-	return &Extractor{
-		logger:      logger,
-		baiduClient: NewBaiduClient(logger),
-		cache:       make(map[string][]Record),
+	e := &Extractor{
+		ResolveDocxNumbering: true,
+		logger:               logger,
+		cache:                make(map[string]*cacheEntry),
+		prefixes:             defaultFieldPrefixes,
+	}
+
+	e.patterns = newPatterns(config.Get(), logger)
+	if err := e.SetFieldPrefixes(config.Get().Extraction.FieldPrefixes); err != nil {
+		logger.Warn("字段标注配置有误，已忽略无效条目", "error", err)
+	}
+	e.RequiredFields = config.Get().Extraction.RequiredFields
+	e.RequireAny = config.Get().Extraction.RequireAny
+	e.Strict = config.Get().Extraction.Strict
+	e.Workers = config.Get().Extraction.Workers
+	e.MinTextChars = config.Get().Extraction.MinTextChars
+	e.Deskew = config.Get().Extraction.Deskew
+	e.IncludeProvenance = config.Get().Extraction.IncludeProvenance
+	e.TextCacheTTL = config.Get().Extraction.TextCacheTTL
+	e.TextCacheSize = config.Get().Extraction.TextCacheSize
+	e.audit = AuditSinkFromConfig(logger)
+	e.BridgeTimeout = config.Get().OCR.BridgeTimeout
+	e.StreamOCRPages = config.Get().OCR.StreamPages
+	e.ParseRawTextOnEmpty = config.Get().OCR.ParseRawTextOnEmpty
+	e.FallbackOnEmpty = config.Get().OCR.FallbackOnEmpty
+	e.Hybrid = config.Get().Extraction.Hybrid
+	e.ScoreQuality = config.Get().Extraction.QualityCheck
+	e.QualityThreshold = config.Get().Extraction.QualityThreshold
+	priority, err := FieldPriorityFromConfig()
+	if err != nil {
+		logger.Warn("字段优先级配置有误，已忽略无效条目", "error", err)
+	}
+	e.FieldPriority = priority
+	e.Dedupe = DedupeOptionsFromConfig()
+	for _, f := range config.Get().Extraction.RepeatableFields {
+		if !slices.Contains(RepeatableFieldKeys, f) {
+			logger.Warn("extraction.repeatable_fields 中的字段不支持提取全部出现项，已忽略", "field", f, "supported", RepeatableFieldKeys)
+			continue
+		}
+		e.RepeatableFields = append(e.RepeatableFields, f)
+	}
+	if names := config.Get().Extraction.Transforms; len(names) > 0 {
+		list, err := LookupTransforms(names)
+		if err != nil {
+			logger.Warn("后处理配置有误，已全部忽略", "error", err)
+		}
+		e.Transforms = list
+	}
+
+	switch config.Get().OCR.Provider {
+	case "mock":
+		logger.Info("使用 [模拟识别引擎]，不会访问云端服务")
+		e.ocr = NewMockOCRProvider()
+	default:
+		if baidu := NewBaiduClient(logger); baidu.config.Token != "" {
+			e.ocr = baidu
+			e.backups = baidu.fallbackClients()
+		}
+	}
+	return e
+}
+
+// newPatterns 复制 DefaultPatterns 并应用 extraction 与 ocr.field_aliases 中覆盖内置解析规则的配置。每个提取器使用各自的副本，
+// 创建提取器不会影响其他提取器正在使用的规则
+func newPatterns(cfg *config.Config, logger *slog.Logger) *ExtractionPatterns {
+	p := DefaultPatterns
+	ext := cfg.Extraction
+	p.SetDefendantStopKeywords(ext.DefendantStopKeywords)
+	p.SetDefendantMaxRunes(ext.DefendantMaxRunes)
+	if err := p.SetSectionEndAnchors(ext.SectionEndAnchors); err != nil {
+		logger.Warn("段落结尾标记配置有误，已使用内置标记", "error", err)
+	}
+	p.SetLooseIDMatch(!ext.StrictIDLabel)
+	if err := p.SetNameWhitespace(ext.NameWhitespace); err != nil {
+		logger.Warn("姓名空白处理方式配置有误，已使用默认的 compact", "error", err)
 	}
+	if err := p.SetFieldAliases(cfg.OCR.FieldAliases); err != nil {
+		logger.Warn("字段别名配置有误，已忽略无效条目", "error", err)
+	}
+	return &p
+}
+
+// ActivePatterns 返回该提取器当前生效的提取正则 (含配置覆盖)，便于排查字段为何未匹配
+func (e *Extractor) ActivePatterns() ActivePatterns {
+	return e.patterns.active()
+}
+
+// SetOCRProvider 替换云端识别引擎 (传入 nil 表示禁用云端识别)
+func (e *Extractor) SetOCRProvider(p OCRProvider) {
+	e.ocr = p
+	e.shareUsage(p)
+}
+
+// SetFallbackOCRProviders 设置 FallbackOnEmpty 时依次尝试的备用识别引擎，替换按 baidu.fallback_api_urls 创建的备用引擎
+func (e *Extractor) SetFallbackOCRProviders(providers ...OCRProvider) {
+	e.backups = providers
+	e.shareUsage(providers...)
 }
 
 // Logger 返回提取器的日志记录器
@@ -48,38 +224,217 @@ func (e *Extractor) Logger() *slog.Logger {
 	return e.logger
 }
 
+// Providers 返回当前已配置可用的 OCR 引擎名称 (不含任何密钥信息)
+func (e *Extractor) Providers() []string {
+	providers := []string{}
+	if e.ocr != nil {
+		providers = append(providers, e.ocr.Name())
+	}
+	if runtime.GOOS == "windows" {
+		if _, err := findWinOcrBridge(); err == nil {
+			providers = append(providers, "winocr")
+		}
+	}
+	return providers
+}
+
 // Record 代表一条提取的记录
 type Record map[string]string
 
+// RepeatSeparator 连接可重复字段多个取值的分隔符
+const RepeatSeparator = "、"
+
+// RepeatableFieldKeys 可通过 RepeatableFields 提取全部出现项的字段
+var RepeatableFieldKeys = []string{"plaintiff", "plaintiffPhone", "defendant", "thirdParty", "idNumber"}
+
+// IncompleteKey 标记缺少必填字段的记录 (以下划线开头的键为元数据，不参与表格导出)
+const IncompleteKey = "_incomplete"
+
 // ProgressCallback 进度回调函数
 type ProgressCallback func(current, total int, message string)
 
+// SupportedExtensions 返回 ExtractData 支持的文档扩展名 (小写，含点)
+func SupportedExtensions() []string {
+	return []string{".pdf", ".docx", ".eml"}
+}
+
+// ImageExtensions 返回 ExtractImage 支持的图片扩展名，识别图片需要已配置云端识别引擎
+func ImageExtensions() []string {
+	return []string{".jpg", ".jpeg", ".png"}
+}
+
+// derivedFieldKeys 不能单独选择提取的列：页码、由金额解析出的数值与币种、导出时生成的摘要与来源信息
+var derivedFieldKeys = []string{"page", "amountValue", "amountCurrency", "summary", "sourceFile", "sourceHash", "sourcePage", "extractedAt"}
+
+// FieldKeys 返回可选择提取的字段键，按导出的列顺序排列；派生列见 DerivedFieldKeys
+func FieldKeys() []string {
+	var keys []string
+	for _, k := range exportFieldOrder {
+		if !slices.Contains(derivedFieldKeys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// DerivedFieldKeys 返回由其他字段或导出选项派生的列键，按导出的列顺序排列
+func DerivedFieldKeys() []string {
+	return slices.Clone(derivedFieldKeys)
+}
+
 // ExtractData 根据文件类型选择提取策略
 func (e *Extractor) ExtractData(fileData []byte, fileName string, fields []string, onProgress ProgressCallback) ([]Record, error) {
+	return e.ExtractDataContext(context.Background(), fileData, fileName, fields, onProgress)
+}
+
+// ExtractDataContext 同 ExtractData，ctx 用于串联调用方的追踪上下文 (如 HTTP 请求头中的 traceparent)
+func (e *Extractor) ExtractDataContext(ctx context.Context, fileData []byte, fileName string, fields []string, onProgress ProgressCallback) (finished []Record, err error) {
+	ctx, audit := e.auditTimer(ctx, fileData, fileName)
+	defer func() { audit(finished, err) }()
+
+	records, err := e.extractData(ctx, fileData, fileName, fields, onProgress, nil)
+	if err != nil {
+		return nil, err
+	}
+	finished, err = e.finishRecords(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+	return e.newProvenance(fileData, fileName).stamp(finished), nil
+}
+
+// ExtractStream 同 ExtractDataContext，但边解析边输出记录：带文本层的 PDF 每解析完一页即按页码顺序发送该页的记录，
+// 其他文档 (DOCX、扫描件) 在解析完成后依次发送。两个通道在结束时关闭；提取失败或 ctx 取消时
+// 错误通道先发送一个错误 (取消时为 ctx.Err())，调用方应持续读取记录通道直至关闭
+func (e *Extractor) ExtractStream(ctx context.Context, fileData []byte, fileName string, fields []string) (<-chan Record, <-chan error) {
+	out := make(chan Record)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(out)
+
+		sent := 0    // 已发送的原始记录数 (RequiredFields 过滤前)
+		checked := 0 // 已通过严格模式校验的记录数，用于将缺失字段的序号换算为整份文档中的序号
+		var strictErr error
+		var emitted []Record // 已输出的记录，用于审计
+		prov := e.newProvenance(fileData, fileName)
+		ctx, audit := e.auditTimer(ctx, fileData, fileName)
+		send := func(records []Record) {
+			finished, err := e.finishRecords(ctx, records)
+			if err != nil {
+				// 严格模式下此前各批的记录均完整且全部保留，序号顺延即为整份文档中的序号
+				var missing *MissingFieldsError
+				if errors.As(err, &missing) {
+					for i := range missing.Records {
+						missing.Records[i].Index += checked
+					}
+				}
+				strictErr = err
+				return
+			}
+			checked += len(finished)
+			for _, r := range prov.stamp(finished) {
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case out <- r:
+					emitted = append(emitted, r)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		var streamErr error
+		defer func() { audit(emitted, streamErr) }()
+		fail := func(err error) {
+			streamErr = err
+			errs <- err
+		}
+
+		// 严格模式下出现不完整的记录后不再输出，并取消剩余页面的解析
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		sink := func(records []Record) {
+			sent += len(records)
+			if strictErr == nil {
+				send(records)
+			}
+			if strictErr != nil {
+				cancel()
+			}
+		}
+
+		records, err := e.extractData(streamCtx, fileData, fileName, fields, nil, sink)
+		if strictErr != nil {
+			fail(strictErr)
+			return
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			fail(err)
+			return
+		}
+		// 未逐页输出的部分 (如 DOCX、识别引擎的结果、缓存命中) 一并发送
+		if sent < len(records) {
+			send(records[sent:])
+		}
+		if strictErr != nil {
+			fail(strictErr)
+		} else if err := ctx.Err(); err != nil {
+			fail(err)
+		}
+	}()
+	return out, errs
+}
+
+// recordSink 接收按文档顺序逐批解析出的原始记录 (未经 RequiredFields 过滤)
+type recordSink func(records []Record)
+
+// extractData 提取并缓存原始记录；sink 非 nil 时，支持逐页解析的路径会边解析边把记录交给 sink
+func (e *Extractor) extractData(ctx context.Context, fileData []byte, fileName string, fields []string, onProgress ProgressCallback, sink recordSink) (records []Record, err error) {
 	e.logger.Info("开始提取数据", "file", fileName, "size", len(fileData), "fields", fields)
 	ext := strings.ToLower(filepath.Ext(fileName))
 
+	ctx, span := startSpan(ctx, "extractor.ExtractData",
+		attribute.String("file.ext", ext),
+		attribute.Int("file.size", len(fileData)),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("extractor.record_count", len(records)))
+		endSpan(span, err)
+	}()
+
+	// 提取路径随结果写入缓存，调用方未记录路径时也需要
+	route := routeFrom(ctx)
+	if route == nil {
+		ctx, route = withRoute(ctx)
+	}
+
 	// 1. 检查缓存 (使用文件内容的 SHA256 哈希作为 Key)
 	fileHash := e.calculateHash(fileData)
-	e.cacheMu.RLock()
-	if cached, ok := e.cache[fileHash]; ok {
+	if cached, ok := e.cachedEntry(fileHash); ok && cached.records != nil {
 		e.logger.Info("命中内容哈希缓存，跳过提取", "file", fileName, "hash", fileHash[:8])
-		e.cacheMu.RUnlock()
-		return cached, nil
+		span.SetAttributes(attribute.Bool("extractor.cache_hit", true))
+		route.hit(cached.path)
+		return cached.records, nil
 	}
-	e.cacheMu.RUnlock()
-
-	var records []Record
-	var err error
 
 	switch ext {
 	case ".pdf":
-		records, err = e.extractPdf(fileData, fields, onProgress)
+		records, err = e.extractPdf(ctx, fileData, fields, onProgress, sink)
 	case ".jpg", ".png", ".jpeg":
 		return nil, fmt.Errorf("图片识别功能已暂时禁用（仅支持PDF）")
 	case ".docx":
 		e.logger.Info("使用本地原生逻辑提取 DOCX", "file", fileName)
+		route.use(PlanNative)
 		records, err = e.extractFromDocx(fileData, fields)
+	case ".eml":
+		e.logger.Info("解析邮件附件", "file", fileName)
+		records, err = e.extractEmail(ctx, fileData, fields, onProgress)
 	default:
 		return nil, fmt.Errorf("不支持的文件格式: %s", ext)
 	}
@@ -88,122 +443,537 @@ func (e *Extractor) ExtractData(fileData []byte, fileName string, fields []strin
 		return nil, err
 	}
 
-	// 2. 写入缓存 (仅当结果非空时)
+	// 2. 写入缓存 (仅当结果非空时)，与提取时缓存的原文同时过期
 	if len(records) > 0 {
-		e.cacheMu.Lock()
-		e.cache[fileHash] = records
-		e.cacheMu.Unlock()
+		e.updateCache(fileHash, func(c *cacheEntry) { c.records, c.path = records, route.path })
 	}
 
 	return records, nil
 }
 
+// ExtractText 直接解析已识别好的纯文本 (如用户粘贴的 OCR 结果)，不涉及任何文件处理；fields 为空时提取全部字段
+func (e *Extractor) ExtractText(text string, fields []string) ([]Record, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("文本内容为空")
+	}
+	if len(fields) == 0 {
+		for k := range PatternRegistry {
+			fields = append(fields, k)
+		}
+	}
+	e.logger.Info("解析纯文本", "size", len(text), "fields", fields)
+	return e.finishRecords(context.Background(), e.parseCases(text, fields))
+}
+
+// ExtractImage 识别单张图片 (如剪贴板截图) 中的案件信息，直接交由云端识别引擎处理
+func (e *Extractor) ExtractImage(imageData []byte, fields []string, onProgress ProgressCallback) ([]Record, error) {
+	return e.ExtractImageContext(context.Background(), imageData, fields, onProgress)
+}
+
+// ExtractImageContext 同 ExtractImage，ctx 用于串联调用方的追踪上下文并在取消时中止识别请求
+func (e *Extractor) ExtractImageContext(ctx context.Context, imageData []byte, fields []string, onProgress ProgressCallback) ([]Record, error) {
+	if len(imageData) == 0 {
+		return nil, fmt.Errorf("图片内容为空")
+	}
+	if e.ocr == nil {
+		return nil, ErrOCRNotConfigured
+	}
+
+	e.logger.Info("使用 [云端识别引擎] 识别图片", "provider", e.ocr.Name(), "size", len(imageData))
+	records, err := e.ocrImage(ctx, imageData, onProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.finishRecords(ctx, keepFields(records, fields))
+}
+
+// keepFields 识别引擎总是返回全部字段，这里只保留调用方选择的字段 (元数据字段除外)；fields 为空时不做处理
+func keepFields(records []Record, fields []string) []Record {
+	if len(fields) == 0 {
+		return records
+	}
+	keep := make(map[string]bool)
+	for _, f := range fields {
+		keep[f] = true
+	}
+	for _, r := range records {
+		for k := range r {
+			if !keep[k] && !strings.HasPrefix(k, "_") {
+				delete(r, k)
+			}
+		}
+	}
+	return records
+}
+
+// ScanFieldKeys 字段扫描的候选字段 (按界面展示顺序)
+var ScanFieldKeys = []string{"plaintiff", "defendant", "idNumber", "creditCode", "thirdParty", "request", "amount", "factsReason"}
+
+// scanPdfPages 字段扫描时最多读取的 PDF 文本页数
+const scanPdfPages = 3
+
+// ScanFields 轻量扫描文档中实际存在的字段，不调用云端识别，用于构建字段选择界面。
+// DOCX 与带文本层的 PDF 按正文内容判断；可读但无法判断的文档 (扫描件、未识别出任何字段) 返回全部候选字段。
+// 加密 PDF 返回 ErrPDFEncrypted，扫描件既无云端引擎也无本地桥接工具时返回 ErrBridgeNotFound。
+func (e *Extractor) ScanFields(fileData []byte, fileName string) ([]string, error) {
+	var text string
+	switch ext := strings.ToLower(filepath.Ext(fileName)); ext {
+	case ".docx":
+		t, err := extractTextFromDocx(fileData, e.ResolveDocxNumbering)
+		if err != nil {
+			return nil, err
+		}
+		text = t
+	case ".pdf":
+		t, err := e.scanPdfText(fileData)
+		if err != nil {
+			return nil, err
+		}
+		text = t
+	case ".jpg", ".png", ".jpeg", ".eml":
+		return ScanFieldKeys, nil
+	default:
+		return nil, fmt.Errorf("不支持的文件格式: %s", ext)
+	}
+
+	present := make(map[string]bool)
+	for _, r := range e.parseCases(text, ScanFieldKeys) {
+		for k, v := range r {
+			if v != "" {
+				present[k] = true
+			}
+		}
+	}
+
+	var found []string
+	for _, k := range ScanFieldKeys {
+		if present[k] {
+			found = append(found, k)
+		}
+	}
+	if len(found) == 0 {
+		e.logger.Info("未能从正文判断字段，返回全部候选字段", "file", fileName)
+		return ScanFieldKeys, nil
+	}
+	return found, nil
+}
+
+// strictKey WithStrict 在 ctx 中保存的键
+type strictKey struct{}
+
+// WithStrict 返回为本次提取覆盖 Extractor.Strict 的 ctx，供按请求开关严格模式 (如 Web 服务的 strict 参数)
+func WithStrict(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, strictKey{}, strict)
+}
+
+// strictFor 返回本次提取是否为严格模式：ctx 经 WithStrict 指定时以其为准，否则为 e.Strict
+func (e *Extractor) strictFor(ctx context.Context) bool {
+	if strict, ok := ctx.Value(strictKey{}).(bool); ok {
+		return strict
+	}
+	return e.Strict
+}
+
+// finishRecords 对提取结果依次执行字段标注清理 (见 stripFieldPrefixes)、质量评分、Transforms 后处理与 RequiredFields 过滤；
+// 严格模式 (见 strictFor) 下有记录缺少必填字段时返回 *MissingFieldsError
+func (e *Extractor) finishRecords(ctx context.Context, records []Record) ([]Record, error) {
+	records = e.prefixes.stripFieldPrefixes(records)
+	if e.ScoreQuality {
+		threshold := e.QualityThreshold
+		if threshold <= 0 {
+			threshold = DefaultQualityThreshold
+		}
+		records = scoreRecords(records, threshold)
+	}
+	records = applyTransforms(records, e.Transforms)
+	if e.strictFor(ctx) {
+		if err := e.checkRequiredFields(records); err != nil {
+			return nil, err
+		}
+	}
+	return e.applyRequiredFields(records), nil
+}
+
+// MissingFieldsError 严格模式下有记录缺少必填字段
+type MissingFieldsError struct {
+	Records []MissingFields
+}
+
+// MissingFields 一条不完整的记录及其缺少的必填字段
+type MissingFields struct {
+	// Index 记录在本次结果 (流式输出时为整份文档) 中的序号，从 1 开始
+	Index int `json:"index"`
+	// Page 记录所在页码，未知时为空
+	Page   string   `json:"page,omitempty"`
+	Fields []string `json:"fields"`
+}
+
+func (err *MissingFieldsError) Error() string {
+	parts := make([]string, 0, len(err.Records))
+	for _, m := range err.Records {
+		where := fmt.Sprintf("第 %d 条记录", m.Index)
+		if m.Page != "" {
+			where += fmt.Sprintf(" (第 %s 页)", m.Page)
+		}
+		parts = append(parts, where+"缺少 "+strings.Join(m.Fields, "、"))
+	}
+	return fmt.Sprintf("%d 条记录缺少必填字段: %s", len(err.Records), strings.Join(parts, "；"))
+}
+
+// checkRequiredFields 严格模式的校验：列出缺少必填字段的全部记录
+func (e *Extractor) checkRequiredFields(records []Record) error {
+	var missing []MissingFields
+	for i, rec := range records {
+		if fields := e.missingRequired(rec); len(fields) > 0 {
+			missing = append(missing, MissingFields{Index: i + 1, Page: rec["page"], Fields: fields})
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &MissingFieldsError{Records: missing}
+}
+
+// missingRequired 返回记录缺少的必填字段，记录完整时返回 nil。
+// RequireAny 时只要具备任一字段即为完整，否则列出全部 RequiredFields
+func (e *Extractor) missingRequired(rec Record) []string {
+	var missing []string
+	for _, f := range e.RequiredFields {
+		if strings.TrimSpace(rec[f]) == "" {
+			missing = append(missing, f)
+		}
+	}
+	if e.RequireAny && len(missing) < len(e.RequiredFields) {
+		return nil
+	}
+	return missing
+}
+
+// applyRequiredFields 按 RequiredFields 过滤或标记不完整的记录
+// 缓存中保存的是原始结果，这里只在需要标记时复制记录，避免污染缓存
+func (e *Extractor) applyRequiredFields(records []Record) []Record {
+	if len(e.RequiredFields) == 0 {
+		return records
+	}
+
+	var kept []Record
+	for _, rec := range records {
+		switch {
+		case e.missingRequired(rec) == nil:
+			kept = append(kept, rec)
+		case e.KeepIncomplete:
+			flagged := make(Record, len(rec)+1)
+			for k, v := range rec {
+				flagged[k] = v
+			}
+			flagged[IncompleteKey] = "true"
+			kept = append(kept, flagged)
+		default:
+			e.logger.Debug("丢弃缺少必填字段的记录", "page", rec["page"])
+		}
+	}
+	return kept
+}
+
 // calculateHash 计算文件内容的 SHA256 哈希值
 func (e *Extractor) calculateHash(data []byte) string {
-	hash := sha256.Sum256(data)
-	return fmt.Sprintf("%x", hash)
+	return FileHash(data)
 }
 
 // extractPdf 处理 PDF 提取（优先本地提取文本层）
-func (e *Extractor) extractPdf(fileData []byte, fields []string, onProgress ProgressCallback) ([]Record, error) {
+func (e *Extractor) extractPdf(ctx context.Context, fileData []byte, fields []string, onProgress ProgressCallback, sink recordSink) ([]Record, error) {
 	e.logger.Info("正在解析 PDF 结构...", "bytes", len(fileData))
 
 	// 1. 获取总页数 (增加多库回退逻辑以提高鲁棒性)
-	totalPages := 1
-	e.logger.Debug("尝试使用 dslipak/pdf 获取页数")
-	r, err := pdf.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+	doc, totalPages := e.openPdf(fileData)
+
+	// 2. 探测前几页文本层 (带超时保护，防止复杂 PDF 导致挂起)
+	e.logger.Info("正在尝试提取前几页文本层以判断解析模式...")
+	textLayer := e.probeTextLayer(doc, totalPages)
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("pdf.page_count", totalPages))
+	route := routeFrom(ctx)
+	route.addPages(totalPages)
+	if textLayer {
+		e.logger.Info("检测到 PDF 文本层，切换至 [本地高速解析] 模式")
+		route.use(PlanNative)
+		if e.Hybrid && e.ocr != nil {
+			// 合并需要两份完整结果，不逐页输出
+			sink = nil
+		}
+		_, span := startSpan(ctx, "extractor.LocalText", attribute.Int("pdf.page_count", totalPages))
+		records, err := e.batchExtractLocalPdf(ctx, fileData, fields, totalPages, onProgress, sink)
+		endSpan(span, err)
+		if err != nil || !e.Hybrid || e.ocr == nil {
+			return records, err
+		}
+		records = e.resolveHybrid(ctx, fileData, totalPages, records, onProgress)
+		// 合并结果无法仅凭一份原文复现
+		e.forgetText(fileData)
+		return records, nil
+	}
+
+	e.logger.Info("未检测到 PDF 文本层或文本过少，切换至 [云端识别] 模式")
+
+	// 3. 如果配置了云端引擎 (默认为百度 PaddleOCR-VL Layout Parsing)，则优先使用
+	if e.ocr != nil {
+		e.logger.Info("使用 [云端识别引擎] 进行解析", "provider", e.ocr.Name())
+		return e.ocrWithFallback(ctx, fileData, true, totalPages, onProgress)
+	}
+
+	e.logger.Info("未配置云端识别引擎，回退至 [本地系统识别] 模式")
+	route.use(PlanBridge)
+	route.recognized("winocr", totalPages)
+	return e.extractViaWinOcr(ctx, fileData, totalPages, onProgress)
+}
+
+// openPdf 打开 PDF 文本层并获取总页数；文本层后端无法解析时回退到 pdfcpu 计数，此时 doc 为 nil。
+// 两者均失败时按 1 页处理
+func (e *Extractor) openPdf(fileData []byte) (PDFTextDocument, int) {
+	backend := e.pdfText()
+	e.logger.Debug("尝试使用文本层后端获取页数", "backend", backend.Name())
+	doc, err := backend.Open(fileData)
+	if err == nil && doc.NumPage() > 0 {
+		e.logger.Info("文本层后端解析成功", "backend", backend.Name(), "totalPages", doc.NumPage())
+		return doc, doc.NumPage()
+	}
 	if err == nil {
-		totalPages = r.NumPage()
-		e.logger.Info("dslipak/pdf 解析成功", "totalPages", totalPages)
-	} else {
-		e.logger.Warn("dslipak/pdf 解析失败，尝试回退到 pdfcpu", "error", err)
-		// 回退到 pdfcpu
-		pageCount, err := api.PageCount(bytes.NewReader(fileData), nil)
-		if err == nil {
-			totalPages = pageCount
-			e.logger.Info("pdfcpu 解析成功", "totalPages", totalPages)
-		} else {
-			e.logger.Error("所有 PDF 库解析页数均失败", "error", err)
+		// 部分合并生成的 PDF 页面树无法被文本层后端识别，页数以 pdfcpu 为准，文本层仍可按页读取
+		if pageCount, countErr := api.PageCount(bytes.NewReader(fileData), nil); countErr == nil && pageCount > 0 {
+			e.logger.Info("文本层后端未识别出页面，页数以 pdfcpu 为准", "backend", backend.Name(), "totalPages", pageCount)
+			return doc, pageCount
 		}
+		return doc, 0
 	}
 
-	// 2. 探测第一页文本层 (带超时保护，防止复杂 PDF 导致挂起)
-	e.logger.Info("正在尝试提取第一页文本层以判断解析模式...")
+	e.logger.Warn("文本层后端解析失败，尝试回退到 pdfcpu", "backend", backend.Name(), "error", err)
+	pageCount, err := api.PageCount(bytes.NewReader(fileData), nil)
+	if err != nil {
+		e.logger.Error("所有 PDF 库解析页数均失败", "error", err)
+		return nil, 1
+	}
+	e.logger.Info("pdfcpu 解析成功", "totalPages", pageCount)
+	return nil, pageCount
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+// probeTextLayer 判断 PDF 是否按文本 PDF 本地解析：前 textProbePages 页中任一页文字足够即是，
+// 避免只有标题的封面页把整份文档送去识别。探测超过 2 秒 (复杂 PDF 可能挂起) 时按扫描件处理
+func (e *Extractor) probeTextLayer(doc PDFTextDocument, totalPages int) bool {
+	if doc == nil {
+		return false
+	}
+	probeCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	textChan := make(chan string, 1)
+	found := make(chan bool, 1)
 	go func() {
-		t, _ := e.extractPageTextLocally(fileData, 1)
-		textChan <- t
+		for page := 1; page <= min(totalPages, textProbePages); page++ {
+			t, _ := doc.PageText(page)
+			if e.hasTextLayer(t) {
+				found <- true
+				return
+			}
+		}
+		found <- false
 	}()
 
-	var firstPageText string
 	select {
-	case firstPageText = <-textChan:
+	case textLayer := <-found:
 		e.logger.Debug("文本层探测完成")
-	case <-ctx.Done():
+		return textLayer
+	case <-probeCtx.Done():
 		e.logger.Warn("文本层探测超时，自动切换至 OCR 模式")
+		return false
 	}
+}
 
-	if len(strings.TrimSpace(firstPageText)) > 20 {
-		e.logger.Info("检测到 PDF 文本层，切换至 [本地高速解析] 模式")
-		return e.batchExtractLocalPdf(fileData, fields, totalPages, onProgress)
+// resolveHybrid 对已在本地解析的 PDF 再调用识别引擎，按字段优先级合并；识别失败时保留本地结果
+func (e *Extractor) resolveHybrid(ctx context.Context, fileData []byte, totalPages int, local []Record, onProgress ProgressCallback) []Record {
+	e.logger.Info("混合解析：调用 [云端识别引擎] 补充识别", "provider", e.ocr.Name())
+	// 混合解析的路径仍为本地解析，只记录识别引擎与页数
+	hybridCtx, sub := withRoute(ctx)
+	ocrRecords, err := e.ocrWithFallback(hybridCtx, fileData, true, totalPages, onProgress)
+	routeFrom(ctx).recognized(sub.provider, sub.ocrPages)
+	if err != nil {
+		e.logger.Warn("混合解析的识别调用失败，仅使用文本层结果", "error", err)
+		return local
+	}
+	priority := e.FieldPriority
+	if priority == nil {
+		priority = DefaultFieldPriority()
 	}
+	return ResolveSources(local, ocrRecords, priority)
+}
 
-	e.logger.Info("未检测到 PDF 文本层或文本过少，切换至 [云端识别] 模式")
+// ocrWithFallback 调用云端识别引擎。引擎成功但没有解析出记录时依次尝试：开启 ParseRawTextOnEmpty 时按识别出的原始文字
+// 在本地提取 (不再调用引擎)；开启 FallbackOnEmpty 时改用备用引擎，扫描版 PDF 最后尝试本地系统识别 (桥接工具存在时)。
+// 各步骤都未得到记录时返回空结果，引擎报错时不会切换
+func (e *Extractor) ocrWithFallback(ctx context.Context, fileData []byte, isPdf bool, pages int, onProgress ProgressCallback) ([]Record, error) {
+	providers := []OCRProvider{e.ocr}
+	if e.FallbackOnEmpty {
+		providers = append(providers, e.backups...)
+	}
 
-	// 3. 如果配置了百度 Token，则优先使用百度 PaddleOCR-VL (Layout Parsing)
-	if e.baiduClient.config.Token != "" {
-		e.logger.Info("使用 [百度云端引擎] 进行解析")
-		return e.baiduClient.ParseDocument(fileData, true, onProgress)
+	route := routeFrom(ctx)
+	path := PlanOCR
+	defer func() { route.use(path) }()
+
+	var records []Record
+	for i, p := range providers {
+		route.recognized(p.Name(), pages)
+		var text []OCRPage
+		var err error
+		if isPdf && e.StreamOCRPages && pages > 1 {
+			records, text, err = e.parsePagesWithProvider(ctx, p, fileData, pages, onProgress)
+		} else {
+			records, text, err = e.parseWithProvider(ctx, p, fileData, isPdf, pages, onProgress)
+		}
+		var noData *NoDataError
+		if errors.As(err, &noData) {
+			err = nil
+			if e.ParseRawTextOnEmpty && noData.hasText() {
+				if local := e.parseRawText(noData); len(local) > 0 {
+					e.logger.Info("识别引擎未解析出记录，已按原始文字在本地提取", "provider", p.Name(), "recordCount", len(local))
+					route.parsedRawText()
+					return local, nil
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			if i > 0 {
+				e.logger.Info("备用识别引擎识别出记录", "provider", p.Name(), "recordCount", len(records))
+			}
+			if text != nil {
+				e.cacheText(fileData, text, nil)
+			}
+			return records, nil
+		}
+		if e.FallbackOnEmpty {
+			e.logger.Warn("识别引擎未识别出任何记录，尝试下一个引擎", "provider", p.Name())
+		}
 	}
 
-	e.logger.Info("未配置百度 Token，回退至 [本地系统识别] 模式")
-	return e.extractViaWinOcr(fileData, totalPages, onProgress)
+	if e.FallbackOnEmpty && isPdf {
+		if _, err := findWinOcrBridge(); err == nil {
+			path = PlanBridge
+			route.recognized("winocr", pages)
+			records, err := e.extractViaWinOcr(ctx, fileData, pages, onProgress)
+			if err == nil && len(records) > 0 {
+				e.logger.Info("本地系统识别识别出记录", "provider", "winocr", "recordCount", len(records))
+			}
+			return records, err
+		}
+	}
+	return records, nil
 }
 
-// extractPageTextLocally 本地提取指定页码的文本
-func (e *Extractor) extractPageTextLocally(fileData []byte, pageNum int) (string, error) {
-	r, err := pdf.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+// scanPdfText 读取 PDF 前几页的文本层，供字段扫描使用；扫描件返回空串
+func (e *Extractor) scanPdfText(fileData []byte) (string, error) {
+	doc, err := e.pdfText().Open(fileData)
 	if err != nil {
-		return "", err
+		if isPdfEncryptionError(err) {
+			return "", ErrPDFEncrypted
+		}
+		// 文本层后端无法解析时用 pdfcpu 确认文档是否可读
+		if _, cpuErr := api.PageCount(bytes.NewReader(fileData), nil); cpuErr != nil {
+			if isPdfEncryptionError(cpuErr) {
+				return "", ErrPDFEncrypted
+			}
+			return "", fmt.Errorf("无法读取 PDF: %w", err)
+		}
+		return "", nil
 	}
 
-	if pageNum > r.NumPage() {
-		return "", fmt.Errorf("页码 %d 超出范围 (总页数: %d)", pageNum, r.NumPage())
+	var sb strings.Builder
+	for page := 1; page <= doc.NumPage() && page <= scanPdfPages; page++ {
+		t, _ := doc.PageText(page)
+		sb.WriteString(t)
+		sb.WriteString("\n")
 	}
+	text := sb.String()
 
-	p := r.Page(pageNum)
-	text, _ := p.GetPlainText(nil)
+	// 扫描件需经识别引擎处理，提前确认该路径可用，避免提供注定提取不到的字段
+	if !e.hasTextLayer(text) && e.ocr == nil {
+		if _, err := findWinOcrBridge(); err != nil {
+			return "", err
+		}
+	}
 	return text, nil
 }
 
-// batchExtractLocalPdf 批量本地提取 PDF 文本层 (并发加速版)
-func (e *Extractor) batchExtractLocalPdf(fileData []byte, fields []string, totalPages int, onProgress ProgressCallback) ([]Record, error) {
-	e.logger.Info("启动并行提取引擎", "workers", runtime.NumCPU())
+// isPdfEncryptionError 判断 PDF 库返回的错误是否由加密引起
+func isPdfEncryptionError(err error) bool {
+	if errors.Is(err, pdf.ErrInvalidPassword) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "encrypt") || strings.Contains(msg, "password")
+}
+
+// DefaultMinTextChars 页面视为文本层所需的默认非空白字符数
+const DefaultMinTextChars = 10
+
+// textProbePages 判断解析模式时最多探测的页数
+const textProbePages = 3
+
+// hasTextLayer 判断页面文本是否足以视为原生文本层 (非空白字符少于 MinTextChars 则视为扫描件，需要识别)
+func (e *Extractor) hasTextLayer(pageText string) bool {
+	threshold := e.minTextChars()
+	n := 0
+	for _, r := range pageText {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n >= threshold
+}
+
+// minTextChars 返回文本层判定的字符数阈值，未设置时为 DefaultMinTextChars
+func (e *Extractor) minTextChars() int {
+	if e.MinTextChars <= 0 {
+		return DefaultMinTextChars
+	}
+	return e.MinTextChars
+}
+
+// maxDefaultWorkers 自动确定并发数时的上限，防止内存波动过大
+const maxDefaultWorkers = 8
 
-	// 1. 预解析一次 Reader，供所有子任务复用 (dslipak/pdf 是并发安全的)
-	r, err := pdf.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+// DefaultWorkers 返回本地并行解析的默认协程数：GOMAXPROCS (容器中受 CPU 配额限制)，最多 maxDefaultWorkers
+func DefaultWorkers() int {
+	return min(runtime.GOMAXPROCS(0), maxDefaultWorkers)
+}
+
+// batchExtractLocalPdf 批量本地提取 PDF 文本层 (并发加速版)。sink 非 nil 时按页码顺序逐页输出记录；
+// ctx 取消后不再分派新页面，并返回 ctx.Err()
+func (e *Extractor) batchExtractLocalPdf(ctx context.Context, fileData []byte, fields []string, totalPages int, onProgress ProgressCallback, sink recordSink) ([]Record, error) {
+
+	// 1. 预解析一次文档，供所有子任务复用 (PDFTextDocument 须并发安全)
+	doc, err := e.pdfText().Open(fileData)
 	if err != nil {
 		return nil, fmt.Errorf("创建 PDF 阅读器失败: %w", err)
 	}
 
 	type pageResult struct {
 		pageNum int
+		text    string
 		records []Record
 		err     error
 	}
 
 	// 2. 准备并行任务
-	numWorkers := runtime.NumCPU()
-	if numWorkers > 8 {
-		numWorkers = 8 // 限制最大并发，防止内存波动过大
+	numWorkers := e.Workers
+	if numWorkers <= 0 {
+		numWorkers = DefaultWorkers()
 	}
 	if numWorkers > totalPages {
 		numWorkers = totalPages
 	}
+	e.logger.Info("启动并行提取引擎", "workers", numWorkers)
 
 	jobs := make(chan int, totalPages)
 	results := make(chan pageResult, totalPages)
@@ -215,9 +985,13 @@ func (e *Extractor) batchExtractLocalPdf(fileData []byte, fields []string, total
 		go func() {
 			defer wg.Done()
 			for pageNum := range jobs {
+				if ctx.Err() != nil {
+					results <- pageResult{pageNum: pageNum}
+					continue
+				}
+
 				// 提取并解析
-				p := r.Page(pageNum)
-				text, _ := p.GetPlainText(nil)
+				text, _ := doc.PageText(pageNum)
 
 				if strings.TrimSpace(text) == "" {
 					results <- pageResult{pageNum: pageNum}
@@ -228,7 +1002,7 @@ func (e *Extractor) batchExtractLocalPdf(fileData []byte, fields []string, total
 				for _, rec := range pageRecords {
 					rec["page"] = fmt.Sprintf("%d", pageNum)
 				}
-				results <- pageResult{pageNum: pageNum, records: pageRecords}
+				results <- pageResult{pageNum: pageNum, text: text, records: pageRecords}
 			}
 		}()
 	}
@@ -249,14 +1023,31 @@ func (e *Extractor) batchExtractLocalPdf(fileData []byte, fields []string, total
 
 	var allPageResults []pageResult
 	processedCount := 0
+	done := make(map[int][]Record) // 已完成但尚未输出到 sink 的页面
+	nextPage := 1
 	for res := range results {
 		processedCount++
 		if onProgress != nil {
 			onProgress(processedCount, totalPages, "正在进行文本层逻辑分析...")
 		}
-		if len(res.records) > 0 {
+		if res.text != "" {
 			allPageResults = append(allPageResults, res)
 		}
+
+		// 前面的页面全部完成后才输出，保证记录按页码顺序到达
+		if sink != nil {
+			done[res.pageNum] = res.records
+			for recs, ok := done[nextPage]; ok && ctx.Err() == nil; recs, ok = done[nextPage] {
+				if len(recs) > 0 {
+					sink(recs)
+				}
+				delete(done, nextPage)
+				nextPage++
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// 6. 按照页码排序，保证输出顺序一致
@@ -265,15 +1056,18 @@ func (e *Extractor) batchExtractLocalPdf(fileData []byte, fields []string, total
 	})
 
 	var finalRecords []Record
+	text := make([]pageText, 0, len(allPageResults))
 	for _, pr := range allPageResults {
 		finalRecords = append(finalRecords, pr.records...)
+		text = append(text, pageText{page: pr.pageNum, text: pr.text})
 	}
+	e.cacheText(fileData, nil, text)
 
 	return finalRecords, nil
 }
 
 // extractViaWinOcr 调用 Windows 系统原生 OCR 桥接工具 (并发加速版)
-func (e *Extractor) extractViaWinOcr(fileData []byte, totalPages int, onProgress ProgressCallback) ([]Record, error) {
+func (e *Extractor) extractViaWinOcr(ctx context.Context, fileData []byte, totalPages int, onProgress ProgressCallback) ([]Record, error) {
 	// 1. 创建临时文件存储 PDF 内容
 	tempFile, err := os.CreateTemp("", "legal_ocr_*.pdf")
 	if err != nil {
@@ -287,20 +1081,15 @@ func (e *Extractor) extractViaWinOcr(fileData []byte, totalPages int, onProgress
 	}
 
 	// 2. 定位桥接工具路径
-	exePath, _ := os.Executable()
-	baseDir := filepath.Dir(exePath)
-	bridgePath := filepath.Join(baseDir, "bridge_bin", "WinOcrBridge.exe")
-
-	if _, err := os.Stat(bridgePath); os.IsNotExist(err) {
-		bridgePath = filepath.Join("internal", "extractor", "bridge_bin", "WinOcrBridge.exe")
-		if _, err := os.Stat(bridgePath); os.IsNotExist(err) {
-			return nil, fmt.Errorf("找不到 Windows OCR 桥接工具 (WinOcrBridge.exe)")
-		}
+	bridgePath, err := findWinOcrBridge()
+	if err != nil {
+		return nil, err
 	}
 
 	type pageResult struct {
 		pageNum int
 		records []Record
+		err     error
 	}
 
 	// 3. 并行执行 OCR 进程
@@ -318,10 +1107,13 @@ func (e *Extractor) extractViaWinOcr(fileData []byte, totalPages int, onProgress
 		go func() {
 			defer wg.Done()
 			for pageNum := range jobs {
-				cmd := exec.Command(bridgePath, tempFile.Name(), fmt.Sprintf("%d", pageNum))
-				output, err := cmd.CombinedOutput()
+				_, span := startSpan(ctx, "winocr.Page", attribute.String("ocr.provider", "winocr"), attribute.Int("pdf.page", pageNum))
+				output, err := runBridge(ctx, e.BridgeTimeout, bridgePath, tempFile.Name(), fmt.Sprintf("%d", pageNum))
+				endSpan(span, err)
+				e.usage.Record("winocr", 1, 1, err)
 				if err != nil {
-					results <- pageResult{pageNum: pageNum}
+					e.logger.Warn("系统识别引擎处理失败", "page", pageNum, "error", err)
+					results <- pageResult{pageNum: pageNum, err: err}
 					continue
 				}
 
@@ -353,9 +1145,13 @@ func (e *Extractor) extractViaWinOcr(fileData []byte, totalPages int, onProgress
 	}()
 
 	var allPageResults []pageResult
+	var timeoutErr error
 	processed := 0
 	for res := range results {
 		processed++
+		if errors.Is(res.err, ErrBridgeTimeout) && timeoutErr == nil {
+			timeoutErr = res.err
+		}
 		if onProgress != nil {
 			onProgress(processed, totalPages, fmt.Sprintf("正在调用系统识别引擎提取第 %d 页内容...", res.pageNum))
 		}
@@ -373,15 +1169,35 @@ func (e *Extractor) extractViaWinOcr(fileData []byte, totalPages int, onProgress
 		finalRecords = append(finalRecords, pr.records...)
 	}
 
+	// 没有任何结果且有页面超时时，返回超时错误而非空结果，便于用户定位卡住的文档
+	if len(finalRecords) == 0 && timeoutErr != nil {
+		return nil, timeoutErr
+	}
 	return finalRecords, nil
 }
 
+// findWinOcrBridge 定位 Windows OCR 桥接工具，优先查找可执行文件同级目录
+func findWinOcrBridge() (string, error) {
+	exePath, _ := os.Executable()
+	baseDir := filepath.Dir(exePath)
+	bridgePath := filepath.Join(baseDir, "bridge_bin", "WinOcrBridge.exe")
+
+	if _, err := os.Stat(bridgePath); os.IsNotExist(err) {
+		bridgePath = filepath.Join("internal", "extractor", "bridge_bin", "WinOcrBridge.exe")
+		if _, err := os.Stat(bridgePath); os.IsNotExist(err) {
+			return "", ErrBridgeNotFound
+		}
+	}
+	return bridgePath, nil
+}
+
 // extractFromDocx 保留原有的本地 DOCX 提取逻辑
 func (e *Extractor) extractFromDocx(fileData []byte, fields []string) ([]Record, error) {
-	text, err := extractTextFromDocx(fileData)
+	text, err := extractTextFromDocx(fileData, e.ResolveDocxNumbering)
 	if err != nil {
 		return nil, err
 	}
+	e.cacheText(fileData, nil, []pageText{{text: text}})
 
 	if len(fields) == 0 {
 		for k := range PatternRegistry {
@@ -393,30 +1209,53 @@ func (e *Extractor) extractFromDocx(fileData []byte, fields []string) ([]Record,
 }
 
 // extractTextFromDocx 核心 DOCX 文本提取逻辑
-func extractTextFromDocx(fileData []byte) (string, error) {
+// resolveNumbering 为 true 时在自动编号段落前补上可见序号 (如 "一、")，使 smartMerge 能按条目分行
+func extractTextFromDocx(fileData []byte, resolveNumbering bool) (string, error) {
+	return docxText(fileData, resolveNumbering, 0)
+}
+
+// docxText 依次提取全部主文档部件的文本 (部件之间以换行分隔)，depth 为 altChunk 嵌套文档的层数
+func docxText(fileData []byte, resolveNumbering bool, depth int) (string, error) {
 	r, err := zip.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
 	if err != nil {
 		return "", err
 	}
 
-	var documentXML io.ReadCloser
-	for _, f := range r.File {
-		if f.Name == "word/document.xml" {
-			documentXML, err = f.Open()
-			if err != nil {
-				return "", err
-			}
-			break
+	parts := mainDocumentParts(r)
+	if len(parts) == 0 {
+		return "", fmt.Errorf("word/document.xml not found")
+	}
+
+	var numbering *docxNumbering
+	if resolveNumbering {
+		numbering = loadDocxNumbering(r)
+	}
+
+	var sb strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if err := writeDocxPart(&sb, r, part, numbering, depth); err != nil {
+			return "", err
 		}
 	}
+	return sb.String(), nil
+}
 
-	if documentXML == nil {
-		return "", fmt.Errorf("word/document.xml not found")
+// writeDocxPart 将一个文档部件的正文写入 sb：段落、表格行以换行分隔，单元格以空格分隔，
+// altChunk 引用的嵌入文档在其所在位置展开为独立段落
+func writeDocxPart(sb *strings.Builder, r *zip.Reader, part string, numbering *docxNumbering, depth int) error {
+	documentXML, err := openZipFile(r, part)
+	if err != nil {
+		return err
 	}
 	defer documentXML.Close()
 
 	decoder := xml.NewDecoder(documentXML)
-	var sb strings.Builder
+	var numID string
+	ilvl := 0
+	var rels map[string]string
 
 	for {
 		t, _ := decoder.Token()
@@ -425,14 +1264,50 @@ func extractTextFromDocx(fileData []byte) (string, error) {
 		}
 		switch se := t.(type) {
 		case xml.StartElement:
-			if se.Name.Local == "t" {
+			switch se.Name.Local {
+			case "t":
 				var s string
 				if err := decoder.DecodeElement(&s, &se); err == nil {
 					sb.WriteString(s)
 				}
+			case "p":
+				numID, ilvl = "", 0
+			case "numId", "ilvl":
+				if numbering == nil {
+					continue
+				}
+				for _, attr := range se.Attr {
+					if attr.Name.Local != "val" {
+						continue
+					}
+					if se.Name.Local == "numId" {
+						numID = attr.Value
+					} else if n, err := strconv.Atoi(attr.Value); err == nil {
+						ilvl = n
+					}
+				}
+			case "altChunk":
+				if rels == nil {
+					rels = partRelationships(r, part)
+				}
+				for _, attr := range se.Attr {
+					if attr.Name.Local == "id" && rels[attr.Value] != "" {
+						if text := altChunkText(r, rels[attr.Value], numbering != nil, depth); text != "" {
+							if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+								sb.WriteString("\n")
+							}
+							sb.WriteString(strings.TrimRight(text, "\n") + "\n")
+						}
+					}
+				}
 			}
 		case xml.EndElement:
 			switch se.Name.Local {
+			case "pPr":
+				// 段落属性结束、正文尚未开始，此时写入序号 (numId 为 "0" 表示取消编号)
+				if numbering != nil && numID != "" && numID != "0" {
+					sb.WriteString(numbering.next(numID, ilvl))
+				}
 			case "p", "tr":
 				sb.WriteString("\n")
 			case "tc":
@@ -440,19 +1315,40 @@ func extractTextFromDocx(fileData []byte) (string, error) {
 			}
 		}
 	}
-
-	return sb.String(), nil
+	return nil
 }
 
 // parseCases 现有的本地正则解析逻辑 (用于 DOCX)
 func (e *Extractor) parseCases(text string, fields []string) []Record {
-	parts := DefaultPatterns.Split.Split(text, -1)
+	return e.parseCasesTracked(text, fields, nil)
+}
+
+// parseCasesTracked 同 parseCases，tracker 非空时同时记录每条输出记录的字段位置
+func (e *Extractor) parseCasesTracked(text string, fields []string, tracker *offsetTracker) []Record {
+	p := e.patterns
+
+	// 记录每个分段在原文中的起始位置 (用于换算字段偏移) 及其前面的文书标题
+	parts := p.Split.Split(text, -1)
+	bases := make([]int, len(parts))
+	titles := make([]string, len(parts))
+	for i, loc := range p.Split.FindAllStringIndex(text, -1) {
+		if i+1 < len(bases) {
+			bases[i+1] = loc[1]
+			titles[i+1] = text[loc[0]:loc[1]]
+		}
+	}
 	var data []Record
 
-	for _, part := range parts {
+	for i, part := range parts {
+		// 附件 (送达地址确认书、授权委托书等) 不属于起诉状正文，截去其后的内容，避免地址、代理人信息混入字段
+		if loc := p.Annex.FindStringIndex(part); loc != nil {
+			part = part[:loc[0]]
+		}
 		if strings.TrimSpace(part) == "" {
 			continue
 		}
+		base := bases[i]
+		tracker.reset()
 
 		record := make(Record)
 		fieldSet := make(map[string]bool)
@@ -460,60 +1356,348 @@ func (e *Extractor) parseCases(text string, fields []string) []Record {
 			fieldSet[f] = true
 		}
 
-		// 1. 提取被告
-		if fieldSet["defendant"] {
-			loc := DefaultPatterns.DefStart.FindStringIndex(part)
-			if loc != nil {
-				startIdx := loc[1]
-				remaining := part[startIdx:]
-				cleanRemaining := strings.ReplaceAll(remaining, "\n", "")
-				locEnd := DefaultPatterns.DefEnd.FindStringIndex(cleanRemaining)
-
-				var name string
-				if locEnd != nil {
-					name = cleanRemaining[:locEnd[0]]
-				} else {
-					if len(cleanRemaining) > 50 {
-						name = cleanRemaining[:50]
-					} else {
-						name = cleanRemaining
-					}
+		repeatable := make(map[string]bool)
+		for _, f := range e.RepeatableFields {
+			repeatable[f] = true
+		}
+
+		// 1. 提取被告，并在被告信息段内查找性别、出生日期
+		if fieldSet["defendant"] || fieldSet["gender"] || fieldSet["birthday"] {
+			if names, span, ok := p.partyNames(part, p.DefStart, repeatable["defendant"]); ok {
+				if fieldSet["defendant"] {
+					record["defendant"] = strings.Join(names, RepeatSeparator)
+					tracker.set("defendant", base+span[0], base+span[1])
+				}
+
+				block := p.partyBlock(part, span[1])
+				if m := p.Gender.FindStringSubmatch(block); fieldSet["gender"] && m != nil {
+					record["gender"] = m[1] + m[2]
+				}
+				if m := p.Birthday.FindStringSubmatch(block); fieldSet["birthday"] && m != nil {
+					record["birthday"] = formatDate(m[1], m[2], m[3])
+				}
+			}
+		}
+
+		// 1.1 提取原告一方 (原告、身份证号、联系电话、委托代理人及其律所)
+		plaintiffName, plaintiffBlock, hasPlaintiff := p.fillPlaintiff(record, part, fieldSet, repeatable)
+		if hasPlaintiff && fieldSet["plaintiff"] {
+			tracker.set("plaintiff", base+plaintiffName[0], base+plaintiffName[1])
+		}
+
+		// 1.2 委托代理人的代理权限 (如 "特别授权""一般代理")
+		if fieldSet["agentAuthority"] {
+			if m := p.AgentAuthority.FindStringSubmatchIndex(part); m != nil {
+				record["agentAuthority"] = strings.TrimSpace(part[m[2]:m[3]])
+				tracker.set("agentAuthority", base+m[2], base+m[3])
+			}
+		}
+
+		// 1.3 提取第三人 (通常位于原告、被告之后)，其后至下一当事人或诉讼请求之前的身份证号归属第三人
+		if fieldSet["thirdParty"] || fieldSet["thirdPartyId"] {
+			if names, span, ok := p.partyNames(part, p.ThirdParty, repeatable["thirdParty"]); ok {
+				if fieldSet["thirdParty"] {
+					record["thirdParty"] = strings.Join(names, RepeatSeparator)
+					tracker.set("thirdParty", base+span[0], base+span[1])
+				}
+				if id := p.firstID(p.partyBlock(part, span[0])); fieldSet["thirdPartyId"] && id != "" {
+					record["thirdPartyId"] = id
 				}
-				record["defendant"] = strings.TrimSpace(name)
 			}
 		}
 
-		// 2. 提取身份证
+		// 2. 提取身份证 (原告一方信息段内的号码归属原告，查找时以空格遮盖，保持偏移不变)
 		if fieldSet["idNumber"] {
-			matchID := DefaultPatterns.ID.FindStringSubmatch(part)
-			if len(matchID) > 1 {
-				record["idNumber"] = strings.TrimSpace(matchID[1])
+			masked := part
+			if hasPlaintiff {
+				masked = part[:plaintiffBlock[0]] + strings.Repeat(" ", plaintiffBlock[1]-plaintiffBlock[0]) + part[plaintiffBlock[1]:]
+			}
+			var ids []string
+			for _, span := range p.findIDs(masked, repeatable["idNumber"]) {
+				if len(ids) == 0 {
+					tracker.set("idNumber", base+span[0], base+span[1])
+				}
+				ids = append(ids, normalizeIDText(part[span[0]:span[1]]))
+			}
+			if len(ids) > 0 {
+				record["idNumber"] = strings.Join(ids, RepeatSeparator)
+			}
+		}
+
+		// 2.1 提取被告一方的统一社会信用代码 (企业被告没有身份证号码)，同样跳过原告一方信息段
+		if fieldSet["creditCode"] {
+			masked := part
+			if hasPlaintiff {
+				masked = part[:plaintiffBlock[0]] + strings.Repeat(" ", plaintiffBlock[1]-plaintiffBlock[0]) + part[plaintiffBlock[1]:]
+			}
+			if m := p.CreditCode.FindStringSubmatchIndex(masked); m != nil {
+				record["creditCode"] = strings.ToUpper(part[m[2]:m[3]])
+				tracker.set("creditCode", base+m[2], base+m[3])
 			}
 		}
 
+		reqStart, reqEnd, hasReq := p.sectionSpan(part, p.Request, p.RequestLabel, p.FactsLabel)
+
 		// 3. 提取请求
-		if fieldSet["request"] {
-			matchReq := DefaultPatterns.Request.FindStringSubmatch(part)
-			if len(matchReq) > 1 {
-				record["request"] = smartMerge(matchReq[1])
+		if fieldSet["request"] && hasReq {
+			record["request"] = smartMerge(part[reqStart:reqEnd])
+			tracker.set("request", base+reqStart, base+reqEnd)
+		}
+
+		// 3.1 提取案由：优先取 "案由：" 标注，否则在正文中查找常见案由；统一为 CommonCaseCauses 中的名称
+		if fieldSet["caseCause"] {
+			if m := p.CaseCause.FindStringSubmatchIndex(part); m != nil {
+				record["caseCause"] = NormalizeCaseCause(part[m[2]:m[3]])
+				tracker.set("caseCause", base+m[2], base+m[3])
+			} else if cause := findCaseCause(strings.Join(strings.Fields(part), "")); cause != "" {
+				record["caseCause"] = cause
 			}
 		}
 
 		// 4. 提取事实
 		if fieldSet["factsReason"] {
-			matchFact := DefaultPatterns.Facts.FindStringSubmatch(part)
-			if len(matchFact) > 1 {
-				record["factsReason"] = smartMerge(matchFact[1])
+			if start, end, ok := p.sectionSpan(part, p.Facts, p.FactsLabel, p.RequestLabel); ok {
+				record["factsReason"] = smartMerge(part[start:end])
+				tracker.set("factsReason", base+start, base+end)
 			}
 		}
 
-		if len(record) > 0 {
-			data = append(data, record)
+		// 5. 提取金额 (优先在诉讼请求中查找，避免误取事实部分的金额)
+		if fieldSet["amount"] {
+			source, sourceBase := part, base
+			if hasReq {
+				source, sourceBase = part[reqStart:reqEnd], base+reqStart
+			}
+			fillAmount(record, source)
+			tracker.find("amount", sourceBase, record["amount"])
+		}
+
+		if len(record) == 0 {
+			continue
 		}
+		// 第一段之前没有分段标题，从正文判断 (如判决书、裁定书)
+		if docType := detectDocType(titles[i] + part); docType != "" {
+			record[DocTypeKey] = docType
+		}
+		if e.SplitDefendants && fieldSet["defendant"] {
+			if defendants := p.findParties(part, p.DefStart); len(defendants) > 1 {
+				shared := tracker.snapshot("defendant", "idNumber", "creditCode")
+				for _, d := range defendants {
+					r := p.jointDefendantRecord(record, part, d, fieldSet)
+					tracker.restore(shared)
+					tracker.set("defendant", base+d.span[0], base+d.span[1])
+					tracker.add()
+					checkIDConsistency(r, e.PreferIDDerived)
+					data = append(data, r)
+				}
+				continue
+			}
+		}
+		checkIDConsistency(record, e.PreferIDDerived)
+		tracker.add()
+		data = append(data, record)
 	}
 	return data
 }
 
+// fillPlaintiff 提取诉讼请求之前的原告一方信息，只将所选字段写入 record：原告姓名、其信息段内的身份证号码、联系电话，
+// 以及委托诉讼代理人和所在律所。返回第一个原告姓名的区间与原告信息段 (至被告、第三人或诉讼请求之前) 的区间；
+// 未选择原告字段时同样定位信息段，供被告身份证号码、信用代码的查找跳过原告一方。repeatable 中的原告、联系电话提取全部出现项
+func (p *ExtractionPatterns) fillPlaintiff(record Record, part string, fieldSet, repeatable map[string]bool) (name, block [2]int, ok bool) {
+	head := part
+	if loc := p.RequestLabel.FindStringIndex(part); loc != nil {
+		head = part[:loc[0]]
+	}
+	names, span, ok := p.partyNames(head, p.Plaintiff, repeatable["plaintiff"])
+	if !ok {
+		return name, block, false
+	}
+	text := p.partyBlock(part, span[0])
+	block = [2]int{span[0], span[0] + len(text)}
+
+	if fieldSet["plaintiff"] && len(names) > 0 {
+		record["plaintiff"] = strings.Join(names, RepeatSeparator)
+	}
+	if id := p.firstID(text); fieldSet["plaintiffId"] && id != "" {
+		record["plaintiffId"] = id
+	}
+	if fieldSet["plaintiffPhone"] {
+		var phones []string
+		for _, m := range p.Phone.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(phones, m[1]) {
+				phones = append(phones, m[1])
+			}
+			if !repeatable["plaintiffPhone"] {
+				break
+			}
+		}
+		if len(phones) > 0 {
+			record["plaintiffPhone"] = strings.Join(phones, RepeatSeparator)
+		}
+	}
+	if loc := p.PlaintiffAgent.FindStringIndex(text); loc != nil {
+		agent := text[loc[1]:]
+		if fieldSet["plaintiffAgent"] {
+			if n := p.agentName(agent); n != "" {
+				record["plaintiffAgent"] = n
+			}
+		}
+		if firm := p.LawFirm.FindString(agent); fieldSet["plaintiffLawFirm"] && firm != "" {
+			record["plaintiffLawFirm"] = strings.Join(strings.Fields(firm), "")
+		}
+	}
+	return span, block, true
+}
+
+// agentName 截取代理人标签之后的姓名，止于第一个标点或换行
+func (p *ExtractionPatterns) agentName(s string) string {
+	s = strings.TrimLeft(s, " \t\r\n")
+	if idx := strings.IndexAny(s, ",，、；;。(（\r\n"); idx >= 0 {
+		s = s[:idx]
+	}
+	return truncateRunes(strings.TrimSpace(s), p.DefMaxRunes)
+}
+
+// jointDefendantRecord 为共同被告中的一人生成记录：复制共同部分，个人信息改取自其本人的信息段
+func (p *ExtractionPatterns) jointDefendantRecord(shared Record, part string, d partyMatch, fieldSet map[string]bool) Record {
+	r := make(Record, len(shared))
+	for k, v := range shared {
+		if k == "defendant" || k == "idNumber" || k == "creditCode" || k == "gender" || k == "birthday" {
+			continue
+		}
+		r[k] = v
+	}
+	r["defendant"] = d.name
+
+	block := p.partyBlock(part, d.span[1])
+	if id := p.firstID(block); fieldSet["idNumber"] && id != "" {
+		r["idNumber"] = id
+	}
+	if m := p.CreditCode.FindStringSubmatch(block); fieldSet["creditCode"] && m != nil {
+		r["creditCode"] = strings.ToUpper(m[1])
+	}
+	if m := p.Gender.FindStringSubmatch(block); fieldSet["gender"] && m != nil {
+		r["gender"] = m[1] + m[2]
+	}
+	if m := p.Birthday.FindStringSubmatch(block); fieldSet["birthday"] && m != nil {
+		r["birthday"] = formatDate(m[1], m[2], m[3])
+	}
+	return r
+}
+
+// partyBlock 返回从 from 开始、至下一当事人标签或诉讼请求之前的当事人信息段
+func (p *ExtractionPatterns) partyBlock(part string, from int) string {
+	block := part[from:]
+	for _, next := range []*regexp.Regexp{p.DefStart, p.ThirdParty, p.RequestLabel} {
+		if loc := next.FindStringIndex(block); loc != nil {
+			block = block[:loc[0]]
+		}
+	}
+	return block
+}
+
+// sectionSpan 定位章节正文的 [start, end) 区间：优先使用完整模式 full，匹配失败时从章节标题 label 开始。
+// 无论哪种方式，正文都会截止于下一个已知章节标题 (next)、SectionEnd 结尾标记或文档末尾，
+// 以兼容缺少"此致"或章节顺序颠倒的文书
+func (p *ExtractionPatterns) sectionSpan(part string, full, label *regexp.Regexp, next ...*regexp.Regexp) (int, int, bool) {
+	start, end := -1, len(part)
+	if m := full.FindStringSubmatchIndex(part); len(m) > 3 && m[2] >= 0 {
+		start, end = m[2], m[3]
+	} else if loc := label.FindStringIndex(part); loc != nil {
+		start = loc[1]
+	} else {
+		return 0, 0, false
+	}
+
+	rest := part[start:end]
+	for _, re := range append(next, p.SectionEnd) {
+		if loc := re.FindStringIndex(rest); loc != nil && start+loc[0] < end {
+			end = start + loc[0]
+		}
+	}
+	if strings.TrimSpace(part[start:end]) == "" {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// partyMatch 一处当事人标签后的姓名及其在分段中的字节区间
+type partyMatch struct {
+	name string
+	span [2]int
+}
+
+// findParties 提取每个 start 标签 (如"被告："、"被告二："、"第三人：") 之后的当事人姓名
+func (p *ExtractionPatterns) findParties(part string, start *regexp.Regexp) []partyMatch {
+	var matches []partyMatch
+	for _, loc := range start.FindAllStringIndex(part, -1) {
+		startIdx := loc[1]
+		remaining := part[startIdx:]
+		cleanRemaining := strings.ReplaceAll(remaining, "\n", "")
+		// 姓名不越过下一个当事人或诉讼请求标签 (如单独成行、其后没有标点的 "原告：某某公司")
+		for _, next := range []*regexp.Regexp{p.DefStart, p.ThirdParty, p.RequestLabel} {
+			if loc := next.FindStringIndex(cleanRemaining); loc != nil {
+				cleanRemaining = cleanRemaining[:loc[0]]
+			}
+		}
+
+		var name string
+		if end := p.defendantEnd(cleanRemaining); end >= 0 {
+			name = cleanRemaining[:end]
+		} else {
+			name = truncateRunes(cleanRemaining, p.DefMaxRunes)
+		}
+		matches = append(matches, partyMatch{
+			name: p.cleanPartyName(name),
+			span: [2]int{startIdx, startIdx + skipNewlines(remaining, len(name))},
+		})
+	}
+	return matches
+}
+
+// partyNames 提取 start 标签之后的当事人姓名，all 为 true 时收集全部出现项。
+// 返回第一个姓名在 part 中的字节区间，用于记录来源位置
+func (p *ExtractionPatterns) partyNames(part string, start *regexp.Regexp, all bool) ([]string, [2]int, bool) {
+	matches := p.findParties(part, start)
+	if len(matches) == 0 {
+		return nil, [2]int{}, false
+	}
+	if !all {
+		matches = matches[:1]
+	}
+	var names []string
+	for _, m := range matches {
+		if m.name != "" {
+			names = append(names, m.name)
+		}
+	}
+	return names, matches[0].span, true
+}
+
+// defendantEnd 返回被告姓名的结束位置：DefEnd 关键词或 DefStopChars 中最靠前者，均未出现时返回 -1
+func (p *ExtractionPatterns) defendantEnd(s string) int {
+	end := -1
+	if loc := p.DefEnd.FindStringIndex(s); loc != nil {
+		end = loc[0]
+	}
+	if idx := strings.IndexAny(s, p.DefStopChars); idx >= 0 && (end < 0 || idx < end) {
+		end = idx
+	}
+	return end
+}
+
+// truncateRunes 按字符 (rune) 截断字符串，max 非正数时不截断
+func truncateRunes(s string, max int) string {
+	if max <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
 // smartMerge 智能合并换行符
 // 逻辑：保留句号、分号、冒号后的换行，或者新条目序号（如"二、"）之前的换行，其他的换行符视作布局造成的干扰并予以合并。
 func smartMerge(s string) string {
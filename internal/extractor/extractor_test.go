@@ -1,9 +1,54 @@
 package extractor
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"legal-extractor/internal/config"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
 )
 
+var allFields = []string{"defendant", "idNumber", "request", "factsReason"}
+
+// readFixture 读取 testdata 下的测试样本
+func readFixture(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// mergedFixture 将 testdata 下的 PDF 样本重复拼接 copies 份，返回合并后的文件与总页数
+func mergedFixture(t testing.TB, name string, copies int) ([]byte, int) {
+	t.Helper()
+	page := readFixture(t, name)
+	readers := make([]io.ReadSeeker, copies)
+	for i := range readers {
+		readers[i] = bytes.NewReader(page)
+	}
+	var buf bytes.Buffer
+	if err := api.MergeRaw(readers, &buf, false, nil); err != nil {
+		t.Fatalf("merge %s: %v", name, err)
+	}
+	pages, err := api.PageCount(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("count pages: %v", err)
+	}
+	return buf.Bytes(), pages
+}
+
 func TestParseCases(t *testing.T) {
 	e := NewExtractor(nil)
 	text := `
@@ -30,7 +75,7 @@ func TestParseCases(t *testing.T) {
 		},
 	}
 
-	result := e.parseCases(text, []string{"defendant", "idNumber", "request", "factsReason"})
+	result := e.parseCases(text, allFields)
 
 	if len(result) != 1 {
 		t.Fatalf("Expected 1 record, got %d", len(result))
@@ -76,3 +121,936 @@ func TestSmartMerge(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCases_Arbitration(t *testing.T) {
+	e := NewExtractor(nil)
+	text := `
+仲 裁 申 请 书
+
+申请人： 某某小额贷款有限公司
+被申请人： 李四，住址：上海市浦东新区
+身份证号码： 110101198505051234
+
+仲裁请求：
+一、裁决被申请人偿还借款本金20000元；
+事实与理由：
+被申请人于2022年向申请人借款，逾期未还。
+此致
+`
+	result := e.parseCases(text, allFields)
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(result))
+	}
+	if got := result[0]["defendant"]; got != "李四" {
+		t.Errorf("defendant: expected %q, got %q", "李四", got)
+	}
+	if got := result[0]["idNumber"]; got != "110101198505051234" {
+		t.Errorf("idNumber: expected %q, got %q", "110101198505051234", got)
+	}
+	if got := result[0]["request"]; got == "" {
+		t.Error("request: expected 仲裁请求 content, got empty")
+	}
+}
+
+func TestParseMarkdown_Arbitration(t *testing.T) {
+	md := "# 仲裁申请书\n\n申请人：某某小额贷款有限公司\n\n被申请人：李四，住址：上海市浦东新区\n\n仲裁请求：裁决被申请人偿还借款。\n"
+	records := ParseMarkdown(md)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["defendant"]; got != "李四" {
+		t.Errorf("defendant: expected %q, got %q", "李四", got)
+	}
+}
+
+func TestExtractData_PDF_Scanned_MockOCR(t *testing.T) {
+	fileData := readFixture(t, "scanned.pdf")
+
+	e := NewExtractor(nil)
+	mock := NewMockOCRProvider()
+	e.SetOCRProvider(mock)
+
+	records, err := e.ExtractData(fileData, "scanned.pdf", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["defendant"]; got != "张三" {
+		t.Errorf("defendant: expected %q, got %q", "张三", got)
+	}
+	if got := records[0]["page"]; got != "1" {
+		t.Errorf("page: expected %q, got %q", "1", got)
+	}
+
+	// 相同内容再次提取应命中缓存，不再调用识别引擎
+	if _, err := e.ExtractData(fileData, "scanned.pdf", nil, nil); err != nil {
+		t.Fatalf("second ExtractData returned error: %v", err)
+	}
+	if mock.Calls() != 1 {
+		t.Errorf("Expected OCR provider to be called once, got %d", mock.Calls())
+	}
+}
+
+func TestExtractData_PDF_Text(t *testing.T) {
+	e := NewExtractor(nil)
+	records, err := e.ExtractData(readFixture(t, "complaint.pdf"), "complaint.pdf", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+
+	want := Record{
+		"defendant":   "张三",
+		"idNumber":    "110101199001011234",
+		"request":     "一、请求判令被告偿还借款10000元；\n二、诉讼费由被告承担。",
+		"factsReason": "2023年1月1日，被告向原告借款10000元，至今未还。",
+		"page":        "1",
+	}
+	for k, v := range want {
+		if records[0][k] != v {
+			t.Errorf("Field %s: expected %q, got %q", k, v, records[0][k])
+		}
+	}
+}
+
+func TestExtractData_DOCX(t *testing.T) {
+	e := NewExtractor(nil)
+	records, err := e.ExtractData(readFixture(t, "complaint.docx"), "complaint.docx", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["defendant"]; got != "钱七" {
+		t.Errorf("defendant: expected %q, got %q", "钱七", got)
+	}
+	if got := records[0]["idNumber"]; got != "31010419880808123X" {
+		t.Errorf("idNumber: expected %q, got %q", "31010419880808123X", got)
+	}
+
+	// 导出到临时目录，确认结果可以完整写出
+	out := filepath.Join(t.TempDir(), "out.json")
+	if err := ExportJSON(out, records); err != nil {
+		t.Fatalf("ExportJSON returned error: %v", err)
+	}
+	exported, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if !strings.Contains(string(exported), "钱七") {
+		t.Errorf("Exported JSON missing defendant: %s", exported)
+	}
+}
+
+func TestExtractData_DOCX_AutoNumbering(t *testing.T) {
+	data := readFixture(t, "numbered_list.docx")
+	want := "一、判令被告偿还借款本金人民币10000元\n二、判令被告支付利息\n三、本案诉讼费用由被告承担"
+
+	e := NewExtractor(nil)
+	records, err := e.ExtractData(data, "numbered_list.docx", []string{"request"}, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["request"]; got != want {
+		t.Errorf("request: expected %q, got %q", want, got)
+	}
+
+	// 关闭后序号不可见，各条目被合并为一行
+	text, err := extractTextFromDocx(data, false)
+	if err != nil {
+		t.Fatalf("extractTextFromDocx returned error: %v", err)
+	}
+	if strings.Contains(text, "一、") {
+		t.Errorf("Numbering should not be resolved when disabled: %q", text)
+	}
+}
+
+func TestExtractData_DOCX_AltChunk(t *testing.T) {
+	data := readFixture(t, "altchunk.docx")
+
+	// 起诉状正文通过 altChunk 以 HTML 嵌入，另有独立的 word/document2.xml 部件
+	text, err := extractTextFromDocx(data, true)
+	if err != nil {
+		t.Fatalf("extractTextFromDocx returned error: %v", err)
+	}
+	for _, want := range []string{"（正文由模板插入）\n被告：周八", "借款30000元&利息", "人民法院\n附：证据清单", "证据清单\n\n附件二：借条复印件一份"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q:\n%s", want, text)
+		}
+	}
+
+	e := NewExtractor(nil)
+	records, err := e.ExtractData(data, "altchunk.docx", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["defendant"]; got != "周八" {
+		t.Errorf("defendant: expected %q, got %q", "周八", got)
+	}
+	if got := records[0]["idNumber"]; got != "330106199010101234" {
+		t.Errorf("idNumber: expected %q, got %q", "330106199010101234", got)
+	}
+}
+
+func TestExtractData_EML(t *testing.T) {
+	data := readFixture(t, "attachment.eml")
+
+	// 未配置识别引擎时跳过正文内嵌的图片，只提取 DOCX 附件
+	e := NewExtractor(nil)
+	records, err := e.ExtractData(data, "attachment.eml", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0][AttachmentKey]; got != "起诉状.docx" {
+		t.Errorf("%s: expected %q, got %q", AttachmentKey, "起诉状.docx", got)
+	}
+	if got := records[0]["defendant"]; got != "钱七" {
+		t.Errorf("defendant: expected %q, got %q", "钱七", got)
+	}
+
+	// 配置识别引擎后内嵌图片同样送识别
+	e = NewExtractor(nil)
+	mock := NewMockOCRProvider()
+	e.SetOCRProvider(mock)
+	records, err = e.ExtractData(data, "attachment.eml", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 2 || records[0][AttachmentKey] != "logo.png" || mock.Calls() != 1 {
+		t.Errorf("Expected the inline image to be recognized first, got %v (%d calls)", records, mock.Calls())
+	}
+
+	if _, err := NewExtractor(nil).ExtractData([]byte("Subject: hi\r\n\r\nno attachments"), "empty.eml", allFields, nil); err == nil {
+		t.Error("Expected an error for an email without attachments")
+	}
+}
+
+func TestExtractText_StrictMissingFields(t *testing.T) {
+	text := "民事起诉状\n原告：甲公司\n被告：张三，住址：北京\n诉讼请求：\n偿还借款10000元。\n事实与理由：\n借款未还。\n此致\n"
+	e := NewExtractor(nil)
+	e.RequiredFields = []string{"defendant", "idNumber"}
+	e.Strict = true
+
+	records, err := e.ExtractText(text, allFields)
+	var missing *MissingFieldsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected a MissingFieldsError, got records %v (err %v)", records, err)
+	}
+	if len(missing.Records) != 1 || !reflect.DeepEqual(missing.Records[0].Fields, []string{"idNumber"}) {
+		t.Errorf("Expected idNumber missing from record 1, got %+v", missing.Records)
+	}
+	if !strings.Contains(err.Error(), "idNumber") {
+		t.Errorf("Expected the error to name the missing field, got %q", err)
+	}
+
+	// 满足 RequireAny 时不报错
+	e.RequireAny = true
+	if records, err := e.ExtractText(text, allFields); err != nil || len(records) != 1 {
+		t.Errorf("Expected 1 record with RequireAny, got %d (%v)", len(records), err)
+	}
+}
+
+func TestApplyRequiredFields(t *testing.T) {
+	records := []Record{
+		{"defendant": "张三", "idNumber": "110101199001011234"},
+		{"defendant": "李四"},
+		{"request": "偿还借款"},
+	}
+
+	tests := []struct {
+		name           string
+		required       []string
+		requireAny     bool
+		keepIncomplete bool
+		wantCount      int
+		wantFlagged    int
+	}{
+		{name: "default keeps all", wantCount: 3},
+		{name: "require all", required: []string{"defendant", "idNumber"}, wantCount: 1},
+		{name: "require any", required: []string{"defendant", "idNumber"}, requireAny: true, wantCount: 2},
+		{name: "flag incomplete", required: []string{"defendant", "idNumber"}, keepIncomplete: true, wantCount: 3, wantFlagged: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExtractor(nil)
+			e.RequiredFields = tt.required
+			e.RequireAny = tt.requireAny
+			e.KeepIncomplete = tt.keepIncomplete
+
+			got := e.applyRequiredFields(records)
+			if len(got) != tt.wantCount {
+				t.Fatalf("Expected %d records, got %d", tt.wantCount, len(got))
+			}
+			flagged := 0
+			for _, rec := range got {
+				if rec[IncompleteKey] == "true" {
+					flagged++
+				}
+			}
+			if flagged != tt.wantFlagged {
+				t.Errorf("Expected %d flagged records, got %d", tt.wantFlagged, flagged)
+			}
+		})
+	}
+
+	for _, rec := range records {
+		if _, ok := rec[IncompleteKey]; ok {
+			t.Fatalf("Source records must not be mutated: %v", rec)
+		}
+	}
+}
+
+func TestScanFields(t *testing.T) {
+	e := NewExtractor(nil)
+
+	keys, err := e.ScanFields(readFixture(t, "no_closing.docx"), "no_closing.docx")
+	if err != nil {
+		t.Fatalf("ScanFields returned error: %v", err)
+	}
+	if want := []string{"plaintiff", "defendant", "idNumber", "request", "factsReason"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected fields %v, got %v", want, keys)
+	}
+
+	if _, err := e.ScanFields(readFixture(t, "encrypted.pdf"), "encrypted.pdf"); !errors.Is(err, ErrPDFEncrypted) {
+		t.Errorf("Expected ErrPDFEncrypted, got %v", err)
+	}
+
+	// 扫描件既无云端引擎也无桥接工具时不应假装所有字段都可用
+	e.SetOCRProvider(nil)
+	if _, err := e.ScanFields(readFixture(t, "scanned.pdf"), "scanned.pdf"); !errors.Is(err, ErrBridgeNotFound) {
+		t.Errorf("Expected ErrBridgeNotFound, got %v", err)
+	}
+
+	// 配置了识别引擎时，扫描件属于无法判断的可读文档，返回全部候选字段
+	e.SetOCRProvider(NewMockOCRProvider())
+	keys, err = e.ScanFields(readFixture(t, "scanned.pdf"), "scanned.pdf")
+	if err != nil || !reflect.DeepEqual(keys, ScanFieldKeys) {
+		t.Errorf("Expected default fields for scanned PDF, got %v (err %v)", keys, err)
+	}
+}
+
+func TestExtractImage(t *testing.T) {
+	e := NewExtractor(nil)
+	e.SetOCRProvider(nil)
+	if _, err := e.ExtractImage([]byte("\x89PNG"), allFields, nil); !errors.Is(err, ErrOCRNotConfigured) {
+		t.Errorf("Expected ErrOCRNotConfigured, got %v", err)
+	}
+
+	mock := NewMockOCRProvider()
+	e.SetOCRProvider(mock)
+	records, err := e.ExtractImage([]byte("\x89PNG"), []string{"defendant"}, nil)
+	if err != nil {
+		t.Fatalf("ExtractImage returned error: %v", err)
+	}
+	if len(records) == 0 || records[0]["defendant"] == "" {
+		t.Fatalf("Expected defendant from mock OCR, got %v", records)
+	}
+	if _, ok := records[0]["idNumber"]; ok {
+		t.Errorf("Unselected field should be dropped: %v", records[0])
+	}
+	if mock.Calls() != 1 {
+		t.Errorf("Expected 1 OCR call, got %d", mock.Calls())
+	}
+}
+
+func TestParseTextOffsets(t *testing.T) {
+	e := NewExtractor(nil)
+	text := "民事起诉状\n原告：甲公司\n被告：张三，住址：北京\n身份证号码：110101199001011234\n诉讼请求：\n判令被告偿还借款人民币10000元。\n事实与理由：\n被告借款未还。\n此致\n" +
+		"民事起诉状\n被告：李四，住址：上海\n身份证号码：310101198001011234\n"
+	fields := append([]string{"amount"}, allFields...)
+
+	records, offsets := e.ParseTextOffsets(text, fields)
+	if len(records) != 2 || len(offsets) != 2 {
+		t.Fatalf("Expected 2 records with offsets, got %d/%d", len(records), len(offsets))
+	}
+
+	runes := []rune(text)
+	for i, rec := range records {
+		for k := range rec {
+			if strings.HasPrefix(k, "_offset") {
+				t.Errorf("record %d: offsets must not be stored in the record: %v", i, rec)
+			}
+		}
+		for _, field := range []string{"defendant", "idNumber", "amount"} {
+			if rec[field] == "" {
+				continue
+			}
+			span, ok := offsets[i][field]
+			if !ok {
+				t.Errorf("record %d: missing offset for %s", i, field)
+				continue
+			}
+			if got := string(runes[span[0]:span[1]]); got != rec[field] {
+				t.Errorf("record %d: offset for %s points at %q, want %q", i, field, got, rec[field])
+			}
+		}
+	}
+	if span := offsets[0]["request"]; string(runes[span[0]:span[1]]) != "判令被告偿还借款人民币10000元。" {
+		t.Errorf("request offset points at %q", string(runes[span[0]:span[1]]))
+	}
+
+	// 拆分共同被告时每条记录的被告位置指向各自的姓名
+	e.SplitDefendants = true
+	joint := "民事起诉状\n原告：甲公司\n被告一：张三，住址：北京\n被告二：李四，住址：上海\n诉讼请求：\n判令偿还借款。\n"
+	records, offsets = e.ParseTextOffsets(joint, []string{"defendant", "request"})
+	if len(records) != 2 || len(offsets) != 2 {
+		t.Fatalf("Expected 2 joint records with offsets, got %d/%d", len(records), len(offsets))
+	}
+	jr := []rune(joint)
+	for i, rec := range records {
+		span := offsets[i]["defendant"]
+		if got := string(jr[span[0]:span[1]]); got != rec["defendant"] {
+			t.Errorf("joint record %d: defendant offset points at %q, want %q", i, got, rec["defendant"])
+		}
+		if _, ok := offsets[i]["request"]; !ok {
+			t.Errorf("joint record %d: shared request offset missing", i)
+		}
+	}
+}
+
+func TestParseCases_DefendantMaxRunes(t *testing.T) {
+	// 无任何结束标记的多方被告
+	parties := "甲市第一建筑工程有限公司、乙省第二建筑工程有限公司"
+	text := "民事起诉状\n被告：" + parties + "\n"
+	e := NewExtractor(nil)
+
+	tests := []struct {
+		name    string
+		maxRune int
+		want    string
+	}{
+		{"short cap", 4, "甲市第一"},
+		{"long cap", 40, parties},
+		{"unset restores default", 0, "甲市第一建筑工程有限公司、乙省第"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Extraction: config.ExtractionConfig{DefendantMaxRunes: tt.maxRune}}
+			e.patterns = newPatterns(cfg, slog.Default())
+			records := e.parseCases(text, []string{"defendant"})
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 record, got %d", len(records))
+			}
+			if got := records[0]["defendant"]; got != tt.want {
+				t.Errorf("defendant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCases_DefendantStopKeywords(t *testing.T) {
+	e := NewExtractor(nil)
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"电话", "张三 电话：13800000000"},
+		{"户籍", "张三，户籍地：北京市"},
+		{"出生", "张三 出生日期：1990年1月1日"},
+		{"住所", "张三，住所地：上海市"},
+		{"bare gender", "张三，男，汉族"},
+		{"birth year", "张三，1990年1月1日出生"},
+		{"spaced keyword", "张三，联 系 电 话：13800000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := e.parseCases("民事起诉状\n被告："+tt.line+"\n", []string{"defendant"})
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 record, got %d", len(records))
+			}
+			if got := records[0]["defendant"]; got != "张三" {
+				t.Errorf("defendant = %q, want %q", got, "张三")
+			}
+		})
+	}
+
+	// 自定义关键词替换内置列表
+	e.patterns.SetDefendantStopKeywords([]string{"法定代表人"})
+	records := e.parseCases("民事起诉状\n被告：某某公司 法定代表人：李四\n", []string{"defendant"})
+	if got := records[0]["defendant"]; got != "某某公司" {
+		t.Errorf("custom keyword: defendant = %q, want %q", got, "某某公司")
+	}
+
+	// 其他提取器不受影响，仍使用内置列表
+	records = NewExtractor(nil).parseCases("民事起诉状\n被告：张三，户籍地：北京市\n", []string{"defendant"})
+	if got := records[0]["defendant"]; got != "张三" {
+		t.Errorf("after reset: defendant = %q, want %q", got, "张三")
+	}
+}
+
+func TestExtractData_DOCX_SectionFallbacks(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    Record
+	}{
+		{
+			// 缺少"此致"，事实与理由截止于具状人签名
+			fixture: "no_closing.docx",
+			want: Record{
+				"request":     "一、判令被告返还租赁押金；\n二、本案诉讼费用由被告承担。",
+				"factsReason": "原告向被告承租房屋，租期届满后被告拒不退还押金。",
+			},
+		},
+		{
+			// 事实与理由在诉讼请求之前
+			fixture: "reordered_sections.docx",
+			want: Record{
+				"request":     "一、判令被告偿还借款20000元；\n二、本案诉讼费用由被告承担。",
+				"factsReason": "被告向原告借款后逾期未还。",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			records, err := NewExtractor(nil).ExtractData(readFixture(t, tt.fixture), tt.fixture, []string{"request", "factsReason"}, nil)
+			if err != nil {
+				t.Fatalf("ExtractData returned error: %v", err)
+			}
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 record, got %d", len(records))
+			}
+			for k, want := range tt.want {
+				if got := records[0][k]; got != want {
+					t.Errorf("%s: expected %q, got %q", k, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractData_DOCX_FactsEndAnchors(t *testing.T) {
+	const want = "被告于2023年5月向原告借款5000元，约定三个月内归还，至今未还。"
+	// 事实与理由分别截止于此致、起诉人签名行、日期行与证据章节标题
+	for _, fixture := range []string{"facts_end_closing.docx", "facts_end_signature.docx", "facts_end_date.docx", "facts_end_evidence.docx"} {
+		t.Run(fixture, func(t *testing.T) {
+			records, err := NewExtractor(nil).ExtractData(readFixture(t, fixture), fixture, []string{"request", "factsReason"}, nil)
+			if err != nil {
+				t.Fatalf("ExtractData returned error: %v", err)
+			}
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 record, got %d", len(records))
+			}
+			if got := records[0]["factsReason"]; got != want {
+				t.Errorf("factsReason: expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestSetSectionEndAnchors(t *testing.T) {
+	text := "民事起诉状\n被告：王五\n事实与理由：\n借款未还。\n附件：借条\n此致\n"
+	custom := []string{`此\s*致`, `^\s*附\s*件\s*[:：]`}
+	e := NewExtractor(nil)
+
+	if err := e.patterns.SetSectionEndAnchors(custom); err != nil {
+		t.Fatalf("SetSectionEndAnchors returned error: %v", err)
+	}
+	if got := e.parseCases(text, []string{"factsReason"})[0]["factsReason"]; got != "借款未还。" {
+		t.Errorf("Expected facts to end at the custom anchor, got %q", got)
+	}
+
+	if err := e.patterns.SetSectionEndAnchors([]string{`此致(`}); err == nil {
+		t.Error("Expected an error for an invalid anchor")
+	}
+	e.patterns.SetSectionEndAnchors(nil)
+	if got := e.parseCases(text, []string{"factsReason"})[0]["factsReason"]; got != "借款未还。\n附件：借条" {
+		t.Errorf("Default anchors should not end facts at 附件, got %q", got)
+	}
+
+	// 未配置或配置有误时使用内置标记，不受其他提取器所设的标记影响
+	e.patterns.SetSectionEndAnchors(custom)
+	for _, anchors := range [][]string{nil, {`此致(`}} {
+		cfg := &config.Config{Extraction: config.ExtractionConfig{SectionEndAnchors: anchors}}
+		other := NewExtractor(nil)
+		other.patterns = newPatterns(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if got := other.parseCases(text, []string{"factsReason"})[0]["factsReason"]; got != "借款未还。\n附件：借条" {
+			t.Errorf("anchors %q: expected the default anchors, got %q", anchors, got)
+		}
+	}
+}
+
+func TestParseCases_RepeatableFields(t *testing.T) {
+	text := "民事起诉状\n原告：甲公司\n被告：张三，住址：北京\n身份证号码：110101199001011234\n被告：李四，住址：上海\n身份证号码：310101198001011234\n诉讼请求：\n偿还借款。\n"
+	e := NewExtractor(nil)
+
+	records := e.parseCases(text, allFields)
+	if got := records[0]["idNumber"]; got != "110101199001011234" {
+		t.Errorf("By default only the first ID should be kept, got %q", got)
+	}
+
+	e.RepeatableFields = []string{"idNumber", "defendant"}
+	records = e.parseCases(text, allFields)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got, want := records[0]["idNumber"], "110101199001011234、310101198001011234"; got != want {
+		t.Errorf("idNumber = %q, want %q", got, want)
+	}
+	if got, want := records[0]["defendant"], "张三、李四"; got != want {
+		t.Errorf("defendant = %q, want %q", got, want)
+	}
+	// 原告一方的多个联系电话，重复的号码只保留一次
+	text = "民事起诉状\n原告：甲公司，联系电话：13800000001，手机：13800000002，电话：13800000001\n被告：张三，电话：13900000000\n诉讼请求：\n偿还借款。\n"
+	fields := []string{"plaintiff", "plaintiffPhone", "defendant"}
+	e.RepeatableFields = nil
+	if got := e.parseCases(text, fields)[0]["plaintiffPhone"]; got != "13800000001" {
+		t.Errorf("By default only the first phone should be kept, got %q", got)
+	}
+	e.RepeatableFields = []string{"plaintiffPhone"}
+	if got, want := e.parseCases(text, fields)[0]["plaintiffPhone"], "13800000001、13800000002"; got != want {
+		t.Errorf("plaintiffPhone = %q, want %q", got, want)
+	}
+}
+
+func TestExtractData_DOCX_ThirdParty(t *testing.T) {
+	records, err := NewExtractor(nil).ExtractData(readFixture(t, "third_party.docx"), "third_party.docx", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	want := map[string]string{
+		"defendant":    "李四",
+		"idNumber":     "110101198502020022",
+		"thirdParty":   "王五",
+		"thirdPartyId": "110101199003030033",
+	}
+	for k, v := range want {
+		if got := records[0][k]; got != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestExtractData_DOCX_CorporateDefendant(t *testing.T) {
+	records, err := NewExtractor(nil).ExtractData(readFixture(t, "corporate_defendant.docx"), "corporate_defendant.docx", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	want := map[string]string{
+		"defendant":  "北京某某商贸有限公司",
+		"creditCode": "91110105MA00XYZ12K", // 原告银行的代码不应计入被告
+		"idNumber":   "",
+	}
+	for k, v := range want {
+		if got := records[0][k]; got != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestExtractData_DOCX_Plaintiff(t *testing.T) {
+	records, err := NewExtractor(nil).ExtractData(readFixture(t, "plaintiff.docx"), "plaintiff.docx", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	want := map[string]string{
+		"plaintiff":        "陈一",
+		"plaintiffId":      "510104197904040041",
+		"plaintiffPhone":   "13800138000",
+		"plaintiffAgent":   "刘二",
+		"plaintiffLawFirm": "四川某某律师事务所",
+		"defendant":        "黄三",
+		"idNumber":         "510107198606060013", // 原告的号码不应计入被告
+		"gender":           "男",
+	}
+	for k, v := range want {
+		if got := records[0][k]; got != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got)
+		}
+	}
+
+	// 只选择被告字段时，原告的号码同样不计入被告
+	records, err = NewExtractor(nil).ExtractData(readFixture(t, "plaintiff.docx"), "plaintiff.docx", []string{"defendant", "idNumber"}, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 || records[0]["idNumber"] != "510107198606060013" {
+		t.Errorf("Expected the defendant's ID with only defendant fields selected, got %v", records)
+	}
+	if _, ok := records[0]["plaintiffId"]; ok {
+		t.Errorf("plaintiffId was not selected but got %q", records[0]["plaintiffId"])
+	}
+
+	// 导出时原告一方的列排在被告一方之前
+	records, _ = NewExtractor(nil).ExtractData(readFixture(t, "plaintiff.docx"), "plaintiff.docx", nil, nil)
+	keys, _ := exportColumns(records[0], false, ExportOptions{})
+	if len(keys) < 6 || keys[0] != "plaintiff" || keys[4] != "plaintiffLawFirm" || keys[5] != "defendant" {
+		t.Errorf("Expected plaintiff columns before defendant columns, got %v", keys)
+	}
+}
+
+func TestExtractData_DOCX_Annexes(t *testing.T) {
+	// 送达地址确认书、授权委托书夹在起诉状之间，其中的身份证号码与地址不应混入起诉状记录
+	records, err := NewExtractor(nil).ExtractData(readFixture(t, "annex.docx"), "annex.docx", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records (annexes skipped), got %d: %v", len(records), records)
+	}
+	if got := records[0]["defendant"]; got != "张三" {
+		t.Errorf("defendant = %q, want 张三", got)
+	}
+	if got := records[0]["idNumber"]; got != "" {
+		t.Errorf("idNumber leaked from the 授权委托书: %q", got)
+	}
+	if got, want := records[1]["factsReason"], "被告向原告借款5000元，到期未还。"; got != want {
+		t.Errorf("factsReason = %q, want %q", got, want)
+	}
+}
+
+func TestExtractData_DOCX_IDLabelVariants(t *testing.T) {
+	data := readFixture(t, "id_label_variants.docx")
+	fields := []string{"defendant", "idNumber"}
+
+	records, err := NewExtractor(nil).ExtractData(data, "id_label_variants.docx", fields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	want := []struct{ defendant, id string }{
+		{"张三", "110101198001010010"}, // 公民身份号码：
+		{"李四", "320102198512120022"}, // 居民身份证：
+		{"王五", "44030119920315003X"}, // 身份证号：(小写 x)
+		{"钱七", "510104197807070046"}, // 身份证 后直接跟号码
+		{"孙八", ""},                   // 校验位错误，不予采用
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %d", len(want), len(records))
+	}
+	for i, w := range want {
+		if got := records[i]["defendant"]; got != w.defendant {
+			t.Errorf("record %d: defendant = %q, want %q", i, got, w.defendant)
+		}
+		if got := records[i]["idNumber"]; got != w.id {
+			t.Errorf("record %d: idNumber = %q, want %q", i, got, w.id)
+		}
+	}
+
+	// 严格模式下只识别 "身份证号码："
+	e := NewExtractor(nil)
+	e.patterns.SetLooseIDMatch(false)
+	records, err = e.ExtractData(data, "id_label_variants.docx", fields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	for i, r := range records {
+		if r["idNumber"] != "" {
+			t.Errorf("record %d: strict mode should not match variant labels, got %q", i, r["idNumber"])
+		}
+	}
+}
+
+func TestParseMarkdown_ThirdParty(t *testing.T) {
+	records := ParseMarkdown("被告：李四，住址：北京\n第三人：王五，住址：上海\n诉讼请求：偿还借款")
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["defendant"]; got != "李四" {
+		t.Errorf("defendant = %q, want %q", got, "李四")
+	}
+	if got := records[0]["thirdParty"]; got != "王五" {
+		t.Errorf("thirdParty = %q, want %q", got, "王五")
+	}
+}
+
+func TestExtractData_FallbackOnEmpty(t *testing.T) {
+	empty := &MockOCRProvider{Records: []Record{}}
+	backup := &MockOCRProvider{Records: []Record{{"defendant": "张三", "page": "1"}}}
+	data := readFixture(t, "scanned.pdf")
+
+	e := NewExtractor(nil)
+	e.SetOCRProvider(empty)
+	e.SetFallbackOCRProviders(backup)
+	records, err := e.ExtractData(data, "scanned.pdf", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 0 || backup.Calls() != 0 {
+		t.Errorf("Without FallbackOnEmpty the backup must not be used, got %d records, %d backup calls", len(records), backup.Calls())
+	}
+
+	e = NewExtractor(nil)
+	e.SetOCRProvider(empty)
+	e.SetFallbackOCRProviders(backup)
+	e.FallbackOnEmpty = true
+	records, err = e.ExtractData(data, "scanned.pdf", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 || records[0]["defendant"] != "张三" {
+		t.Errorf("Expected the backup provider's record, got %v", records)
+	}
+	if empty.Calls() != 2 || backup.Calls() != 1 {
+		t.Errorf("Expected primary then backup, got %d primary and %d backup calls", empty.Calls(), backup.Calls())
+	}
+
+	// 引擎报错时不切换
+	e = NewExtractor(nil)
+	e.SetOCRProvider(&MockOCRProvider{Err: errors.New("boom")})
+	e.SetFallbackOCRProviders(backup)
+	e.FallbackOnEmpty = true
+	if _, err := e.ExtractData(data, "scanned.pdf", nil, nil); err == nil || backup.Calls() != 1 {
+		t.Errorf("Errors must not trigger the empty-result fallback, err=%v backup calls=%d", err, backup.Calls())
+	}
+}
+
+func TestExtractData_DOCX_JointDefendants(t *testing.T) {
+	data := readFixture(t, "joint_defendants.docx")
+	fields := []string{"defendant", "idNumber", "request", "factsReason", "amount"}
+
+	records, err := NewExtractor(nil).ExtractData(data, "joint_defendants.docx", fields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 || records[0]["defendant"] != "张三" {
+		t.Fatalf("By default only the first defendant is kept, got %v", records)
+	}
+
+	e := NewExtractor(nil)
+	e.SplitDefendants = true
+	records, err = e.ExtractData(data, "joint_defendants.docx", fields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected one record per defendant, got %d", len(records))
+	}
+	want := []struct{ defendant, id string }{
+		{"张三", "110101199001011237"},
+		{"李四", "110101198502020022"},
+	}
+	for i, w := range want {
+		r := records[i]
+		if r["defendant"] != w.defendant || r["idNumber"] != w.id {
+			t.Errorf("record %d: got defendant=%q idNumber=%q, want %q/%q", i, r["defendant"], r["idNumber"], w.defendant, w.id)
+		}
+		if !strings.Contains(r["request"], "共同偿还借款") || r["factsReason"] == "" || r["amount"] != "人民币80000元" {
+			t.Errorf("record %d should inherit the shared sections, got %v", i, r)
+		}
+	}
+}
+
+func TestExtractStream_Incremental(t *testing.T) {
+	data, pages := mergedFixture(t, "complaint.pdf", 4)
+	want, err := NewExtractor(nil).ExtractData(data, "merged.pdf", allFields, nil)
+	if err != nil || len(want) != pages {
+		t.Fatalf("Expected %d records from ExtractData, got %d (%v)", pages, len(want), err)
+	}
+
+	e := NewExtractor(nil)
+	e.Workers = 1
+	records, errs := e.ExtractStream(context.Background(), data, "merged.pdf", allFields)
+	var got []Record
+	for r := range records {
+		got = append(got, r)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("ExtractStream error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Streamed records differ from ExtractData:\n got %v\nwant %v", got, want)
+	}
+	for i, r := range got {
+		if r["page"] != fmt.Sprint(i+1) {
+			t.Errorf("record %d has page %q, expected page order", i, r["page"])
+		}
+	}
+}
+
+func TestExtractStream_StrictIndex(t *testing.T) {
+	data, _ := mergedFixture(t, "complaint.pdf", 4)
+	e := NewExtractor(nil)
+	e.Workers = 1
+	e.RequiredFields = []string{"idNumber"}
+	// 第 3 页的记录缺少必填字段
+	e.Transforms = []Transform{func(r Record) Record {
+		if r["page"] == "3" {
+			delete(r, "idNumber")
+		}
+		return r
+	}}
+
+	records, errs := e.ExtractStream(WithStrict(context.Background(), true), data, "merged.pdf", allFields)
+	received := 0
+	for range records {
+		received++
+	}
+	var missing *MissingFieldsError
+	if err := <-errs; !errors.As(err, &missing) {
+		t.Fatalf("Expected a MissingFieldsError, got %v", err)
+	}
+	// 序号按整份文档计，而不是按出错的那一批
+	if len(missing.Records) != 1 || missing.Records[0].Index != 3 || missing.Records[0].Page != "3" {
+		t.Errorf("Expected record 3 (page 3) to be reported, got %+v", missing.Records)
+	}
+	if received != 2 {
+		t.Errorf("Expected the 2 complete records before page 3 to be streamed, got %d", received)
+	}
+}
+
+func TestExtractStream_Cancel(t *testing.T) {
+	data, pages := mergedFixture(t, "complaint.pdf", 4)
+	e := NewExtractor(nil)
+	e.Workers = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	records, errs := e.ExtractStream(ctx, data, "merged.pdf", allFields)
+
+	// 第一条记录在整份文档解析完成前到达，取消后通道应关闭且不再输出
+	if _, ok := <-records; !ok {
+		t.Fatal("Expected a first record before cancellation")
+	}
+	cancel()
+	received := 1
+	for range records {
+		received++
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if received >= pages {
+		t.Errorf("Expected cancellation to stop the stream early, got all %d records", received)
+	}
+	if _, ok := <-errs; ok {
+		t.Error("error channel not closed")
+	}
+}
+
+func TestExtractStream_DOCX(t *testing.T) {
+	records, errs := NewExtractor(nil).ExtractStream(context.Background(), readFixture(t, "joint_defendants.docx"), "joint_defendants.docx", allFields)
+	count := 0
+	for range records {
+		count++
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("ExtractStream error: %v", err)
+	}
+	if count == 0 {
+		t.Error("Expected records from the DOCX stream")
+	}
+}
@@ -0,0 +1,74 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update 重新生成 testdata/cases 下的 golden 文件：go test ./internal/extractor -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/cases")
+
+// TestGolden 端到端回归：提取 testdata/cases 下的每份样本 (PDF、DOCX 或纯文本)，
+// 与同名的 .golden.json 逐字比较。解析规则有意改变时，以 -update 重新生成并审阅差异
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "cases", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ran := 0
+	for _, input := range inputs {
+		ext := strings.ToLower(filepath.Ext(input))
+		if ext != ".pdf" && ext != ".docx" && ext != ".txt" {
+			continue
+		}
+		ran++
+		name := filepath.Base(input)
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := NewExtractor(nil)
+			var records []Record
+			if ext == ".txt" {
+				records, err = e.ExtractText(string(data), nil)
+			} else {
+				records, err = e.ExtractData(data, name, FieldKeys(), nil)
+			}
+			if err != nil {
+				t.Fatalf("extract %s: %v", name, err)
+			}
+
+			got, err := json.MarshalIndent(records, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(input, filepath.Ext(input)) + ".golden.json"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from %s (run with -update if the change is intended):\n got %s\nwant %s",
+					name, filepath.Base(golden), got, want)
+			}
+		})
+	}
+	if ran == 0 {
+		t.Fatal("no golden cases found in testdata/cases")
+	}
+}
@@ -0,0 +1,139 @@
+package extractor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WarningsKey 记录提取结果中需要人工核对的提示，多条以"；"连接 (元数据，不参与表格导出)
+const WarningsKey = "_warnings"
+
+// IDInfo 由 18 位居民身份证号码推导出的信息
+type IDInfo struct {
+	Gender   string // "男" 或 "女"
+	Birthday string // 形如 "1990-01-02"
+}
+
+// idWeights GB 11643 校验码加权因子
+var idWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// ParseIDNumber 校验 18 位身份证号码 (出生日期与校验位)，有效时返回推导出的性别与出生日期
+func ParseIDNumber(id string) (IDInfo, bool) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if len(id) != 18 {
+		return IDInfo{}, false
+	}
+
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return IDInfo{}, false
+		}
+		sum += int(id[i]-'0') * idWeights[i]
+	}
+	if "10X98765432"[sum%11] != id[17] {
+		return IDInfo{}, false
+	}
+
+	birthday, err := time.Parse("20060102", id[6:14])
+	if err != nil {
+		return IDInfo{}, false
+	}
+
+	gender := "女"
+	if (id[16]-'0')%2 == 1 {
+		gender = "男"
+	}
+	return IDInfo{Gender: gender, Birthday: birthday.Format("2006-01-02")}, true
+}
+
+// findIDs 返回 text 中身份证号码的位置 (相对 text)，all 为 false 时只取第一个。
+// 优先使用严格的 "身份证号码：" 形式；未命中时再以 IDLoose 匹配 "公民身份号码"、"身份证" 等变体，
+// 此时只保留校验位有效的号码，避免把电话号码等误认为身份证号码
+func (p *ExtractionPatterns) findIDs(text string, all bool) [][2]int {
+	var spans [][2]int
+	for _, m := range p.ID.FindAllStringSubmatchIndex(text, -1) {
+		if len(m) < 4 || m[2] < 0 {
+			continue
+		}
+		spans = append(spans, [2]int{m[2], m[3]})
+		if !all {
+			return spans
+		}
+	}
+	if len(spans) > 0 || p.IDLoose == nil {
+		return spans
+	}
+
+	for _, m := range p.IDLoose.FindAllStringSubmatchIndex(text, -1) {
+		if len(m) < 4 || m[2] < 0 {
+			continue
+		}
+		if _, ok := ParseIDNumber(text[m[2]:m[3]]); !ok {
+			continue
+		}
+		spans = append(spans, [2]int{m[2], m[3]})
+		if !all {
+			break
+		}
+	}
+	return spans
+}
+
+// firstID 返回 text 中的第一个身份证号码，未找到时返回空字符串
+func (p *ExtractionPatterns) firstID(text string) string {
+	if spans := p.findIDs(text, false); len(spans) > 0 {
+		return normalizeIDText(text[spans[0][0]:spans[0][1]])
+	}
+	return ""
+}
+
+func normalizeIDText(id string) string {
+	return strings.ToUpper(strings.TrimSpace(id))
+}
+
+// formatDate 将年、月、日统一为 "2006-01-02" 格式，无法解析时原样拼接
+func formatDate(year, month, day string) string {
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errM != nil || errD != nil {
+		return year + "-" + month + "-" + day
+	}
+	return fmt.Sprintf("%s-%02d-%02d", year, m, d)
+}
+
+// checkIDConsistency 比对提取到的性别、出生日期与身份证号码推导的结果。
+// 不一致通常是 OCR 将不同当事人的信息错位所致：总是记录提示，prefer 为 true 时改用身份证推导的值
+func checkIDConsistency(r Record, prefer bool) {
+	// 多个身份证号码 (可重复字段) 时无法确定对应关系，不做比对
+	if strings.Contains(r["idNumber"], RepeatSeparator) {
+		return
+	}
+	info, ok := ParseIDNumber(r["idNumber"])
+	if !ok {
+		return
+	}
+
+	var warnings []string
+	if g := r["gender"]; g != "" && g != info.Gender {
+		warnings = append(warnings, fmt.Sprintf("性别 (%s) 与身份证号码推导的 (%s) 不一致", g, info.Gender))
+		if prefer {
+			r["gender"] = info.Gender
+		}
+	}
+	if b := r["birthday"]; b != "" && b != info.Birthday {
+		warnings = append(warnings, fmt.Sprintf("出生日期 (%s) 与身份证号码推导的 (%s) 不一致", b, info.Birthday))
+		if prefer {
+			r["birthday"] = info.Birthday
+		}
+	}
+	if len(warnings) == 0 {
+		return
+	}
+	if existing := r[WarningsKey]; existing != "" {
+		warnings = append([]string{existing}, warnings...)
+	}
+	r[WarningsKey] = strings.Join(warnings, "；")
+}
@@ -0,0 +1,49 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseIDNumber(t *testing.T) {
+	info, ok := ParseIDNumber("110101199001011237")
+	if !ok {
+		t.Fatal("Expected a valid ID number")
+	}
+	if info.Gender != "男" || info.Birthday != "1990-01-01" {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+
+	for _, id := range []string{"110101199001011234", "11010119900101123", "110101199013011237"} {
+		if _, ok := ParseIDNumber(id); ok {
+			t.Errorf("%s: expected invalid", id)
+		}
+	}
+}
+
+func TestParseCases_IDConsistency(t *testing.T) {
+	text := "民事起诉状\n原告：甲公司\n被告：张三，性别：女，1990年1月1日出生，住址：北京\n身份证号码：110101199001011237\n诉讼请求：\n偿还借款。\n"
+	fields := []string{"defendant", "gender", "birthday", "idNumber"}
+	e := NewExtractor(nil)
+
+	records := e.parseCases(text, fields)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	r := records[0]
+	if r["gender"] != "女" || r["birthday"] != "1990-01-01" {
+		t.Errorf("Extracted values should be kept by default, got gender=%q birthday=%q", r["gender"], r["birthday"])
+	}
+	if !strings.Contains(r[WarningsKey], "性别") || strings.Contains(r[WarningsKey], "出生日期") {
+		t.Errorf("Expected a gender warning only, got %q", r[WarningsKey])
+	}
+
+	e.PreferIDDerived = true
+	r = e.parseCases(text, fields)[0]
+	if r["gender"] != "男" {
+		t.Errorf("Expected the ID-derived gender, got %q", r["gender"])
+	}
+	if r[WarningsKey] == "" {
+		t.Error("The warning should be kept when the ID-derived value is preferred")
+	}
+}
@@ -5,19 +5,24 @@ import (
 	"strings"
 )
 
-// ParseMarkdown 针对 PaddleOCR-VL 优化的解析器
+// ParseMarkdown 针对 PaddleOCR-VL 优化的解析器，使用内置的解析规则 (提取器按各自的配置解析，见 parseOCRPages)
 func ParseMarkdown(markdown string) []Record {
+	return DefaultPatterns.parseMarkdown(markdown)
+}
+
+// parseMarkdown 按 p 中的规则解析识别结果的 Markdown
+func (p *ExtractionPatterns) parseMarkdown(markdown string) []Record {
 	if markdown == "" {
 		return nil
 	}
 
-	// 1. 预处理：剔除所有 HTML 标签 (VLM 经常返回 div/img)
-	cleanMd := stripHTML(markdown)
+	// 1. 预处理：剔除所有 HTML 标签 (VLM 经常返回 div/img)，并将配置的别名标注替换为标准标注
+	cleanMd := p.applyFieldAliases(stripHTML(markdown))
 	record := make(Record)
 
 	// 2. 按标题和常见关键词切分
 	// 增加对常见法律文书关键词的切分支持，增加“此致”作为结束标志
-	delimiters := []string{"#", "诉讼请求", "事实与理由", "事实和理由", "此致"}
+	delimiters := []string{"#", "诉讼请求", "仲裁请求", "事实与理由", "事实和理由", "此致"}
 	content := cleanMd
 	for _, d := range delimiters {
 		content = strings.ReplaceAll(content, d, "\n[SEP]"+d)
@@ -31,12 +36,15 @@ func ParseMarkdown(markdown string) []Record {
 		}
 		lowered := strings.ToLower(trimmed)
 
-		if strings.Contains(lowered, "被告") || strings.Contains(lowered, "当事人") {
+		if strings.Contains(lowered, "被告") || strings.Contains(lowered, "被申请人") || strings.Contains(lowered, "当事人") {
 			if record["defendant"] == "" {
-				record["defendant"] = extractField(trimmed, "被告")
+				record["defendant"] = p.extractDefendant(trimmed)
 			}
 		}
-		if strings.Contains(lowered, "诉讼请求") {
+		if strings.Contains(lowered, "第三人") && record["thirdParty"] == "" {
+			record["thirdParty"] = p.extractField(trimmed, "第三人")
+		}
+		if strings.Contains(lowered, "诉讼请求") || strings.Contains(lowered, "仲裁请求") {
 			record["request"] = cleanMarkdown(trimmed)
 		}
 		if strings.Contains(lowered, "事实") && (strings.Contains(lowered, "理由") || strings.Contains(lowered, "事实经过")) {
@@ -44,17 +52,38 @@ func ParseMarkdown(markdown string) []Record {
 		}
 	}
 
-	// 3. 兜底全局匹配
+	// 3. 从诉讼请求中提取金额
+	if record["request"] != "" {
+		fillAmount(record, record["request"])
+	}
+
+	// 4. 原告一方信息 (原告、身份证号、联系电话、代理人及律所)
+	idText := cleanMd
+	if _, block, ok := p.fillPlaintiff(record, cleanMd, plaintiffFieldSet, nil); ok {
+		idText = cleanMd[:block[0]] + cleanMd[block[1]:]
+	}
+
+	// 5. 兜底全局匹配
 	if record["defendant"] == "" {
-		record["defendant"] = extractField(cleanMd, "被告")
+		record["defendant"] = p.extractDefendant(cleanMd)
 	}
 	if record["idNumber"] == "" {
-		// 使用 patterns.go 中定义的身份证号正则
-		match := DefaultPatterns.ID.FindStringSubmatch(cleanMd)
-		if len(match) > 1 {
-			record["idNumber"] = strings.TrimSpace(match[1])
+		// 使用 patterns.go 中定义的身份证号正则 (含变体标签的兜底)，跳过原告的号码
+		if id := p.firstID(idText); id != "" {
+			record["idNumber"] = id
 		}
 	}
+	if m := p.CreditCode.FindStringSubmatch(idText); m != nil {
+		record["creditCode"] = strings.ToUpper(m[1])
+	}
+	if m := p.AgentAuthority.FindStringSubmatch(cleanMd); m != nil {
+		record["agentAuthority"] = strings.TrimSpace(m[1])
+	}
+	if m := p.CaseCause.FindStringSubmatch(cleanMd); m != nil {
+		record["caseCause"] = NormalizeCaseCause(m[1])
+	} else if cause := findCaseCause(strings.Join(strings.Fields(cleanMd), "")); cause != "" {
+		record["caseCause"] = cause
+	}
 
 	// 只有当至少有一个字段有值时才返回记录
 	hasData := false
@@ -66,12 +95,20 @@ func ParseMarkdown(markdown string) []Record {
 	}
 
 	if hasData {
+		if docType := detectDocType(cleanMd); docType != "" {
+			record[DocTypeKey] = docType
+		}
 		return []Record{record}
 	}
 
 	return nil
 }
 
+// plaintiffFieldSet 识别结果中提取的原告一方字段
+var plaintiffFieldSet = map[string]bool{
+	"plaintiff": true, "plaintiffId": true, "plaintiffPhone": true, "plaintiffAgent": true, "plaintiffLawFirm": true,
+}
+
 // stripHTML 使用正则剥离所有 HTML 标签
 func stripHTML(input string) string {
 	re := regexp.MustCompile(`<[^>]*>`)
@@ -90,15 +127,28 @@ func cleanMarkdown(s string) string {
 	s = strings.ReplaceAll(s, "&nbsp;", " ")
 
 	// 移除关键词头部，防止内容中重复出现标题
-	reHeader := regexp.MustCompile(`^(?i)(诉讼请求|事实与理由|事实和理由|事实经过)[:：\s]*`)
+	reHeader := regexp.MustCompile(`^(?i)(诉讼请求|仲裁请求|事实与理由|事实和理由|事实经过)[:：\s]*`)
 	s = reHeader.ReplaceAllString(s, "")
 
 	// 规范化换行和空格
 	return smartMerge(s)
 }
 
+// defendantLabels 被告一方的常见称谓 (仲裁、非诉文书使用"被申请人")
+var defendantLabels = []string{"被告", "被申请人"}
+
+// extractDefendant 依次尝试各被告称谓提取被告名称
+func (p *ExtractionPatterns) extractDefendant(text string) string {
+	for _, label := range defendantLabels {
+		if val := p.extractField(text, label); val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
 // extractField 从行中提取关键字段
-func extractField(text, keyword string) string {
+func (p *ExtractionPatterns) extractField(text, keyword string) string {
 	lines := strings.Split(text, "\n")
 	for i, line := range lines {
 		if strings.Contains(line, keyword) {
@@ -115,12 +165,11 @@ func extractField(text, keyword string) string {
 			}
 
 			if val != "" {
-				// 再次利用 DefEnd 正则清理多余后缀
-				locEnd := DefaultPatterns.DefEnd.FindStringIndex(val)
-				if locEnd != nil {
-					val = val[:locEnd[0]]
+				// 再次利用 DefEnd 正则与截止字符清理多余后缀
+				if end := p.defendantEnd(val); end >= 0 {
+					val = val[:end]
 				}
-				return strings.Trim(val, " ,，、;；")
+				return p.cleanPartyName(strings.Trim(val, " ,，、;；"))
 			}
 		}
 	}
@@ -0,0 +1,56 @@
+package extractor
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 当事人姓名中空白的处理方式 (extraction.name_whitespace)。两种方式都会去除换行与首尾空白
+const (
+	// NameWhitespaceCompact 删除姓名内部的全部空白 ("张 三" -> "张三")，仅在两个拉丁字母或数字之间保留一个空格 ("John Smith")
+	NameWhitespaceCompact = "compact"
+	// NameWhitespaceCollapse 将姓名内部的每段连续空白合并为一个空格
+	NameWhitespaceCollapse = "collapse"
+)
+
+// SetNameWhitespace 设置当事人 (被告、原告、第三人) 姓名的空白处理方式，空串恢复默认的 compact；
+// 无法识别的取值返回错误并保持原设置不变
+func (p *ExtractionPatterns) SetNameWhitespace(policy string) error {
+	switch ws := strings.ToLower(strings.TrimSpace(policy)); ws {
+	case "":
+		p.nameWhitespace = NameWhitespaceCompact
+	case NameWhitespaceCompact, NameWhitespaceCollapse:
+		p.nameWhitespace = ws
+	default:
+		return fmt.Errorf("无效的姓名空白处理方式 %q (应为 %s 或 %s)", policy, NameWhitespaceCompact, NameWhitespaceCollapse)
+	}
+	return nil
+}
+
+// cleanPartyName 按 p.nameWhitespace 清理当事人姓名中的换行与空白 (识别结果常在姓名中间断行或插入空格)。
+// 无论姓名由哪条提取路径得到，写入记录前都经过这里
+func (p *ExtractionPatterns) cleanPartyName(name string) string {
+	words := strings.Fields(name)
+	if p.nameWhitespace == NameWhitespaceCollapse || len(words) < 2 {
+		return strings.Join(words, " ")
+	}
+
+	var b strings.Builder
+	b.WriteString(words[0])
+	for i, w := range words[1:] {
+		prev, _ := utf8.DecodeLastRuneInString(words[i])
+		next, _ := utf8.DecodeRuneInString(w)
+		if isLatinOrDigit(prev) && isLatinOrDigit(next) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// isLatinOrDigit 判断 r 是否为拉丁字母或阿拉伯数字
+func isLatinOrDigit(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,52 @@
+package extractor
+
+import "testing"
+
+func TestCleanPartyName(t *testing.T) {
+	tests := []struct{ policy, in, want string }{
+		{NameWhitespaceCompact, " 张 三\r\n", "张三"},
+		{NameWhitespaceCompact, "某某\n科技 有限公司", "某某科技有限公司"},
+		{NameWhitespaceCompact, "John \n Smith", "John Smith"},
+		{NameWhitespaceCompact, "Acme 贸易 Ltd", "Acme贸易Ltd"},
+		{NameWhitespaceCollapse, "张 三\r\n李\t四", "张 三 李 四"},
+	}
+	p := DefaultPatterns
+	for _, tt := range tests {
+		if err := p.SetNameWhitespace(tt.policy); err != nil {
+			t.Fatal(err)
+		}
+		if got := p.cleanPartyName(tt.in); got != tt.want {
+			t.Errorf("%s: cleanPartyName(%q) = %q, want %q", tt.policy, tt.in, got, tt.want)
+		}
+	}
+	if err := p.SetNameWhitespace("strip"); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+	if got := DefaultPatterns.cleanPartyName("张 三"); got != "张三" {
+		t.Errorf("DefaultPatterns should keep the compact policy, got %q", got)
+	}
+}
+
+// 被告姓名之后没有截止关键词或标点时走截断兜底分支，姓名中间的换行与空白同样需要清理
+func TestExtractText_DefendantFallbackInteriorNewline(t *testing.T) {
+	records, err := NewExtractor(nil).ExtractText("民事起诉状\n原告：李四，男\n被告：王\r\n 五\n", nil)
+	if err != nil {
+		t.Fatalf("ExtractText returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["defendant"]; got != "王五" {
+		t.Errorf("defendant: expected %q, got %q", "王五", got)
+	}
+}
+
+func TestParseMarkdown_DefendantNextLineWhitespace(t *testing.T) {
+	records := ParseMarkdown("## 当事人\n被告：\n赵 六\n## 诉讼请求\n1. 归还借款")
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+	if got := records[0]["defendant"]; got != "赵六" {
+		t.Errorf("defendant: expected %q, got %q", "赵六", got)
+	}
+}
@@ -0,0 +1,71 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoData 识别引擎调用成功，但按结构解析未得到任何记录
+var ErrNoData = errors.New("识别结果中未解析出任何记录")
+
+// NoDataError 识别引擎未解析出记录时返回，携带各页识别出的原始文字，供本地解析兜底 (见 ParseRawTextOnEmpty)。
+// errors.Is(err, ErrNoData) 成立
+type NoDataError struct {
+	Provider string
+	// Pages 各页识别出的原始文字 (可能含 Markdown 与 HTML 标记)，引擎未返回文字时为空
+	Pages []string
+}
+
+func (e *NoDataError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Provider, ErrNoData)
+}
+
+// Unwrap 使 errors.Is(err, ErrNoData) 成立
+func (e *NoDataError) Unwrap() error {
+	return ErrNoData
+}
+
+// hasText 原始文字中是否有非空白内容
+func (e *NoDataError) hasText() bool {
+	for _, p := range e.Pages {
+		if strings.TrimSpace(p) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRawText 将识别引擎未能结构化解析的原始文字按本地文本规则提取：去除 HTML 与 Markdown 标记后各页顺序拼接
+// (案件信息可能跨页)，只有一页时记录页码为 1
+func (e *Extractor) parseRawText(noData *NoDataError) []Record {
+	cleaned := make([]string, 0, len(noData.Pages))
+	for _, p := range noData.Pages {
+		p = strings.NewReplacer("**", "", "&nbsp;", " ", "|", " ").Replace(stripHTML(p))
+		var lines []string
+		for _, line := range strings.Split(p, "\n") {
+			lines = append(lines, strings.TrimLeft(line, "# "))
+		}
+		cleaned = append(cleaned, strings.Join(lines, "\n"))
+	}
+
+	var fields []string
+	for k := range PatternRegistry {
+		fields = append(fields, k)
+	}
+	records := e.parseCases(strings.Join(cleaned, "\n"), fields)
+	for _, r := range records {
+		if len(noData.Pages) == 1 {
+			r["page"] = "1"
+		}
+		warnings := []string{rawTextWarning}
+		if w := r[WarningsKey]; w != "" {
+			warnings = append([]string{w}, warnings...)
+		}
+		r[WarningsKey] = strings.Join(warnings, "；")
+	}
+	return records
+}
+
+// rawTextWarning 按原始文字在本地提取的记录附带的提示
+const rawTextWarning = "识别结果未能按结构解析，已按原始文字在本地提取，请核对"
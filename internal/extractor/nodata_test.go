@@ -0,0 +1,77 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"legal-extractor/internal/config"
+)
+
+// noDataText 识别引擎未能结构化解析、但原始文字中包含完整案件信息的识别结果
+const noDataText = "民事起诉状\n原告：李四，男\n被告：张三，男，汉族，身份证号码：110101199001011237\n诉讼请求：\n1. 判令被告偿还借款 10000 元\n"
+
+func TestExtractData_NoDataParsesRawTextBeforeFallback(t *testing.T) {
+	primary := &MockOCRProvider{Err: &NoDataError{Provider: "mock", Pages: []string{noDataText}}}
+	backup := &MockOCRProvider{Records: []Record{{"defendant": "王五", "page": "1"}}}
+	data := readFixture(t, "scanned.pdf")
+
+	e := NewExtractor(nil)
+	e.SetOCRProvider(primary)
+	e.SetFallbackOCRProviders(backup)
+	e.FallbackOnEmpty = true
+	e.ParseRawTextOnEmpty = true
+	records, err := e.ExtractData(data, "scanned.pdf", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 || records[0]["defendant"] != "张三" || records[0]["idNumber"] != "110101199001011237" {
+		t.Fatalf("Expected the record recovered from the raw text, got %v", records)
+	}
+	if !strings.Contains(records[0][WarningsKey], rawTextWarning) {
+		t.Errorf("Expected the raw-text warning, got %q", records[0][WarningsKey])
+	}
+	if primary.Calls() != 1 || backup.Calls() != 0 {
+		t.Errorf("Expected a single provider call, got %d primary and %d backup calls", primary.Calls(), backup.Calls())
+	}
+
+	// 关闭本地解析后直接改用备用引擎
+	e = NewExtractor(nil)
+	e.SetOCRProvider(primary)
+	e.SetFallbackOCRProviders(backup)
+	e.FallbackOnEmpty = true
+	records, err = e.ExtractData(data, "scanned.pdf", nil, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 || records[0]["defendant"] != "王五" || backup.Calls() != 1 {
+		t.Errorf("Expected the backup provider's record, got %v (%d backup calls)", records, backup.Calls())
+	}
+
+	// 两步都关闭时返回空结果而非错误
+	e = NewExtractor(nil)
+	e.SetOCRProvider(primary)
+	records, err = e.ExtractData(data, "scanned.pdf", nil, nil)
+	if err != nil || len(records) != 0 {
+		t.Errorf("Expected no records and no error, got %v, %v", records, err)
+	}
+}
+
+func TestBaiduClient_ParseDocument_NoData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error_code":0,"result":{"layoutParsingResults":[{"markdown":{"text":"<div>附件清单</div>"}}]}}`)
+	}))
+	defer srv.Close()
+
+	_, err := newTestBaiduClient(srv, config.BaiduConfig{}).ParseDocument([]byte("scan"), false, nil)
+	var noData *NoDataError
+	if !errors.As(err, &noData) || !errors.Is(err, ErrNoData) {
+		t.Fatalf("Expected a NoDataError, got %v", err)
+	}
+	if len(noData.Pages) != 1 || noData.Pages[0] != "<div>附件清单</div>" {
+		t.Errorf("Expected the raw page text to be kept, got %q", noData.Pages)
+	}
+}
@@ -0,0 +1,167 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// OCRProvider 云端识别引擎的统一接口，扫描件 PDF 与图片均经由此接口解析
+type OCRProvider interface {
+	// Name 返回引擎名称 (用于日志与版本信息，不含密钥)
+	Name() string
+	// ParseDocument 解析文档并返回按页标注的记录
+	ParseDocument(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]Record, error)
+}
+
+// OCRPage 识别引擎返回的一页识别原文
+type OCRPage struct {
+	// Page 在所识别文档中的页码，从 1 开始
+	Page     int
+	Markdown string
+	// Angle 云端检测到的页面旋转角度，-1 表示未检测方向
+	Angle int
+}
+
+// PageRecognizer 由能返回各页识别原文的引擎实现。提取器据此在本地按页解析并缓存原文，
+// 改选字段后可直接重新解析而无需再次识别 (见 ReparseFields)
+type PageRecognizer interface {
+	RecognizePages(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]OCRPage, error)
+}
+
+// ContextPageRecognizer 由可接收 ctx 的 PageRecognizer 实现：ctx 取消时中止在途请求，
+// 其中的追踪上下文随请求头 (traceparent) 传给识别服务
+type ContextPageRecognizer interface {
+	RecognizePagesContext(ctx context.Context, fileData []byte, isPdf bool, onProgress ProgressCallback) ([]OCRPage, error)
+}
+
+// parseOCRPages 按 p 中的规则逐页解析识别原文，标注页码与旋转角度；没有解析出任何记录时返回携带原文的 NoDataError
+func (p *ExtractionPatterns) parseOCRPages(logger *slog.Logger, provider string, pages []OCRPage, onProgress ProgressCallback) ([]Record, error) {
+	logger.Info("开始按页提取法律实体", "provider", provider, "pages", len(pages))
+	var allRecords []Record
+	for i, page := range pages {
+		if onProgress != nil {
+			// 增加微小延迟 (50ms)，让前端有足够时间渲染进度条的跳动，避免瞬间完成
+			time.Sleep(50 * time.Millisecond)
+			onProgress(i+1, len(pages), fmt.Sprintf("正在结构化提取第 %d/%d 页的法律信息...", i+1, len(pages)))
+		}
+		pageNum := page.Page
+		if pageNum <= 0 {
+			pageNum = i + 1
+		}
+		if page.Angle > 0 {
+			logger.Warn("检测到页面旋转，已由云端自动校正", "page", pageNum, "angle", page.Angle)
+		}
+		for _, rec := range p.parseMarkdown(page.Markdown) {
+			// 标注准确的页码
+			if rec["page"] == "" {
+				rec["page"] = strconv.Itoa(pageNum)
+			}
+			if page.Angle > 0 {
+				rec[AngleKey] = strconv.Itoa(page.Angle)
+			}
+			allRecords = append(allRecords, rec)
+		}
+	}
+
+	logger.Info("数据提取完成", "recordCount", len(allRecords))
+	if len(allRecords) == 0 {
+		noData := &NoDataError{Provider: provider}
+		for _, page := range pages {
+			noData.Pages = append(noData.Pages, page.Markdown)
+		}
+		return nil, noData
+	}
+	return allRecords, nil
+}
+
+// Name 实现 OCRProvider
+func (c *BaiduClient) Name() string {
+	if c.name != "" {
+		return c.name
+	}
+	return "baidu"
+}
+
+// mockSampleMarkdown 离线开发时 MockOCRProvider 默认返回的识别结果
+const mockSampleMarkdown = `# 民事起诉状
+
+被告：张三，住址：北京市朝阳区
+
+身份证号码：110101199001011234
+
+诉讼请求：
+
+一、请求判令被告偿还借款10000元；
+
+事实与理由：
+
+2023年1月1日，被告向原告借款10000元，至今未还。
+
+此致
+`
+
+// MockOCRProvider 用于测试与离线开发的 OCR 引擎，不访问网络
+// 若设置了 Records 则直接返回；否则将 Pages 中的每页 Markdown 按真实引擎的流程解析
+type MockOCRProvider struct {
+	Records []Record
+	Pages   []string
+	Err     error
+
+	calls int32
+}
+
+// NewMockOCRProvider 创建返回示例起诉状的模拟引擎
+func NewMockOCRProvider() *MockOCRProvider {
+	return &MockOCRProvider{Pages: []string{mockSampleMarkdown}}
+}
+
+// Name 实现 OCRProvider
+func (m *MockOCRProvider) Name() string {
+	return "mock"
+}
+
+// Calls 返回 ParseDocument 被调用的次数
+func (m *MockOCRProvider) Calls() int {
+	return int(atomic.LoadInt32(&m.calls))
+}
+
+// ParseDocument 实现 OCRProvider
+func (m *MockOCRProvider) ParseDocument(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]Record, error) {
+	atomic.AddInt32(&m.calls, 1)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	if m.Records != nil {
+		records := make([]Record, 0, len(m.Records))
+		for _, rec := range m.Records {
+			clone := make(Record, len(rec))
+			for k, v := range rec {
+				clone[k] = v
+			}
+			records = append(records, clone)
+		}
+		return records, nil
+	}
+
+	var allRecords []Record
+	for i, pageMd := range m.Pages {
+		if onProgress != nil {
+			onProgress(i+1, len(m.Pages), fmt.Sprintf("正在结构化提取第 %d/%d 页的法律信息...", i+1, len(m.Pages)))
+		}
+		for _, rec := range ParseMarkdown(pageMd) {
+			if rec["page"] == "" {
+				rec["page"] = fmt.Sprintf("%d", i+1)
+			}
+			allRecords = append(allRecords, rec)
+		}
+	}
+	if len(allRecords) == 0 {
+		return nil, &NoDataError{Provider: m.Name(), Pages: m.Pages}
+	}
+	return allRecords, nil
+}
@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// pdfPageSource 逐页切出 PDF 的单页文件：PDF 只解析一次，每次只生成一页的数据
+type pdfPageSource struct {
+	ctx *model.Context
+}
+
+// newPdfPageSource 解析 PDF 结构，供 page 逐页切分
+func newPdfPageSource(fileData []byte) (*pdfPageSource, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTPAGES
+	ctx, err := api.ReadValidateAndOptimize(bytes.NewReader(fileData), conf)
+	if err != nil {
+		return nil, err
+	}
+	return &pdfPageSource{ctx: ctx}, nil
+}
+
+// page 返回第 n 页 (从 1 开始) 单独构成的 PDF
+func (s *pdfPageSource) page(n int) ([]byte, error) {
+	r, err := api.ExtractPage(s.ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// parsePagesWithProvider 逐页识别扫描版 PDF：切出一页、送识别、记下该页的记录后再处理下一页，
+// 同一时刻只有一页的数据 (及其 base64 编码的请求体) 驻留内存，内存占用与文档页数无关。
+// 记录与识别原文的页码改为其在原文档中的页码
+func (e *Extractor) parsePagesWithProvider(ctx context.Context, p OCRProvider, fileData []byte, pages int, onProgress ProgressCallback) ([]Record, []OCRPage, error) {
+	src, err := newPdfPageSource(fileData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("PDF 逐页切分失败: %w", err)
+	}
+
+	e.logger.Info("启用逐页识别模式", "provider", p.Name(), "pages", pages)
+	var records []Record
+	var text []OCRPage
+	noData := &NoDataError{Provider: p.Name()}
+	for n := 1; n <= pages; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		pageData, err := src.page(n)
+		if err != nil {
+			return nil, nil, fmt.Errorf("切分第 %d 页失败: %w", n, err)
+		}
+		pageRecords, pageText, err := e.parseWithProvider(ctx, p, pageData, true, 1, nil)
+		var pageNoData *NoDataError
+		if errors.As(err, &pageNoData) {
+			// 单页没有解析出记录很常见 (如证据页)，留下原始文字，整份文档都没有记录时再交给兜底处理
+			noData.Pages = append(noData.Pages, pageNoData.Pages...)
+			err = nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("第 %d 页识别失败: %w", n, err)
+		}
+		for _, t := range pageText {
+			t.Page = n
+			text = append(text, t)
+		}
+		for _, r := range pageRecords {
+			r["page"] = strconv.Itoa(n)
+			records = append(records, r)
+		}
+		if onProgress != nil {
+			onProgress(n, pages, fmt.Sprintf("已完成第 %d/%d 页的识别", n, pages))
+		}
+	}
+	if len(records) == 0 {
+		return nil, text, noData
+	}
+	return records, text, nil
+}
@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// sizeRecordingProvider 记录每次识别调用收到的文件大小，每次调用返回一条记录
+type sizeRecordingProvider struct {
+	mu    sync.Mutex
+	sizes []int
+}
+
+func (p *sizeRecordingProvider) Name() string { return "size-recorder" }
+
+func (p *sizeRecordingProvider) ParseDocument(fileData []byte, isPdf bool, onProgress ProgressCallback) ([]Record, error) {
+	p.mu.Lock()
+	p.sizes = append(p.sizes, len(fileData))
+	p.mu.Unlock()
+	return []Record{{"defendant": "张三", "page": "1"}}, nil
+}
+
+func (p *sizeRecordingProvider) maxSize() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := 0
+	for _, n := range p.sizes {
+		m = max(m, n)
+	}
+	return m
+}
+
+func TestStreamOCRPages_BoundsPayload(t *testing.T) {
+	data, pages := mergedFixture(t, "scanned.pdf", 40)
+	provider := &sizeRecordingProvider{}
+	e := NewExtractor(nil)
+	e.SetOCRProvider(provider)
+	e.StreamOCRPages = true
+
+	records, err := e.ExtractData(data, "large_scan.pdf", FieldKeys(), nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(provider.sizes) != pages {
+		t.Fatalf("Expected one OCR call per page (%d), got %d", pages, len(provider.sizes))
+	}
+	// 每次送识别的只有一页，远小于整份文件
+	if largest := provider.maxSize(); largest*10 > len(data) {
+		t.Errorf("Largest OCR payload %d bytes is not bounded by a single page (document %d bytes)", largest, len(data))
+	}
+	if len(records) != pages {
+		t.Fatalf("Expected %d records, got %d", pages, len(records))
+	}
+	for i, r := range records {
+		if want := strconv.Itoa(i + 1); r["page"] != want {
+			t.Errorf("record %d: expected page %s, got %s", i, want, r["page"])
+		}
+	}
+}
+
+// BenchmarkStreamOCRPages 比较整份提交与逐页提交时单次送识别的最大数据量 (peak-payload-bytes)，
+// 前者随页数线性增长，后者与页数无关
+func BenchmarkStreamOCRPages(b *testing.B) {
+	data, _ := mergedFixture(b, "scanned.pdf", 100)
+	for _, stream := range []bool{false, true} {
+		name := "whole"
+		if stream {
+			name = "per-page"
+		}
+		b.Run(name, func(b *testing.B) {
+			var peak int
+			for i := 0; i < b.N; i++ {
+				provider := &sizeRecordingProvider{}
+				e := NewExtractor(nil)
+				e.SetOCRProvider(provider)
+				e.StreamOCRPages = stream
+				if _, err := e.ocrWithFallback(context.Background(), data, true, 200, nil); err != nil {
+					b.Fatal(err)
+				}
+				peak = provider.maxSize()
+			}
+			b.ReportMetric(float64(peak), "peak-payload-bytes")
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package extractor
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldOffsets 字段到其在源文本中 [起, 止) 字符 (rune) 偏移的映射
+type FieldOffsets map[string][2]int
+
+// ParseTextOffsets 解析纯文本，并返回与记录一一对应的字段来源位置 (未能定位的字段不在映射中)；
+// fields 为空时提取全部字段。返回的是未经前缀清理、质量评分、转换与必填检查的原始解析结果
+func (e *Extractor) ParseTextOffsets(text string, fields []string) ([]Record, []FieldOffsets) {
+	if len(fields) == 0 {
+		for k := range PatternRegistry {
+			fields = append(fields, k)
+		}
+	}
+	tracker := &offsetTracker{text: text}
+	return e.parseCasesTracked(text, fields, tracker), tracker.offsets
+}
+
+// offsetTracker 记录字段在源文本中的位置，为 nil 时不做任何事
+type offsetTracker struct {
+	text    string
+	cur     FieldOffsets
+	offsets []FieldOffsets
+}
+
+// reset 开始记录一个新分段的字段位置
+func (t *offsetTracker) reset() {
+	if t == nil {
+		return
+	}
+	t.cur = make(FieldOffsets)
+}
+
+// set 以字节区间 [start, end) 记录字段位置，会去掉区间两端的空白
+func (t *offsetTracker) set(field string, start, end int) {
+	if t == nil || start < 0 || end > len(t.text) || start >= end {
+		return
+	}
+	span := t.text[start:end]
+	start += len(span) - len(strings.TrimLeft(span, " \t\r\n"))
+	end -= len(span) - len(strings.TrimRight(span, " \t\r\n"))
+	if start >= end {
+		return
+	}
+	runeStart := utf8.RuneCountInString(t.text[:start])
+	runeEnd := runeStart + utf8.RuneCountInString(t.text[start:end])
+	t.cur[field] = [2]int{runeStart, runeEnd}
+}
+
+// find 在 [from, len) 范围内查找 value 并记录其位置
+func (t *offsetTracker) find(field string, from int, value string) {
+	if t == nil || value == "" || from < 0 || from > len(t.text) {
+		return
+	}
+	if idx := strings.Index(t.text[from:], value); idx >= 0 {
+		t.set(field, from+idx, from+idx+len(value))
+	}
+}
+
+// snapshot 复制当前分段已记录的位置，跳过 drop 中的字段
+func (t *offsetTracker) snapshot(drop ...string) FieldOffsets {
+	if t == nil {
+		return nil
+	}
+	out := make(FieldOffsets, len(t.cur))
+	for k, v := range t.cur {
+		if !slices.Contains(drop, k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// restore 以 snapshot 的结果替换当前分段的位置
+func (t *offsetTracker) restore(offsets FieldOffsets) {
+	if t == nil {
+		return
+	}
+	t.cur = maps.Clone(offsets)
+}
+
+// add 当前分段输出一条记录，保存其字段位置
+func (t *offsetTracker) add() {
+	if t == nil {
+		return
+	}
+	t.offsets = append(t.offsets, t.snapshot())
+}
+
+// skipNewlines 将去除换行后的字节下标换算回原文下标
+func skipNewlines(s string, cleanIdx int) int {
+	seen := 0
+	for i := 0; i < len(s); i++ {
+		if seen == cleanIdx {
+			return i
+		}
+		if s[i] != '\n' {
+			seen++
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,85 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+)
+
+// RecordPage 分页后的提取结果，用于合并了大量案件的文档，避免一次返回数千条记录
+type RecordPage struct {
+	Records []Record `json:"records"`
+	// Total 分页前的记录总数
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	// Limit 每页条数，0 表示不限
+	Limit int `json:"limit,omitempty"`
+}
+
+// PageRecords 取 records 中从 offset 起的至多 limit 条记录 (limit <= 0 表示不限条数)，offset 超出总数时返回空页
+func PageRecords(records []Record, offset, limit int) RecordPage {
+	offset, limit = max(offset, 0), max(limit, 0)
+	page := RecordPage{Total: len(records), Offset: offset, Limit: limit, Records: []Record{}}
+	if offset >= len(records) {
+		return page
+	}
+	end := len(records)
+	if limit > 0 {
+		end = min(end, offset+limit)
+	}
+	page.Records = records[offset:end]
+	return page
+}
+
+// ExtractDataPage 同 ExtractDataContext，但只返回从 offset 起的至多 limit 条记录及记录总数。
+// 解析结果按文件内容缓存，依次翻页不会重复解析
+func (e *Extractor) ExtractDataPage(ctx context.Context, fileData []byte, fileName string, fields []string, offset, limit int, onProgress ProgressCallback) (RecordPage, error) {
+	records, err := e.ExtractDataContext(ctx, fileData, fileName, fields, onProgress)
+	if err != nil {
+		return RecordPage{}, err
+	}
+	return PageRecords(records, offset, limit), nil
+}
+
+// ExtractStreamPage 同 ExtractStream，但跳过前 offset 条记录，发送 limit 条后即停止解析 (limit <= 0 表示不限条数)。
+// 流式输出时记录总数事先未知，需要总数时使用 ExtractDataPage
+func (e *Extractor) ExtractStreamPage(ctx context.Context, fileData []byte, fileName string, fields []string, offset, limit int) (<-chan Record, <-chan error) {
+	out := make(chan Record)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(out)
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		records, streamErrs := e.ExtractStream(streamCtx, fileData, fileName, fields)
+
+		seen, sent := 0, 0
+		for r := range records {
+			seen++
+			if seen <= offset || (limit > 0 && sent >= limit) {
+				continue
+			}
+			select {
+			case out <- r:
+				sent++
+			case <-ctx.Done():
+			}
+			if limit > 0 && sent >= limit {
+				// 本页已满，停止剩余页面的解析
+				cancel()
+			}
+		}
+		err := <-streamErrs
+		if errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			err = nil
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return out, errs
+}
@@ -0,0 +1,61 @@
+package extractor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestExtractDataPage(t *testing.T) {
+	data, pages := mergedFixture(t, "complaint.pdf", 5)
+	e := NewExtractor(nil)
+	all, err := e.ExtractData(data, "merged.pdf", allFields, nil)
+	if err != nil || len(all) != pages {
+		t.Fatalf("Expected %d records from ExtractData, got %d (%v)", pages, len(all), err)
+	}
+
+	page, err := e.ExtractDataPage(context.Background(), data, "merged.pdf", allFields, 2, 2, nil)
+	if err != nil {
+		t.Fatalf("ExtractDataPage error: %v", err)
+	}
+	if page.Total != pages {
+		t.Errorf("Expected total %d, got %d", pages, page.Total)
+	}
+	if !reflect.DeepEqual(page.Records, all[2:4]) {
+		t.Errorf("Expected records 3-4, got %v", page.Records)
+	}
+
+	// 流式输出取同一页
+	records, errs := e.ExtractStreamPage(context.Background(), data, "merged.pdf", allFields, 2, 2)
+	var streamed []Record
+	for r := range records {
+		streamed = append(streamed, r)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("ExtractStreamPage error: %v", err)
+	}
+	if !reflect.DeepEqual(streamed, all[2:4]) {
+		t.Errorf("Expected streamed records 3-4, got %v", streamed)
+	}
+}
+
+func TestPageRecords_Bounds(t *testing.T) {
+	records := []Record{{"page": "1"}, {"page": "2"}, {"page": "3"}}
+	tests := []struct {
+		offset, limit int
+		want          int
+	}{
+		{0, 0, 3},
+		{1, 0, 2},
+		{2, 5, 1},
+		{3, 2, 0},
+		{-1, 2, 2},
+	}
+	for _, tt := range tests {
+		page := PageRecords(records, tt.offset, tt.limit)
+		if len(page.Records) != tt.want || page.Total != 3 {
+			t.Errorf("PageRecords(offset=%d, limit=%d) = %d records (total %d), expected %d (total 3)",
+				tt.offset, tt.limit, len(page.Records), page.Total, tt.want)
+		}
+	}
+}
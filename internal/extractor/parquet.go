@@ -0,0 +1,250 @@
+package extractor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// Parquet output is written by a small built-in encoder rather than a full
+// Parquet library: every column is a REQUIRED UTF8 string (missing fields are
+// empty strings), stored PLAIN and uncompressed in a single row group with one
+// data page per column. That is all flat extraction records need, and the
+// result reads directly into pandas, Spark or DuckDB.
+
+const parquetMagic = "PAR1"
+
+// Parquet enum values from the format specification (parquet.thrift)
+const (
+	parquetTypeByteArray     = 6
+	parquetRepetitionRequire = 0
+	parquetConvertedUTF8     = 0
+	parquetEncodingPlain     = 0
+	parquetEncodingRLE       = 3
+	parquetCodecUncompressed = 0
+	parquetPageData          = 0
+)
+
+// ExportParquet exports records to a Parquet file
+func ExportParquet(path string, records []Record) error {
+	return ExportParquetWithOptions(path, records, DefaultExportOptions())
+}
+
+// ExportParquetWithOptions exports records to a Parquet file using the given options
+func ExportParquetWithOptions(path string, records []Record, opts ExportOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteParquet(file, records, opts); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteParquet writes records to w as a Parquet file. Columns follow the shared
+// export order and are named by field key (e.g. "defendant") so the schema stays
+// stable for downstream code; opts.Headers does not apply. The page column is kept.
+func WriteParquet(w io.Writer, records []Record, opts ExportOptions) error {
+	records = exportRecords(records, opts)
+	var keys []string
+	if len(records) > 0 {
+		keys, _ = exportColumns(records[0], true, ExportOptions{})
+	}
+
+	bw := bufio.NewWriter(w)
+	offset := int64(len(parquetMagic))
+	if _, err := bw.WriteString(parquetMagic); err != nil {
+		return err
+	}
+
+	var chunks []parquetChunk
+	var total int64
+	for _, k := range keys {
+		var data []byte
+		for _, r := range records {
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(r[k])))
+			data = append(data, r[k]...)
+		}
+		header := encodePageHeader(len(records), len(data))
+		if _, err := bw.Write(header); err != nil {
+			return err
+		}
+		if _, err := bw.Write(data); err != nil {
+			return err
+		}
+		size := int64(len(header) + len(data))
+		chunks = append(chunks, parquetChunk{name: k, offset: offset, size: size})
+		offset += size
+		total += size
+	}
+
+	footer := encodeFileMetaData(chunks, int64(len(records)), total)
+	if _, err := bw.Write(footer); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, uint32(len(footer))); err != nil {
+		return err
+	}
+	if _, err := bw.WriteString(parquetMagic); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// parquetChunk locates one column chunk within the file
+type parquetChunk struct {
+	name   string
+	offset int64
+	size   int64
+}
+
+func encodePageHeader(numValues, dataSize int) []byte {
+	var t thriftWriter
+	t.i32(1, parquetPageData)
+	t.i32(2, int32(dataSize))
+	t.i32(3, int32(dataSize))
+	t.beginStruct(5) // data_page_header
+	t.i32(1, int32(numValues))
+	t.i32(2, parquetEncodingPlain)
+	t.i32(3, parquetEncodingRLE)
+	t.i32(4, parquetEncodingRLE)
+	t.endStruct()
+	t.stop()
+	return t.buf
+}
+
+func encodeFileMetaData(chunks []parquetChunk, numRows, totalSize int64) []byte {
+	var t thriftWriter
+	t.i32(1, 1) // version
+
+	t.beginList(2, thriftStruct, len(chunks)+1) // schema
+	t.beginListStruct()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(chunks)))
+	t.endListStruct()
+	for _, c := range chunks {
+		t.beginListStruct()
+		t.i32(1, parquetTypeByteArray)
+		t.i32(3, parquetRepetitionRequire)
+		t.binary(4, c.name)
+		t.i32(6, parquetConvertedUTF8)
+		t.endListStruct()
+	}
+
+	t.i64(3, numRows)
+
+	if len(chunks) == 0 {
+		t.beginList(4, thriftStruct, 0) // no records: no row groups
+	} else {
+		t.beginList(4, thriftStruct, 1) // row_groups
+		t.beginListStruct()
+		t.beginList(1, thriftStruct, len(chunks))
+		for _, c := range chunks {
+			t.beginListStruct()
+			t.i64(2, c.offset)
+			t.beginStruct(3) // meta_data
+			t.i32(1, parquetTypeByteArray)
+			t.beginList(2, thriftI32, 2)
+			t.listI32(parquetEncodingPlain)
+			t.listI32(parquetEncodingRLE)
+			t.beginList(3, thriftBinary, 1)
+			t.listBinary(c.name)
+			t.i32(4, parquetCodecUncompressed)
+			t.i64(5, numRows)
+			t.i64(6, c.size)
+			t.i64(7, c.size)
+			t.i64(9, c.offset)
+			t.endStruct()
+			t.endListStruct()
+		}
+		t.i64(2, totalSize)
+		t.i64(3, numRows)
+		t.endListStruct()
+	}
+
+	t.binary(6, "legal-extractor")
+	t.stop()
+	return t.buf
+}
+
+// Thrift compact protocol type ids
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the subset of the Thrift compact protocol used by the Parquet footer
+type thriftWriter struct {
+	buf    []byte
+	last   int16
+	nested []int16 // last field id of each enclosing struct
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.buf = binary.AppendVarint(t.buf, int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.buf = binary.AppendVarint(t.buf, v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.listBinary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginListStruct()
+}
+
+func (t *thriftWriter) endStruct() { t.endListStruct() }
+
+// beginListStruct starts a struct element of a list (no field header)
+func (t *thriftWriter) beginListStruct() {
+	t.nested = append(t.nested, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endListStruct() {
+	t.stop()
+	t.last = t.nested[len(t.nested)-1]
+	t.nested = t.nested[:len(t.nested)-1]
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elem)
+	} else {
+		t.buf = append(t.buf, 0xF0|elem)
+		t.buf = binary.AppendUvarint(t.buf, uint64(size))
+	}
+}
+
+func (t *thriftWriter) listI32(v int32) { t.buf = binary.AppendVarint(t.buf, int64(v)) }
+
+func (t *thriftWriter) listBinary(s string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+func (t *thriftWriter) stop() { t.buf = append(t.buf, 0) }
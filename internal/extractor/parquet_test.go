@@ -0,0 +1,217 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// parquetRecords 导出样例，testdata/records.parquet 即其导出结果
+var parquetRecords = []Record{
+	{"page": "1", "defendant": "张三", "idNumber": "110101199001011234", "request": "偿还借款", "_warnings": "ignored"},
+	{"page": "2", "defendant": "李四", "request": "支付货款"},
+	{"page": "3", "defendant": "王五", "idNumber": "110101199003071234", "request": ""},
+}
+
+func TestExportParquet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	if err := ExportParquet(path, parquetRecords); err != nil {
+		t.Fatalf("ExportParquet: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	columns, rows := readParquet(t, data)
+	if rows != 3 {
+		t.Errorf("Expected 3 rows, got %d", rows)
+	}
+	if want := []string{"page", "defendant", "idNumber", "request"}; fmt.Sprint(columnNames(columns)) != fmt.Sprint(want) {
+		t.Errorf("Expected columns %v, got %v", want, columnNames(columns))
+	}
+	if got := columns[1].values; fmt.Sprint(got) != "[张三 李四 王五]" {
+		t.Errorf("defendant column = %v", got)
+	}
+	if got := columns[2].values[1]; got != "" {
+		t.Errorf("Expected a missing idNumber to be empty, got %q", got)
+	}
+}
+
+// testdata/records.parquet 已用独立实现的读取器 (github.com/parquet-go/parquet-go v0.32.0 与 pyarrow) 核对：
+// 模式为 4 列 required binary (STRING)，单个行组共 3 行，各列数据页均可解码，逐行取值与 parquetRecords 一致。
+// 写入逻辑变化导致输出不同时，需重新导出，并在装有 pyarrow 的环境中通过 TestWriteParquet_PyArrow 后再更新该文件
+func TestWriteParquet_ReferenceFixture(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, parquetRecords, DefaultExportOptions()); err != nil {
+		t.Fatalf("WriteParquet: %v", err)
+	}
+	if want := readFixture(t, "records.parquet"); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("WriteParquet output (%d bytes) differs from the reference-checked fixture (%d bytes)", buf.Len(), len(want))
+	}
+}
+
+// pyarrowReadScript 用 pyarrow 读取文件，输出各列的名称、类型、是否可空以及全部行
+const pyarrowReadScript = `import json, sys
+import pyarrow.parquet as pq
+t = pq.read_table(sys.argv[1])
+print(json.dumps({"schema": [[f.name, str(f.type), f.nullable] for f in t.schema], "rows": t.to_pylist()}))`
+
+// TestWriteParquet_PyArrow 用 pyarrow 这一独立实现读取导出结果；未安装 python3 或 pyarrow 时跳过
+// (pip install pyarrow 后即可运行)
+func TestWriteParquet_PyArrow(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found")
+	}
+	if err := exec.Command(python, "-c", "import pyarrow.parquet").Run(); err != nil {
+		t.Skip("pyarrow not installed")
+	}
+
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	if err := ExportParquet(path, parquetRecords); err != nil {
+		t.Fatalf("ExportParquet: %v", err)
+	}
+	out, err := exec.Command(python, "-c", pyarrowReadScript, path).Output()
+	if err != nil {
+		t.Fatalf("pyarrow failed to read the file: %v", err)
+	}
+	var got struct {
+		Schema [][]any             `json:"schema"`
+		Rows   []map[string]string `json:"rows"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("Invalid reader output %s: %v", out, err)
+	}
+
+	wantSchema := `[[page string false] [defendant string false] [idNumber string false] [request string false]]`
+	if fmt.Sprint(got.Schema) != wantSchema {
+		t.Errorf("schema = %v, want %s", got.Schema, wantSchema)
+	}
+	if len(got.Rows) != len(parquetRecords) {
+		t.Fatalf("Expected %d rows, got %d", len(parquetRecords), len(got.Rows))
+	}
+	for i, r := range parquetRecords {
+		for _, k := range []string{"page", "defendant", "idNumber", "request"} {
+			if got.Rows[i][k] != r[k] {
+				t.Errorf("row %d: %s = %q, want %q", i, k, got.Rows[i][k], r[k])
+			}
+		}
+	}
+}
+
+type parquetColumn struct {
+	name   string
+	values []string
+}
+
+func columnNames(cols []parquetColumn) []string {
+	var names []string
+	for _, c := range cols {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// readParquet 解析 WriteParquet 输出的文件：读取页脚的 FileMetaData，再按列块偏移解码 PLAIN 字符串
+func readParquet(t *testing.T, data []byte) ([]parquetColumn, int64) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-8-footerLen : len(data)-8]
+	meta := (&thriftReader{buf: footer}).readStruct()
+
+	numRows := meta[3].(int64)
+	rowGroup := meta[4].([]any)[0].(map[int16]any)
+	var cols []parquetColumn
+	for _, c := range rowGroup[1].([]any) {
+		md := c.(map[int16]any)[3].(map[int16]any)
+		name := md[3].([]any)[0].(string)
+		offset := md[9].(int64)
+
+		r := &thriftReader{buf: data[offset:]}
+		page := r.readStruct()
+		values := page[5].(map[int16]any)[1].(int64)
+		body := data[offset+int64(r.pos):]
+		col := parquetColumn{name: name}
+		for i := int64(0); i < values; i++ {
+			n := binary.LittleEndian.Uint32(body)
+			col.values = append(col.values, string(body[4:4+n]))
+			body = body[4+n:]
+		}
+		cols = append(cols, col)
+	}
+	return cols, numRows
+}
+
+// thriftReader 解码测试所需的 Thrift compact 协议子集
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		b := r.buf[r.pos]
+		r.pos++
+		if b == 0 {
+			return fields
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			id = int16(r.varint())
+		}
+		last = id
+		fields[id] = r.readValue(b & 0x0F)
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) any {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 5, 6:
+		return r.varint()
+	case 8:
+		n := int(r.uvarint())
+		s := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case 9:
+		h := r.buf[r.pos]
+		r.pos++
+		size := int(h >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.readValue(h & 0x0F)
+		}
+		return list
+	case 12:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported thrift type %d", typ))
+}
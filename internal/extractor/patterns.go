@@ -1,27 +1,216 @@
 package extractor
 
-import "regexp"
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
 
-// ExtractionPatterns holds the regex patterns used for parsing
+// ExtractionPatterns holds the regex patterns used for parsing. Each Extractor
+// parses with its own copy of DefaultPatterns carrying the configured overrides
+// (see the Set* methods), so extractors never share mutable parsing state;
+// a copy must not be modified once the extractor is in use.
 type ExtractionPatterns struct {
 	Split       *regexp.Regexp
 	DefStart    *regexp.Regexp
 	DefEnd      *regexp.Regexp
 	DefFallback *regexp.Regexp
+	ThirdParty  *regexp.Regexp
 	ID          *regexp.Regexp
+	Gender      *regexp.Regexp
+	Birthday    *regexp.Regexp
 	Request     *regexp.Regexp
 	Facts       *regexp.Regexp
+	Amount      *regexp.Regexp
+
+	// IDLoose is the fallback for ID: variant labels such as 公民身份号码, 居民身份证
+	// or 身份证 without 号码. Hits are only kept when the checksum validates, which
+	// rejects phone numbers and the like. nil disables the fallback.
+	IDLoose *regexp.Regexp
+
+	// CreditCode matches the 18-character 统一社会信用代码 of a corporate party,
+	// which has no 身份证号码
+	CreditCode *regexp.Regexp
+
+	// CaseCause matches a labeled 案由 ("案由：民间借贷纠纷"); AgentAuthority the
+	// scope of the counsel's mandate ("代理权限：特别授权")
+	CaseCause      *regexp.Regexp
+	AgentAuthority *regexp.Regexp
+
+	// Section anchors used when Request/Facts do not match, e.g. a missing 此致
+	// or sections in an unusual order. A section runs from its label to the
+	// next known label, a SectionEnd marker (see DefaultSectionEndAnchors) or the
+	// end of the document.
+	RequestLabel *regexp.Regexp
+	FactsLabel   *regexp.Regexp
+	SectionEnd   *regexp.Regexp
+
+	// Plaintiff-side block: the 原告/申请人 label, their counsel (委托诉讼代理人 and
+	// the like, not a 法定代理人) and the counsel's law firm. Phone matches a contact
+	// number within a party's block.
+	Plaintiff      *regexp.Regexp
+	PlaintiffAgent *regexp.Regexp
+	LawFirm        *regexp.Regexp
+	Phone          *regexp.Regexp
+
+	// Annex matches the title line of an annex form filed alongside a complaint
+	// (送达地址确认书, 授权委托书, ...). A segment is cut at its first annex so the
+	// form's addresses and agent details do not leak into the complaint's fields.
+	Annex *regexp.Regexp
+
+	// DefStopChars terminate a defendant name when they appear before any DefEnd keyword
+	DefStopChars string
+	// DefMaxRunes caps the defendant name when neither a DefEnd keyword nor a stop char is found
+	DefMaxRunes int
+
+	// aliases rewrites configured label aliases to the standard labels before
+	// OCR output is parsed (see SetFieldAliases); nil leaves the text as is
+	aliases *fieldAliases
+	// nameWhitespace is the whitespace policy for party names (see SetNameWhitespace)
+	nameWhitespace string
+}
+
+// DefaultDefStopKeywords end a defendant name, e.g. "张三，性别：男" or "张三 户籍地：…"
+var DefaultDefStopKeywords = []string{"性别", "生日", "出生", "公民身份", "居民身份", "身份证", "统一社会信用代码", "住址", "住所", "户籍", "联系电话", "电话", "现住", "案由"}
+
+// CompileDefEnd builds the defendant boundary regex from a list of stop keywords.
+// Whitespace may appear between the characters of a keyword (as in OCR output),
+// and a bare gender or birth year after a comma ("张三，男，" / "张三，1990年") also ends the name.
+func CompileDefEnd(keywords []string) *regexp.Regexp {
+	var alts []string
+	for _, kw := range keywords {
+		var chars []string
+		for _, r := range strings.TrimSpace(kw) {
+			chars = append(chars, regexp.QuoteMeta(string(r)))
+		}
+		if len(chars) > 0 {
+			alts = append(alts, strings.Join(chars, `\s*`))
+		}
+	}
+	boundary := `[,，]\s*(?:[男女]\s*[,，、；;]|\d{4}\s*年)`
+	if len(alts) > 0 {
+		boundary = `[,，、；;\s]*(?:` + strings.Join(alts, "|") + `)|` + boundary
+	}
+	return regexp.MustCompile(boundary)
+}
+
+// looseIDPattern is the default IDLoose pattern
+var looseIDPattern = regexp.MustCompile(`(?:公\s*民\s*身\s*份\s*号\s*码|(?:居\s*民\s*)?身\s*份\s*证\s*(?:号\s*码?)?)\s*[:：]?\s*(?:为|是)?\s*(\d{17}[\dXx])(?:\D|$)`)
+
+// SetLooseIDMatch enables or disables the IDLoose fallback
+func (p *ExtractionPatterns) SetLooseIDMatch(enabled bool) {
+	if enabled {
+		p.IDLoose = looseIDPattern
+	} else {
+		p.IDLoose = nil
+	}
+}
+
+// DefaultDefMaxRunes is the built-in DefMaxRunes, the former 50-byte cap, i.e. about 16 Chinese characters
+const DefaultDefMaxRunes = 16
+
+// SetDefendantMaxRunes replaces DefMaxRunes; zero or a negative value restores DefaultDefMaxRunes
+func (p *ExtractionPatterns) SetDefendantMaxRunes(n int) {
+	if n <= 0 {
+		n = DefaultDefMaxRunes
+	}
+	p.DefMaxRunes = n
+}
+
+// SetDefendantStopKeywords replaces the stop keywords used by DefEnd; an empty list restores the defaults
+func (p *ExtractionPatterns) SetDefendantStopKeywords(keywords []string) {
+	if len(keywords) == 0 {
+		keywords = DefaultDefStopKeywords
+	}
+	p.DefEnd = CompileDefEnd(keywords)
+}
+
+// DefaultSectionEndAnchors end the narrative of a section (事实与理由, 诉讼请求) when no
+// later section label comes first, in order: 此致, the signature line (具状人/起诉人/申请人,
+// optionally "(签名)"), a bare date line, and the title of the evidence section
+// (证据和证据来源, 证据清单, 证据目录, or a line that is just "证据："). Lines are
+// matched in multi-line mode, so ^ and $ refer to line boundaries.
+var DefaultSectionEndAnchors = []string{
+	`此\s*致`,
+	`^\s*(?:具\s*状\s*人|起\s*诉\s*人|申\s*请\s*人)\s*(?:[(（]?\s*签\s*[名字章]\s*[)）]?)?\s*[:：]`,
+	`^\s*\d{4}\s*年\s*\d{1,2}\s*月\s*\d{1,2}\s*日\s*$`,
+	`^\s*证\s*据\s*(?:和\s*证\s*据\s*来\s*源|清\s*单|目\s*录|[:：]|$)`,
 }
 
-// DefaultPatterns defines the standard patterns for legal documents
+// joinSectionEnd combines section end anchors into one multi-line regex; the
+// earliest match of any anchor ends the section
+func joinSectionEnd(anchors []string) string {
+	alts := make([]string, len(anchors))
+	for i, a := range anchors {
+		alts[i] = "(?:" + a + ")"
+	}
+	return "(?m)" + strings.Join(alts, "|")
+}
+
+// SetSectionEndAnchors replaces the section end anchors (regular expressions, see
+// DefaultSectionEndAnchors); an empty list restores the defaults. An anchor that
+// does not compile is reported and the current anchors are kept.
+func (p *ExtractionPatterns) SetSectionEndAnchors(anchors []string) error {
+	if len(anchors) == 0 {
+		anchors = DefaultSectionEndAnchors
+	}
+	var valid []string
+	for _, a := range anchors {
+		if strings.TrimSpace(a) == "" {
+			continue
+		}
+		if _, err := regexp.Compile("(?m)" + a); err != nil {
+			return fmt.Errorf("invalid section end anchor %q: %w", a, err)
+		}
+		valid = append(valid, a)
+	}
+	if len(valid) == 0 {
+		valid = DefaultSectionEndAnchors
+	}
+	p.SectionEnd = regexp.MustCompile(joinSectionEnd(valid))
+	return nil
+}
+
+// DefaultPatterns defines the standard patterns for legal documents. It is
+// never modified; configured overrides apply to an extractor's own copy.
 var DefaultPatterns = ExtractionPatterns{
-	Split:       regexp.MustCompile(`民\s*事\s*起\s*诉\s*状`),
-	DefStart:    regexp.MustCompile(`被\s*告\s*[:：]`),
-	DefEnd:      regexp.MustCompile(`[,，、；;、\s]*(?:性\s*别|生\s*日|身\s*份\s*证|住\s*址|联\s*系\s*电\s*话|现\s*住|案\s*由)|[。]|$`),
-	DefFallback: regexp.MustCompile(`被\s*告\s*[:：]\s*(.*?)\n`),
+	Split:       regexp.MustCompile(`民\s*事\s*起\s*诉\s*状|仲\s*裁\s*申\s*请\s*书`),
+	DefStart:    regexp.MustCompile(`(?:被\s*告|被\s*申\s*请\s*人)\s*[一二三四五六七八九十\d]*\s*[:：]`), // also 被告一： / 被告2：
+	DefEnd:      CompileDefEnd(DefaultDefStopKeywords),
+	DefFallback: regexp.MustCompile(`(?:被\s*告|被\s*申\s*请\s*人)\s*[一二三四五六七八九十\d]*\s*[:：]\s*(.*?)\n`),
+	ThirdParty:  regexp.MustCompile(`第\s*三\s*人\s*[:：]`),
 	ID:          regexp.MustCompile(`身\s*份\s*证\s*号\s*码\s*[:：]\s*([\dX]+)`),
-	Request:     regexp.MustCompile(`(?s)诉\s*讼\s*请\s*求\s*[:：]\s*(.*?)\s*事\s*实\s*与\s*理\s*由`),
+	Gender:      regexp.MustCompile(`性\s*别\s*[:：]\s*([男女])|^[,，\s]*([男女])[,，、\s]`),
+	Birthday:    regexp.MustCompile(`(\d{4})\s*[年\-./]\s*(\d{1,2})\s*[月\-./]\s*(\d{1,2})\s*日?`),
+	Request:     regexp.MustCompile(`(?s)(?:诉\s*讼|仲\s*裁)\s*请\s*求\s*[:：]\s*(.*?)\s*事\s*实\s*与\s*理\s*由`),
 	Facts:       regexp.MustCompile(`(?s)事\s*实\s*与\s*理\s*由\s*[:：]\s*(.*?)\s*此\s*致`),
+	Amount:      regexp.MustCompile(`(?:人民币|美元|港币|欧元|[¥￥$€])?\s*(?:\d[\d,，]*(?:\.\d+)?\s*[万亿]?\s*(?:[-~～至到]\s*\d[\d,，]*(?:\.\d+)?\s*[万亿]?\s*)?(?:美元|港元|欧元|元|圆)|[零壹贰叁肆伍陆柒捌玖拾佰仟万亿]+[元圆](?:[零壹贰叁肆伍陆柒捌玖][角分])*整?)`),
+
+	IDLoose: looseIDPattern,
+
+	CaseCause:      regexp.MustCompile(`案\s*由\s*[:：]\s*([^\n,，。；;]+)`),
+	AgentAuthority: regexp.MustCompile(`代\s*理\s*权\s*限\s*(?:[:：]|为)\s*([^\n,，。；;]+)`),
+	CreditCode:     regexp.MustCompile(`统\s*一\s*社\s*会\s*信\s*用\s*代\s*码\s*[:：]?\s*(?:为|是)?\s*([0-9A-Za-z]{18})(?:[^0-9A-Za-z]|$)`),
+
+	RequestLabel: regexp.MustCompile(`(?:诉\s*讼|仲\s*裁)\s*请\s*求\s*[:：]`),
+	FactsLabel:   regexp.MustCompile(`事\s*实\s*与\s*理\s*由\s*[:：]`),
+	// 此致, a signature line (具状人/起诉人/申请人) or a date on its own line
+	SectionEnd: regexp.MustCompile(joinSectionEnd(DefaultSectionEndAnchors)),
+
+	// the leading character keeps 被申请人 from matching as 申请人
+	Plaintiff:      regexp.MustCompile(`(?m)(?:^|[^被\s])\s*(?:原\s*告|申\s*请\s*人)\s*[一二三四五六七八九十\d]*\s*[:：]`),
+	PlaintiffAgent: regexp.MustCompile(`(?:委\s*托\s*(?:诉\s*讼\s*)?|诉\s*讼\s*|原\s*告\s*|申\s*请\s*人\s*)代\s*理\s*人\s*[一二三四五六七八九十\d]*\s*[:：]`),
+	LawFirm:        regexp.MustCompile(`[^\s,，、；;:：。]{2,30}?(?:律\s*师\s*事\s*务\s*所|法\s*律\s*服\s*务\s*所)`),
+	Phone:          regexp.MustCompile(`(?:联\s*系\s*)?(?:电\s*话|手\s*机)(?:\s*号\s*码)?\s*[:：]?\s*(1\d{10}|0\d{2,3}-?\d{7,8})`),
+
+	Annex: regexp.MustCompile(`(?m)^[\s#*]*(?:送\s*达\s*地\s*址\s*确\s*认\s*书|授\s*权\s*委\s*托\s*书|法\s*定\s*代\s*表\s*人\s*身\s*份\s*证\s*明(?:\s*书)?)[\s*]*$`),
+
+	DefStopChars: "。",
+	DefMaxRunes:  DefaultDefMaxRunes,
+
+	nameWhitespace: NameWhitespaceCompact,
 }
 
 // PatternRegistry maps field names to their respective patterns
@@ -29,9 +218,85 @@ var PatternRegistry = map[string]struct {
 	Label   string
 	Pattern *regexp.Regexp
 }{
-	"defendant":   {Label: "被告", Pattern: DefaultPatterns.DefStart},
-	"idNumber":    {Label: "身份证号码", Pattern: DefaultPatterns.ID},
-	"request":     {Label: "诉讼请求", Pattern: DefaultPatterns.Request},
-	"factsReason": {Label: "事实与理由", Pattern: DefaultPatterns.Facts},
-	"page":        {Label: "页码", Pattern: nil},
+	"plaintiff":        {Label: "原告", Pattern: DefaultPatterns.Plaintiff},
+	"plaintiffId":      {Label: "原告身份证号码", Pattern: nil},
+	"plaintiffPhone":   {Label: "原告联系电话", Pattern: DefaultPatterns.Phone},
+	"plaintiffAgent":   {Label: "原告代理人", Pattern: DefaultPatterns.PlaintiffAgent},
+	"plaintiffLawFirm": {Label: "原告律所", Pattern: DefaultPatterns.LawFirm},
+	"defendant":        {Label: "被告", Pattern: DefaultPatterns.DefStart},
+	"idNumber":         {Label: "身份证号码", Pattern: DefaultPatterns.ID},
+	"creditCode":       {Label: "统一社会信用代码", Pattern: DefaultPatterns.CreditCode},
+	"gender":           {Label: "性别", Pattern: DefaultPatterns.Gender},
+	"birthday":         {Label: "出生日期", Pattern: DefaultPatterns.Birthday},
+	"thirdParty":       {Label: "第三人", Pattern: DefaultPatterns.ThirdParty},
+	"thirdPartyId":     {Label: "第三人身份证号码", Pattern: nil},
+	"caseCause":        {Label: "案由", Pattern: DefaultPatterns.CaseCause},
+	"agentAuthority":   {Label: "代理权限", Pattern: DefaultPatterns.AgentAuthority},
+	"request":          {Label: "诉讼请求", Pattern: DefaultPatterns.Request},
+	"factsReason":      {Label: "事实与理由", Pattern: DefaultPatterns.Facts},
+	"amount":           {Label: "金额", Pattern: DefaultPatterns.Amount},
+	"amountValue":      {Label: "金额(数值)", Pattern: nil},
+	"amountCurrency":   {Label: "币种", Pattern: nil},
+	"page":             {Label: "页码", Pattern: nil},
+	"summary":          {Label: "摘要", Pattern: nil},
+	"sourceFile":       {Label: "源文件", Pattern: nil},
+	"sourceHash":       {Label: "源文件哈希", Pattern: nil},
+	"sourcePage":       {Label: "来源页码", Pattern: nil},
+	"extractedAt":      {Label: "提取时间", Pattern: nil},
+}
+
+// FieldPattern is one PatternRegistry entry as reported by GetActivePatterns
+type FieldPattern struct {
+	Field   string `json:"field"`
+	Label   string `json:"label"`
+	Pattern string `json:"pattern"` // empty for derived fields such as amountValue
+}
+
+// ActivePatterns is a snapshot of the patterns currently in effect, including
+// configured overrides such as extraction.defendant_stop_keywords
+type ActivePatterns struct {
+	Fields       []FieldPattern    `json:"fields"`   // sorted by field key
+	Patterns     map[string]string `json:"patterns"` // ExtractionPatterns field name -> regex
+	DefStopChars string            `json:"defStopChars"`
+	DefMaxRunes  int               `json:"defMaxRunes"`
+}
+
+// GetActivePatterns reports the built-in regexes of the local parser; see
+// Extractor.ActivePatterns for the ones an extractor uses after overrides
+func GetActivePatterns() ActivePatterns {
+	return DefaultPatterns.active()
+}
+
+// active reports the regexes in p, to help users understand why a field does or does not match
+func (p *ExtractionPatterns) active() ActivePatterns {
+	active := ActivePatterns{
+		Patterns:     make(map[string]string),
+		DefStopChars: p.DefStopChars,
+		DefMaxRunes:  p.DefMaxRunes,
+	}
+
+	v := reflect.ValueOf(*p)
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if re, ok := v.Field(i).Interface().(*regexp.Regexp); ok && re != nil {
+			active.Patterns[v.Type().Field(i).Name] = re.String()
+		}
+	}
+
+	keys := make([]string, 0, len(PatternRegistry))
+	for k := range PatternRegistry {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		entry := PatternRegistry[k]
+		fp := FieldPattern{Field: k, Label: entry.Label}
+		if entry.Pattern != nil {
+			fp.Pattern = entry.Pattern.String()
+		}
+		active.Fields = append(active.Fields, fp)
+	}
+	return active
 }
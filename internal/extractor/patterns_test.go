@@ -0,0 +1,42 @@
+package extractor
+
+import (
+	"log/slog"
+	"testing"
+
+	"legal-extractor/internal/config"
+)
+
+func TestGetActivePatterns(t *testing.T) {
+	active := GetActivePatterns()
+
+	if got, want := active.Patterns["ID"], DefaultPatterns.ID.String(); got != want {
+		t.Errorf("ID pattern = %q, want %q", got, want)
+	}
+	if len(active.Fields) != len(PatternRegistry) {
+		t.Fatalf("Expected %d fields, got %d", len(PatternRegistry), len(active.Fields))
+	}
+	for _, f := range active.Fields {
+		entry := PatternRegistry[f.Field]
+		if f.Label != entry.Label {
+			t.Errorf("%s: label = %q, want %q", f.Field, f.Label, entry.Label)
+		}
+		if entry.Pattern != nil && f.Pattern != entry.Pattern.String() {
+			t.Errorf("%s: pattern = %q, want %q", f.Field, f.Pattern, entry.Pattern.String())
+		}
+	}
+	if active.DefMaxRunes != 16 || active.DefStopChars != "。" {
+		t.Errorf("Unexpected defendant limits: %q / %d", active.DefStopChars, active.DefMaxRunes)
+	}
+
+	// 配置的截止关键词会反映在提取器的 DefEnd 中，内置规则不变
+	cfg := &config.Config{Extraction: config.ExtractionConfig{DefendantStopKeywords: []string{"法定代表人"}}}
+	e := NewExtractor(nil)
+	e.patterns = newPatterns(cfg, slog.Default())
+	if got, want := e.ActivePatterns().Patterns["DefEnd"], CompileDefEnd([]string{"法定代表人"}).String(); got != want {
+		t.Errorf("DefEnd = %q, want %q", got, want)
+	}
+	if got := GetActivePatterns().Patterns["DefEnd"]; got != DefaultPatterns.DefEnd.String() {
+		t.Errorf("DefaultPatterns.DefEnd changed to %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dslipak/pdf"
+)
+
+// PDFTextExtractor 读取 PDF 文本层的后端，可通过 Extractor.PDFText 替换为其他引擎
+type PDFTextExtractor interface {
+	// Name 后端名称，用于日志
+	Name() string
+	// Open 解析 PDF 文件，返回可按页读取文本的文档
+	Open(data []byte) (PDFTextDocument, error)
+}
+
+// PDFTextDocument 已解析的 PDF 文档。PageText 会被多个协程并发调用，实现须并发安全
+type PDFTextDocument interface {
+	// NumPage 返回总页数
+	NumPage() int
+	// PageText 返回第 page 页 (从 1 开始) 的纯文本，没有文本层时返回空串
+	PageText(page int) (string, error)
+}
+
+// GoPDFTextExtractor 基于 dslipak/pdf 的纯 Go 文本层后端，无需任何外部依赖 (默认后端)
+type GoPDFTextExtractor struct{}
+
+// Name 实现 PDFTextExtractor
+func (GoPDFTextExtractor) Name() string { return "go" }
+
+// Open 实现 PDFTextExtractor
+func (GoPDFTextExtractor) Open(data []byte) (PDFTextDocument, error) {
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	return goPDFDocument{r}, nil
+}
+
+type goPDFDocument struct {
+	r *pdf.Reader
+}
+
+func (d goPDFDocument) NumPage() int { return d.r.NumPage() }
+
+func (d goPDFDocument) PageText(page int) (string, error) {
+	if page < 1 || page > d.r.NumPage() {
+		return "", fmt.Errorf("页码 %d 超出范围 (总页数: %d)", page, d.r.NumPage())
+	}
+	return d.r.Page(page).GetPlainText(nil)
+}
+
+// pdfText 返回当前使用的文本层后端
+func (e *Extractor) pdfText() PDFTextExtractor {
+	if e.PDFText != nil {
+		return e.PDFText
+	}
+	return GoPDFTextExtractor{}
+}
@@ -0,0 +1,85 @@
+package extractor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGoPDFTextExtractor(t *testing.T) {
+	doc, err := GoPDFTextExtractor{}.Open(readFixture(t, "complaint.pdf"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if doc.NumPage() != 1 {
+		t.Fatalf("NumPage = %d, want 1", doc.NumPage())
+	}
+	text, err := doc.PageText(1)
+	if err != nil || !strings.Contains(text, "张三") {
+		t.Errorf("PageText(1) = %q, %v; want text containing 张三", text, err)
+	}
+	if _, err := doc.PageText(2); err == nil {
+		t.Error("PageText(2) succeeded, want out-of-range error")
+	}
+}
+
+// stubPDFText 以固定文本代替真实解析，用于验证自定义后端被采用
+type stubPDFText struct {
+	pages []string
+}
+
+func (stubPDFText) Name() string { return "stub" }
+
+func (s stubPDFText) Open([]byte) (PDFTextDocument, error) { return s, nil }
+
+func (s stubPDFText) NumPage() int { return len(s.pages) }
+
+func (s stubPDFText) PageText(page int) (string, error) { return s.pages[page-1], nil }
+
+func TestExtractData_PDF_CustomTextBackend(t *testing.T) {
+	e := NewExtractor(nil)
+	e.SetOCRProvider(nil)
+	e.PDFText = stubPDFText{pages: []string{
+		"民事起诉状\n原告：某银行股份有限公司\n被告：李四，男，1985年3月2日出生\n诉讼请求：\n请求判令被告偿还借款5000元。",
+		"",
+		"民事起诉状\n原告：某银行股份有限公司\n被告：王五，女，1990年7月8日出生\n诉讼请求：\n请求判令被告偿还借款8000元。",
+	}}
+	records, err := e.ExtractData(readFixture(t, "complaint.pdf"), "complaint.pdf", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	for i, want := range []struct{ defendant, page string }{{"李四", "1"}, {"王五", "3"}} {
+		if records[i]["defendant"] != want.defendant || records[i]["page"] != want.page {
+			t.Errorf("record %d = %q (page %s), want %q (page %s)", i, records[i]["defendant"], records[i]["page"], want.defendant, want.page)
+		}
+	}
+}
+
+func TestExtractData_PDF_TextWithoutBridge(t *testing.T) {
+	// 未配置识别引擎、也没有本地桥接工具：文本 PDF 仍应直接本地解析；封面页文字过少不影响判断
+	e := NewExtractor(nil)
+	e.SetOCRProvider(nil)
+	e.PDFText = stubPDFText{pages: []string{
+		"民事起诉状",
+		"原告：某银行股份有限公司\n被告：李四，男，1985年3月2日出生\n诉讼请求：\n请求判令被告偿还借款5000元。",
+	}}
+	records, err := e.ExtractData(readFixture(t, "complaint.pdf"), "cover.pdf", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData: %v", err)
+	}
+	if len(records) != 1 || records[0]["defendant"] != "李四" {
+		t.Fatalf("records = %v, want 李四 from page 2", records)
+	}
+
+	// 阈值调高后视为扫描件，需要桥接工具
+	scan := NewExtractor(nil)
+	scan.SetOCRProvider(nil)
+	scan.PDFText = e.PDFText
+	scan.MinTextChars = 1000
+	if _, err := scan.ExtractData(readFixture(t, "complaint.pdf"), "scan.pdf", allFields, nil); !errors.Is(err, ErrBridgeNotFound) {
+		t.Errorf("err = %v, want ErrBridgeNotFound", err)
+	}
+}
@@ -0,0 +1,135 @@
+package extractor
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// 提取路径，见 ExtractionPlan
+const (
+	// PlanNative 本地解析 (DOCX 正文或 PDF 原生文本层)，不调用识别
+	PlanNative = "native"
+	// PlanBridge 本地 Windows 系统识别桥接工具逐页识别
+	PlanBridge = "bridge"
+	// PlanOCR 云端识别引擎
+	PlanOCR = "ocr"
+)
+
+// ExtractionPlan ExtractData 处理某个文件时将采用的路径，由 Plan 在提取前给出
+type ExtractionPlan struct {
+	// Path 提取路径 (PlanNative / PlanBridge / PlanOCR)；邮件取各附件中开销最大的路径
+	Path string `json:"path"`
+	// Provider 将调用的识别引擎，不调用时为空
+	Provider   string `json:"provider,omitempty"`
+	TotalPages int    `json:"totalPages"`
+	// OCRPages 预计送入识别 (云端引擎或桥接工具) 的页数，混合解析时也计入
+	OCRPages int `json:"ocrPages"`
+	// Reasons 选择该路径的原因，按判断顺序排列
+	Reasons []string `json:"reasons"`
+	// Attachments 邮件中各附件的计划 (Name 为附件名)，跳过的附件不列出
+	Attachments []ExtractionPlan `json:"attachments,omitempty"`
+	Name        string           `json:"name,omitempty"`
+}
+
+// planCost 路径的开销排序，用于汇总邮件附件
+var planCost = map[string]int{PlanNative: 0, PlanBridge: 1, PlanOCR: 2}
+
+// Plan 在不提取的前提下给出 ExtractData 处理该文件时将采用的路径，判断逻辑与 ExtractData 一致：
+// 只读取本地文本层，不调用识别接口。可用于在消耗识别配额前向用户说明处理方式
+func (e *Extractor) Plan(fileData []byte, fileName string) (ExtractionPlan, error) {
+	switch ext := strings.ToLower(filepath.Ext(fileName)); ext {
+	case ".docx":
+		return ExtractionPlan{Path: PlanNative, Reasons: []string{"DOCX 始终本地解析"}}, nil
+	case ".pdf":
+		return e.planPdf(fileData)
+	case ".eml":
+		return e.planEmail(fileData)
+	default:
+		return ExtractionPlan{}, fmt.Errorf("不支持的文件格式: %s", ext)
+	}
+}
+
+// planPdf 对应 extractPdf 的路径选择
+func (e *Extractor) planPdf(fileData []byte) (ExtractionPlan, error) {
+	if _, err := e.pdfText().Open(fileData); err != nil && isPdfEncryptionError(err) {
+		return ExtractionPlan{}, ErrPDFEncrypted
+	}
+	doc, totalPages := e.openPdf(fileData)
+	plan := ExtractionPlan{TotalPages: totalPages}
+
+	if e.probeTextLayer(doc, totalPages) {
+		plan.Path = PlanNative
+		plan.Reasons = append(plan.Reasons, fmt.Sprintf("前 %d 页中有页面带文本层", min(totalPages, textProbePages)))
+		if e.Hybrid && e.ocr != nil {
+			plan.Provider = e.ocr.Name()
+			plan.OCRPages = totalPages
+			plan.Reasons = append(plan.Reasons, "已开启混合解析，另调用识别引擎 "+plan.Provider+" 按字段优先级合并")
+		}
+		return plan, nil
+	}
+
+	if doc == nil {
+		plan.Reasons = append(plan.Reasons, "无法读取文本层，按扫描件处理")
+	} else {
+		plan.Reasons = append(plan.Reasons, fmt.Sprintf("前 %d 页均无文本层 (少于 %d 个字符)，按扫描件处理",
+			min(totalPages, textProbePages), e.minTextChars()))
+	}
+	plan.OCRPages = totalPages
+	if e.ocr != nil {
+		plan.Path = PlanOCR
+		plan.Provider = e.ocr.Name()
+		plan.Reasons = append(plan.Reasons, "使用已配置的识别引擎 "+plan.Provider)
+		if e.FallbackOnEmpty {
+			plan.Reasons = append(plan.Reasons, "识别结果为空时依次改用备用引擎与本地系统识别")
+		}
+		return plan, nil
+	}
+
+	plan.Path = PlanBridge
+	plan.Reasons = append(plan.Reasons, "未配置云端识别引擎，使用本地系统识别")
+	if _, err := findWinOcrBridge(); err != nil {
+		plan.Reasons = append(plan.Reasons, "找不到桥接工具 (WinOcrBridge.exe)，提取将失败")
+	}
+	return plan, nil
+}
+
+// planEmail 对应 extractEmail：逐个附件给出计划，整体路径取开销最大者
+func (e *Extractor) planEmail(fileData []byte) (ExtractionPlan, error) {
+	attachments, err := emailAttachments(fileData)
+	if err != nil {
+		return ExtractionPlan{}, err
+	}
+
+	plan := ExtractionPlan{Path: PlanNative}
+	for _, a := range attachments {
+		var sub ExtractionPlan
+		switch ext := strings.ToLower(filepath.Ext(a.Name)); {
+		case ext == ".pdf" || ext == ".docx":
+			if sub, err = e.Plan(a.Data, a.Name); err != nil {
+				return ExtractionPlan{}, fmt.Errorf("附件 %s: %w", a.Name, err)
+			}
+		case slices.Contains(ImageExtensions(), ext) && e.ocr != nil:
+			sub = ExtractionPlan{Path: PlanOCR, Provider: e.ocr.Name(), TotalPages: 1, OCRPages: 1,
+				Reasons: []string{"图片附件使用识别引擎 " + e.ocr.Name()}}
+		default:
+			continue
+		}
+		sub.Name = a.Name
+		plan.Attachments = append(plan.Attachments, sub)
+		plan.TotalPages += sub.TotalPages
+		plan.OCRPages += sub.OCRPages
+		if planCost[sub.Path] > planCost[plan.Path] {
+			plan.Path = sub.Path
+		}
+		if sub.Provider != "" {
+			plan.Provider = sub.Provider
+		}
+	}
+	if len(plan.Attachments) == 0 {
+		return ExtractionPlan{}, fmt.Errorf("邮件中没有可提取的 PDF 或 DOCX 附件")
+	}
+	plan.Reasons = []string{fmt.Sprintf("邮件包含 %d 个可提取的附件", len(plan.Attachments))}
+	return plan, nil
+}
@@ -0,0 +1,49 @@
+package extractor
+
+import "testing"
+
+func TestPlan(t *testing.T) {
+	withOCR := NewExtractor(nil)
+	withOCR.SetOCRProvider(NewMockOCRProvider())
+
+	tests := []struct {
+		name         string
+		e            *Extractor
+		file         string
+		wantPath     string
+		wantProvider string
+		wantOCRPages int
+	}{
+		{"text pdf", withOCR, "complaint.pdf", PlanNative, "", 0},
+		{"scanned pdf with provider", withOCR, "scanned.pdf", PlanOCR, "mock", 2},
+		{"scanned pdf without provider", NewExtractor(nil), "scanned.pdf", PlanBridge, "", 2},
+		{"docx", withOCR, "complaint.docx", PlanNative, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := tt.e.Plan(readFixture(t, tt.file), tt.file)
+			if err != nil {
+				t.Fatalf("Plan error: %v", err)
+			}
+			if plan.Path != tt.wantPath || plan.Provider != tt.wantProvider || plan.OCRPages != tt.wantOCRPages {
+				t.Errorf("Plan = %s/%q/%d pages, want %s/%q/%d",
+					plan.Path, plan.Provider, plan.OCRPages, tt.wantPath, tt.wantProvider, tt.wantOCRPages)
+			}
+			if len(plan.Reasons) == 0 {
+				t.Error("Expected at least one reason")
+			}
+		})
+	}
+}
+
+func TestPlan_DoesNotCallProvider(t *testing.T) {
+	mock := NewMockOCRProvider()
+	e := NewExtractor(nil)
+	e.SetOCRProvider(mock)
+	if _, err := e.Plan(readFixture(t, "scanned.pdf"), "scanned.pdf"); err != nil {
+		t.Fatalf("Plan error: %v", err)
+	}
+	if mock.Calls() != 0 {
+		t.Errorf("Expected no OCR calls, got %d", mock.Calls())
+	}
+}
@@ -0,0 +1,157 @@
+package extractor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultFieldPrefixes 各字段取值开头可能混入的本字段标注 (识别结果常把标注与取值连在一起，如 "被告张三"、
+// "诉讼请求：1. …")。提取完成后去除取值开头重复出现的这些标注
+var DefaultFieldPrefixes = map[string][]string{
+	"plaintiff":        {"原告", "申请人", "起诉人"},
+	"plaintiffId":      {"身份证号码", "公民身份号码", "身份证号", "身份证"},
+	"plaintiffPhone":   {"联系电话", "电话", "手机"},
+	"plaintiffAgent":   {"委托诉讼代理人", "委托代理人", "代理人"},
+	"plaintiffLawFirm": {"律师事务所"},
+	"defendant":        {"被告", "被申请人"},
+	"idNumber":         {"身份证号码", "公民身份号码", "身份证号", "身份证"},
+	"creditCode":       {"统一社会信用代码", "信用代码"},
+	"thirdParty":       {"第三人"},
+	"thirdPartyId":     {"身份证号码", "公民身份号码", "身份证号", "身份证"},
+	"caseCause":        {"案由"},
+	"agentAuthority":   {"代理权限"},
+	"request":          {"诉讼请求", "仲裁请求", "请求事项"},
+	"factsReason":      {"事实与理由", "事实和理由", "事实经过"},
+}
+
+// listFields 可能以 RepeatSeparator 连接多个取值的字段，逐个取值去除标注
+var listFields = map[string]bool{
+	"plaintiff": true, "defendant": true, "thirdParty": true,
+	"plaintiffId": true, "idNumber": true, "thirdPartyId": true,
+}
+
+// fieldPrefixes 各字段的标注匹配规则 (字段键 -> 规则)，编译后不再修改，可在提取器之间共享
+type fieldPrefixes map[string]*regexp.Regexp
+
+// defaultFieldPrefixes 按 DefaultFieldPrefixes 编译的规则，未配置 extraction.field_prefixes 时使用
+var defaultFieldPrefixes = compileFieldPrefixes(DefaultFieldPrefixes)
+
+// compileFieldPrefixes 编译各字段的标注规则；律所名称中的 "律师事务所" 是名称的一部分，只在其后带冒号时去除
+func compileFieldPrefixes(prefixes map[string][]string) fieldPrefixes {
+	compiled := make(fieldPrefixes, len(prefixes))
+	for field, labels := range prefixes {
+		if re := compileFieldPrefix(labels, field == "plaintiffLawFirm"); re != nil {
+			compiled[field] = re
+		}
+	}
+	return compiled
+}
+
+// compileFieldPrefix 匹配取值开头连续重复的标注：标注各字之间允许空白，其后可带序号与冒号 (如 "被告一：")；
+// colonOnly 为 true 时标注之后必须有冒号
+func compileFieldPrefix(labels []string, colonOnly bool) *regexp.Regexp {
+	sorted := append([]string(nil), labels...)
+	// 较长的标注优先，避免 "身份证号码" 只去掉 "身份证"
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	var alts []string
+	for _, label := range sorted {
+		var chars []string
+		for _, r := range strings.TrimSpace(label) {
+			chars = append(chars, regexp.QuoteMeta(string(r)))
+		}
+		if len(chars) > 0 {
+			alts = append(alts, strings.Join(chars, `\s*`))
+		}
+	}
+	if len(alts) == 0 {
+		return nil
+	}
+	suffix := `(?:\s*[一二三四五六七八九十\d]+\s*[:：]|\s*[:：])?`
+	if colonOnly {
+		suffix = `\s*[:：]`
+	}
+	return regexp.MustCompile(`^(?:[\s#*]*(?:` + strings.Join(alts, "|") + `)` + suffix + `[\s,，]*)+`)
+}
+
+// SetFieldPrefixes 设置各字段需要去除的标注 (字段键 -> 标注列表)，覆盖 DefaultFieldPrefixes 中对应字段的列表，
+// 空列表表示该字段不做处理。无法识别的字段返回错误，其余条目仍然生效；传入 nil 恢复默认
+func (e *Extractor) SetFieldPrefixes(prefixes map[string][]string) error {
+	merged := make(map[string][]string, len(DefaultFieldPrefixes))
+	for field, labels := range DefaultFieldPrefixes {
+		merged[field] = labels
+	}
+	var invalid []string
+	for field, labels := range prefixes {
+		key := canonicalFieldKey(field)
+		if key == "" {
+			invalid = append(invalid, field)
+			continue
+		}
+		merged[key] = labels
+	}
+
+	e.prefixes = compileFieldPrefixes(merged)
+
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("无效的字段标注配置 (未知字段): %s", strings.Join(invalid, "、"))
+	}
+	return nil
+}
+
+// stripFieldPrefix 去除 field 取值开头重复的本字段标注；没有配置标注的字段或不以标注开头的取值原样返回
+func (fp fieldPrefixes) stripFieldPrefix(field, value string) string {
+	re := fp[field]
+	if re == nil || !re.MatchString(value) {
+		return value
+	}
+	if !listFields[field] {
+		return strings.TrimSpace(re.ReplaceAllString(value, ""))
+	}
+	parts := strings.Split(value, RepeatSeparator)
+	kept := parts[:0]
+	for _, p := range parts {
+		if re.MatchString(p) {
+			p = re.ReplaceAllString(p, "")
+		}
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, RepeatSeparator)
+}
+
+// stripFieldPrefixes 对每条记录的各字段去除混入的标注。本地解析与识别结果都经过这里 (见 finishRecords)；
+// 只有取值发生变化的记录会被复制，不修改缓存中的原始记录
+func (fp fieldPrefixes) stripFieldPrefixes(records []Record) []Record {
+	out, shared := records, true
+	for i, r := range records {
+		var copied Record
+		for field := range fp {
+			v, ok := r[field]
+			if !ok {
+				continue
+			}
+			stripped := fp.stripFieldPrefix(field, v)
+			if stripped == v {
+				continue
+			}
+			if copied == nil {
+				copied = make(Record, len(r))
+				for k, val := range r {
+					copied[k] = val
+				}
+			}
+			copied[field] = stripped
+		}
+		if copied != nil {
+			if shared {
+				out, shared = append([]Record(nil), records...), false
+			}
+			out[i] = copied
+		}
+	}
+	return out
+}
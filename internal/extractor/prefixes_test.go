@@ -0,0 +1,78 @@
+package extractor
+
+import "testing"
+
+func TestStripFieldPrefix(t *testing.T) {
+	tests := []struct{ field, in, want string }{
+		{"plaintiff", "原告：某某银行股份有限公司", "某某银行股份有限公司"},
+		{"plaintiffId", "身份证号码：110101199001011234", "110101199001011234"},
+		{"plaintiffPhone", "联系电话 13800138000", "13800138000"},
+		{"plaintiffAgent", "委托诉讼代理人：钱二", "钱二"},
+		{"plaintiffLawFirm", "律师事务所：北京某某律师事务所", "北京某某律师事务所"},
+		{"plaintiffLawFirm", "北京某某律师事务所", "北京某某律师事务所"},
+		{"defendant", "被告张三", "张三"},
+		{"defendant", "被 告 一：张三、被告二：李四", "张三、李四"},
+		{"defendant", "被申请人：被告张三", "张三"}, // 连续重复的标注一并去除
+		{"idNumber", "公民身份号码110101199001011234", "110101199001011234"},
+		{"creditCode", "统一社会信用代码：91110000MA01ABCD2X", "91110000MA01ABCD2X"},
+		{"thirdParty", "第三人：王五", "王五"},
+		{"thirdPartyId", "身份证号：310101198001011234", "310101198001011234"},
+		{"caseCause", "案由：民间借贷纠纷", "民间借贷纠纷"},
+		{"agentAuthority", "代理权限：特别授权", "特别授权"},
+		{"request", "诉讼请求：1. 判令被告偿还借款。", "1. 判令被告偿还借款。"},
+		{"request", "## 仲裁请求\n1. 裁决被申请人支付货款。", "1. 裁决被申请人支付货款。"},
+		{"factsReason", "事实与理由：被告借款未还。", "被告借款未还。"},
+		{"factsReason", "被告借款未还。\n", "被告借款未还。\n"}, // 不以标注开头时原样保留
+	}
+	for _, tt := range tests {
+		if got := defaultFieldPrefixes.stripFieldPrefix(tt.field, tt.in); got != tt.want {
+			t.Errorf("stripFieldPrefix(%s, %q) = %q, want %q", tt.field, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSetFieldPrefixes(t *testing.T) {
+	e := NewExtractor(nil)
+	if err := e.SetFieldPrefixes(map[string][]string{"defendant": {"被起诉人"}, "request": {}, "nosuch": {"x"}}); err == nil {
+		t.Error("Expected an error for the unknown field")
+	}
+	if got := e.prefixes.stripFieldPrefix("defendant", "被起诉人：张三"); got != "张三" {
+		t.Errorf("Configured label not stripped: %q", got)
+	}
+	if got := e.prefixes.stripFieldPrefix("defendant", "被告张三"); got != "被告张三" {
+		t.Errorf("Configured list should replace the defaults, got %q", got)
+	}
+	if got := e.prefixes.stripFieldPrefix("request", "诉讼请求：偿还借款"); got != "诉讼请求：偿还借款" {
+		t.Errorf("An empty list should disable the field, got %q", got)
+	}
+	// 其他提取器仍使用默认标注
+	if got := NewExtractor(nil).prefixes.stripFieldPrefix("defendant", "被告张三"); got != "张三" {
+		t.Errorf("Another extractor should keep the default labels, got %q", got)
+	}
+}
+
+// 识别结果与本地解析结果都经过标注清理
+func TestExtract_StripsLeakedLabels(t *testing.T) {
+	mock := NewMockOCRProvider()
+	mock.Records = []Record{{"defendant": "被告张三", "request": "诉讼请求：偿还借款10000元", "page": "1"}}
+	e := NewExtractor(nil)
+	e.SetOCRProvider(mock)
+	records, err := e.ExtractData(readFixture(t, "scanned.pdf"), "scanned.pdf", FieldKeys(), nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if records[0]["defendant"] != "张三" || records[0]["request"] != "偿还借款10000元" {
+		t.Errorf("OCR path: unexpected record %v", records[0])
+	}
+	if mock.Records[0]["defendant"] != "被告张三" {
+		t.Error("The provider's records should not be modified in place")
+	}
+
+	records, err = NewExtractor(nil).ExtractText("民事起诉状\n被告：被告张三，男，汉族\n诉讼请求：诉讼请求：偿还借款。\n此致\n", nil)
+	if err != nil {
+		t.Fatalf("ExtractText returned error: %v", err)
+	}
+	if records[0]["defendant"] != "张三" || records[0]["request"] != "偿还借款。" {
+		t.Errorf("Regex path: unexpected record %v", records[0])
+	}
+}
@@ -0,0 +1,187 @@
+package extractor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"legal-extractor/internal/config"
+)
+
+// 混合解析时字段值的两个来源
+const (
+	// FieldSourceText 本地正则解析 PDF 原生文本层的结果，字符准确，适合身份证号、电话、金额等
+	FieldSourceText = "text"
+	// FieldSourceOCR 云端识别引擎 (版面分析) 的结果，段落结构完整，适合诉讼请求、事实与理由等长文本
+	FieldSourceOCR = "ocr"
+)
+
+// FieldPriority 混合解析时各字段优先采用的来源 (FieldSourceText 或 FieldSourceOCR)，
+// 未列出的字段优先采用 FieldSourceText；优先来源为空时取另一来源的值
+type FieldPriority map[string]string
+
+// DefaultFieldPriority 返回内置的优先级表：长文本段落取识别结果，其余字段取原生文本
+func DefaultFieldPriority() FieldPriority {
+	return FieldPriority{
+		"request":     FieldSourceOCR,
+		"factsReason": FieldSourceOCR,
+	}
+}
+
+// ParseFieldPriority 在内置优先级表的基础上应用配置项 extraction.field_priority。
+// 配置文件中的键会被转为小写，此处按字段键不区分大小写匹配；无法识别的字段或来源返回错误，其余条目仍然生效
+func ParseFieldPriority(overrides map[string]string) (FieldPriority, error) {
+	p := DefaultFieldPriority()
+	var invalid []string
+	for key, source := range overrides {
+		field := canonicalFieldKey(key)
+		source = strings.ToLower(strings.TrimSpace(source))
+		if field == "" || (source != FieldSourceText && source != FieldSourceOCR) {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", key, source))
+			continue
+		}
+		p[field] = source
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return p, fmt.Errorf("无效的字段优先级 (来源应为 %s 或 %s): %s",
+			FieldSourceText, FieldSourceOCR, strings.Join(invalid, "，"))
+	}
+	return p, nil
+}
+
+// FieldPriorityFromConfig 返回 conf.yaml 中配置的字段优先级表
+func FieldPriorityFromConfig() (FieldPriority, error) {
+	return ParseFieldPriority(config.Get().Extraction.FieldPriority)
+}
+
+// canonicalFieldKey 将不区分大小写的字段名还原为 PatternRegistry 中的字段键，未知字段返回空字符串
+func canonicalFieldKey(key string) string {
+	key = strings.TrimSpace(key)
+	for field := range PatternRegistry {
+		if strings.EqualFold(field, key) {
+			return field
+		}
+	}
+	if strings.EqualFold(key, "amountValue") {
+		return "amountValue"
+	}
+	return ""
+}
+
+// source 返回字段优先采用的来源；amountValue 与 amount 同源，避免金额文本与数值来自不同来源
+func (p FieldPriority) source(field string) string {
+	if field == "amountValue" {
+		field = "amount"
+	}
+	if s := p[field]; s != "" {
+		return s
+	}
+	return FieldSourceText
+}
+
+// ResolveSources 按字段优先级合并同一文档的两份解析结果：text 为本地正则解析原生文本层的记录，
+// ocr 为识别引擎的记录。两者先按身份证号码、再按被告名称配对，都缺少时按顺序与剩余记录配对
+// (见 pairSources)；未能配对的记录不合并，原样保留 (text 一方按原顺序，ocr 一方附在末尾)。
+// 每个字段取优先来源的值，优先来源为空时取另一来源；元数据 (如页码) 取自 text 一方
+func ResolveSources(text, ocr []Record, priority FieldPriority) []Record {
+	pairs := pairSources(text, ocr)
+	merged := make([]Record, 0, len(text)+len(ocr)-len(pairs))
+	used := make(map[int]bool, len(pairs))
+	for i, t := range text {
+		j, ok := pairs[i]
+		if !ok {
+			merged = append(merged, t)
+			continue
+		}
+		used[j] = true
+		merged = append(merged, mergeSources(t, ocr[j], priority))
+	}
+	for j, o := range ocr {
+		if !used[j] {
+			merged = append(merged, o)
+		}
+	}
+	return merged
+}
+
+// mergeSources 按字段优先级合并配对的两条记录
+func mergeSources(t, o Record, priority FieldPriority) Record {
+	r := make(Record, len(t)+len(o))
+	for _, rec := range []Record{o, t} {
+		for k := range rec {
+			first, second := t[k], o[k]
+			if !strings.HasPrefix(k, "_") && k != "page" && priority.source(k) == FieldSourceOCR {
+				first, second = second, first
+			}
+			if _, ok := r[k]; ok {
+				continue
+			}
+			if first != "" {
+				r[k] = first
+			} else {
+				r[k] = second
+			}
+		}
+	}
+	return r
+}
+
+// sourceIDReplacer 还原识别结果中身份证号码常见的字形混淆 (字母 O、I 误作数字 0、1)
+var sourceIDReplacer = strings.NewReplacer("O", "0", "I", "1")
+
+// sourceID 返回记录用于配对的身份证号码
+func sourceID(r Record) string {
+	return sourceIDReplacer.Replace(normalizeID(r["idNumber"]))
+}
+
+// sourceDefendant 返回记录用于配对的被告名称
+func sourceDefendant(r Record) string {
+	return strings.TrimSpace(r["defendant"])
+}
+
+// pairSources 返回 text 记录序号到 ocr 记录序号的映射：先按身份证号码配对，再按被告名称配对，
+// 其余记录按顺序与剩余记录配对；双方号码或被告名称都存在却不一致时说明不是同一当事人，不配对
+func pairSources(text, ocr []Record) map[int]int {
+	pairs := make(map[int]int)
+	used := make(map[int]bool)
+	match := func(key func(Record) string) {
+		index := make(map[string][]int) // 键 -> 尚未配对的 ocr 记录序号
+		for j, r := range ocr {
+			if k := key(r); k != "" && !used[j] {
+				index[k] = append(index[k], j)
+			}
+		}
+		for i, r := range text {
+			if _, ok := pairs[i]; ok {
+				continue
+			}
+			if k := key(r); k != "" && len(index[k]) > 0 {
+				pairs[i], used[index[k][0]] = index[k][0], true
+				index[k] = index[k][1:]
+			}
+		}
+	}
+	match(sourceID)
+	match(sourceDefendant)
+
+	next := 0
+	for i, t := range text {
+		if _, ok := pairs[i]; ok {
+			continue
+		}
+		for next < len(ocr) && used[next] {
+			next++
+		}
+		if next >= len(ocr) {
+			break
+		}
+		o := ocr[next]
+		if (sourceID(t) != "" && sourceID(o) != "") || (sourceDefendant(t) != "" && sourceDefendant(o) != "") {
+			continue
+		}
+		pairs[i] = next
+		used[next] = true
+	}
+	return pairs
+}
@@ -0,0 +1,148 @@
+package extractor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveSources(t *testing.T) {
+	text := []Record{{
+		"defendant": "张三", "idNumber": "110101199001011234", "plaintiffPhone": "13800138000",
+		"amount": "10000元", "amountValue": "10000",
+		"request": "一、请求判令被告偿还借款10000元；二、诉讼费由被告承担。", "factsReason": "被告借款未还",
+		"page": "1", "_warnings": "text",
+	}}
+	ocr := []Record{{
+		"defendant": "张三丰", "idNumber": "11O10119900101I234", "plaintiffPhone": "",
+		"amount": "1000O元", "amountValue": "1000",
+		"request": "一、请求判令被告偿还借款10000元；\n二、诉讼费由被告承担。", "factsReason": "2023年1月1日，被告向原告借款10000元，至今未还。",
+		"thirdParty": "王五", "_warnings": "ocr",
+	}}
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		want      Record
+	}{
+		{
+			name: "default",
+			want: Record{
+				"defendant": "张三", "idNumber": "110101199001011234", "plaintiffPhone": "13800138000",
+				"amount": "10000元", "amountValue": "10000",
+				"request": ocr[0]["request"], "factsReason": ocr[0]["factsReason"], "thirdParty": "王五",
+				"page": "1", "_warnings": "text",
+			},
+		},
+		{
+			// 配置文件中的键为小写；amountValue 随 amount 取自同一来源；优先来源为空时取另一来源
+			name:      "configured",
+			overrides: map[string]string{"defendant": "ocr", "idnumber": "OCR", "amount": "ocr", "plaintiffphone": "ocr", "factsreason": "text"},
+			want: Record{
+				"defendant": "张三丰", "idNumber": "11O10119900101I234", "plaintiffPhone": "13800138000",
+				"amount": "1000O元", "amountValue": "1000",
+				"request": ocr[0]["request"], "factsReason": "被告借款未还", "thirdParty": "王五",
+				"page": "1", "_warnings": "text",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priority, err := ParseFieldPriority(tt.overrides)
+			if err != nil {
+				t.Fatalf("ParseFieldPriority returned error: %v", err)
+			}
+			got := ResolveSources(text, ocr, priority)
+			if len(got) != 1 {
+				t.Fatalf("Expected 1 record, got %d", len(got))
+			}
+			if len(got[0]) != len(tt.want) {
+				t.Errorf("Expected %d keys, got %v", len(tt.want), got[0])
+			}
+			for k, v := range tt.want {
+				if got[0][k] != v {
+					t.Errorf("%s: expected %q, got %q", k, v, got[0][k])
+				}
+			}
+		})
+	}
+}
+
+func TestResolveSources_UnpairedRecords(t *testing.T) {
+	got := ResolveSources([]Record{{"defendant": "张三"}}, []Record{{"defendant": "张三"}, {"defendant": "李四"}}, DefaultFieldPriority())
+	if len(got) != 2 || got[1]["defendant"] != "李四" {
+		t.Errorf("Expected the extra OCR record to be kept, got %v", got)
+	}
+}
+
+// 两份结果的记录数不同、顺序不同时按号码与被告名称配对，不按序号错配
+func TestResolveSources_DifferentCounts(t *testing.T) {
+	text := []Record{
+		{"defendant": "张三", "idNumber": "110101199001011234", "page": "1"},
+		{"defendant": "李四", "page": "1"},
+		{"defendant": "王五", "idNumber": "110101198505051234", "page": "2"},
+	}
+	ocr := []Record{
+		{"defendant": "王五", "idNumber": "110101198505051234", "request": "王五的请求"},
+		{"defendant": "李四", "request": "李四的请求"},
+		{"defendant": "赵六", "idNumber": "110101197001011234", "request": "赵六的请求"},
+	}
+	got := ResolveSources(text, ocr, DefaultFieldPriority())
+	want := []struct{ defendant, request, page string }{
+		{"张三", "", "1"},
+		{"李四", "李四的请求", "1"},
+		{"王五", "王五的请求", "2"},
+		{"赵六", "赵六的请求", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d records, got %d: %v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i]["defendant"] != w.defendant || got[i]["request"] != w.request || got[i]["page"] != w.page {
+			t.Errorf("record %d: got %v, want %+v", i, got[i], w)
+		}
+	}
+}
+
+func TestParseFieldPriority_Invalid(t *testing.T) {
+	p, err := ParseFieldPriority(map[string]string{"idnumber": "ocr", "defendnat": "ocr", "request": "best"})
+	if err == nil {
+		t.Fatal("Expected an error for the unknown field and source")
+	}
+	if p.source("idNumber") != FieldSourceOCR || p.source("request") != FieldSourceOCR {
+		t.Errorf("Valid entries and defaults should still apply, got %v", p)
+	}
+}
+
+func TestExtractData_Hybrid(t *testing.T) {
+	mock := &MockOCRProvider{Records: []Record{{
+		"defendant": "张二", "idNumber": "11010119900101I234", // 号码中的 1 误识别为 I，仍可配对
+		"request": "识别的诉讼请求", "factsReason": "识别的事实与理由",
+	}}}
+	e := NewExtractor(nil)
+	e.SetOCRProvider(mock)
+	e.Hybrid = true
+
+	records, err := e.ExtractData(readFixture(t, "complaint.pdf"), "complaint.pdf", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if len(records) != 1 || mock.Calls() != 1 {
+		t.Fatalf("Expected 1 record and 1 OCR call, got %d records, %d calls", len(records), mock.Calls())
+	}
+	want := Record{"defendant": "张三", "idNumber": "110101199001011234", "request": "识别的诉讼请求", "factsReason": "识别的事实与理由", "page": "1"}
+	for k, v := range want {
+		if records[0][k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, records[0][k])
+		}
+	}
+
+	// 识别失败时保留文本层结果
+	mock.Err = errors.New("quota exceeded")
+	e = NewExtractor(nil)
+	e.SetOCRProvider(mock)
+	e.Hybrid = true
+	records, err = e.ExtractData(readFixture(t, "complaint.pdf"), "complaint.pdf", allFields, nil)
+	if err != nil || len(records) != 1 || records[0]["factsReason"] != "2023年1月1日，被告向原告借款10000元，至今未还。" {
+		t.Errorf("Expected the text-layer record on OCR failure, got %v (%v)", records, err)
+	}
+}
@@ -0,0 +1,56 @@
+package extractor
+
+import (
+	"time"
+)
+
+// 来源信息字段，由 IncludeProvenance 写入，导出时作为附加列 (位于其他字段之后)
+const (
+	// SourceFileKey 源文件名
+	SourceFileKey = "sourceFile"
+	// SourceHashKey 源文件内容的 SHA-256 (十六进制)，可据此核对导出结果对应的原始文件未被替换
+	SourceHashKey = "sourceHash"
+	// SourcePageKey 记录所在的页码，无页码的文档 (如 DOCX) 不写入
+	SourcePageKey = "sourcePage"
+	// ExtractedAtKey 提取时间 (RFC 3339)
+	ExtractedAtKey = "extractedAt"
+)
+
+// provenance 一次提取的来源信息
+type provenance struct {
+	file, hash, at string
+}
+
+// newProvenance 记下本次提取的文件名、内容哈希与提取时间；未开启 IncludeProvenance 时返回 nil
+func (e *Extractor) newProvenance(fileData []byte, fileName string) *provenance {
+	if !e.IncludeProvenance {
+		return nil
+	}
+	return &provenance{
+		file: fileName,
+		hash: e.calculateHash(fileData),
+		at:   time.Now().Format(time.RFC3339),
+	}
+}
+
+// stamp 返回写入来源信息后的记录副本 (不修改缓存中的原始记录)；p 为 nil 时原样返回
+func (p *provenance) stamp(records []Record) []Record {
+	if p == nil {
+		return records
+	}
+	stamped := make([]Record, len(records))
+	for i, r := range records {
+		s := make(Record, len(r)+4)
+		for k, v := range r {
+			s[k] = v
+		}
+		s[SourceFileKey] = p.file
+		s[SourceHashKey] = p.hash
+		if page := r["page"]; page != "" {
+			s[SourcePageKey] = page
+		}
+		s[ExtractedAtKey] = p.at
+		stamped[i] = s
+	}
+	return stamped
+}
@@ -0,0 +1,68 @@
+package extractor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIncludeProvenance(t *testing.T) {
+	data := readFixture(t, "complaint.pdf")
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	extract := func(include bool) ([]Record, []string) {
+		t.Helper()
+		e := NewExtractor(nil)
+		e.IncludeProvenance = include
+		records, err := e.ExtractData(data, "complaint.pdf", FieldKeys(), nil)
+		if err != nil {
+			t.Fatalf("ExtractData returned error: %v", err)
+		}
+		if len(records) == 0 {
+			t.Fatal("Expected records")
+		}
+		var buf bytes.Buffer
+		if err := WriteCSV(&buf, records, ExportOptions{}); err != nil {
+			t.Fatalf("WriteCSV returned error: %v", err)
+		}
+		header, err := csv.NewReader(&buf).Read()
+		if err != nil {
+			t.Fatalf("read CSV header: %v", err)
+		}
+		return records, header
+	}
+
+	records, header := extract(true)
+	for _, r := range records {
+		if r[SourceFileKey] != "complaint.pdf" || r[SourceHashKey] != hash {
+			t.Errorf("Unexpected source %q / %q", r[SourceFileKey], r[SourceHashKey])
+		}
+		if r[SourcePageKey] == "" || r[SourcePageKey] != r["page"] {
+			t.Errorf("sourcePage %q does not match page %q", r[SourcePageKey], r["page"])
+		}
+		if _, err := time.Parse(time.RFC3339, r[ExtractedAtKey]); err != nil {
+			t.Errorf("extractedAt %q is not RFC 3339: %v", r[ExtractedAtKey], err)
+		}
+	}
+	for _, label := range []string{"源文件", "源文件哈希", "来源页码", "提取时间"} {
+		if !slices.Contains(header, label) {
+			t.Errorf("CSV header %v lacks %s", header, label)
+		}
+	}
+
+	// 未开启时既不写入记录 (缓存命中也不受上次开启的影响)，也不导出
+	records, header = extract(false)
+	for _, key := range []string{SourceFileKey, SourceHashKey, SourcePageKey, ExtractedAtKey} {
+		if _, ok := records[0][key]; ok {
+			t.Errorf("Record should not contain %s when provenance is disabled", key)
+		}
+	}
+	if slices.Contains(header, "源文件") {
+		t.Errorf("CSV header %v should not contain provenance columns", header)
+	}
+}
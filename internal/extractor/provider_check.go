@@ -0,0 +1,129 @@
+package extractor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CredentialChecker 由支持凭据校验的识别引擎实现，以不触发识别、不计费的认证请求验证密钥是否可用
+type CredentialChecker interface {
+	CheckCredentials() error
+}
+
+// ProviderCheck 单个识别引擎的凭据校验结果，不包含任何密钥信息
+type ProviderCheck struct {
+	Provider  string `json:"provider"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	Hint      string `json:"hint,omitempty"` // 面向用户的处理建议
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+// ProviderHint 将识别引擎的错误转换为面向用户的处理建议，无法归类时返回空字符串
+func ProviderHint(err error) string {
+	var httpErr *baiduHTTPError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrQuotaExceeded):
+		return "今日调用量已用尽，请明日再试或在百度 AI Studio 提升配额"
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden):
+		return "Token 无效或已过期，请在百度 AI Studio 重新获取并更新 baidu.token"
+	case errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests:
+		return "请求过于频繁，请稍后重试或调低 baidu.submit_qps"
+	case errors.As(err, &netErr):
+		return "无法连接识别服务，请检查网络、baidu.api_url 与 baidu.proxy 设置"
+	default:
+		return ""
+	}
+}
+
+// TestProvider 对已配置的识别引擎发起一次不计费的认证请求，用于在处理文档前验证凭据；不计入调用量统计
+func (e *Extractor) TestProvider(name string) ProviderCheck {
+	result := ProviderCheck{Provider: name}
+
+	var provider OCRProvider
+	for _, p := range append([]OCRProvider{e.ocr}, e.backups...) {
+		if p != nil && p.Name() == name {
+			provider = p
+			break
+		}
+	}
+	if provider == nil {
+		result.Error = fmt.Sprintf("未配置识别引擎: %s", name)
+		return result
+	}
+	checker, ok := provider.(CredentialChecker)
+	if !ok {
+		result.Error = fmt.Sprintf("识别引擎 %s 不支持凭据校验", name)
+		return result
+	}
+
+	start := time.Now()
+	err := checker.CheckCredentials()
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		e.logger.Warn("识别引擎凭据校验失败", "provider", name, "error", err)
+		result.Error = err.Error()
+		result.Hint = ProviderHint(err)
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// TestProviders 依次校验全部已配置的云端识别引擎 (主引擎与备用引擎)
+func (e *Extractor) TestProviders() []ProviderCheck {
+	results := []ProviderCheck{}
+	for _, p := range append([]OCRProvider{e.ocr}, e.backups...) {
+		if p != nil {
+			results = append(results, e.TestProvider(p.Name()))
+		}
+	}
+	return results
+}
+
+// CheckCredentials 实现 CredentialChecker：发送不含文件的请求，只验证 Token 与网络连通性，不触发识别、不消耗配额。
+// 服务端先校验 Token：401/403 表示 Token 无效，因缺少文件参数返回的 400/422 说明 Token 已通过校验
+func (c *BaiduClient) CheckCredentials() error {
+	if c.config.Token == "" {
+		return fmt.Errorf("百度 AI Studio Token 未配置")
+	}
+	req, err := http.NewRequest(http.MethodPost, c.config.ApiUrl, strings.NewReader("{}"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.config.Token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusBadRequest, http.StatusUnprocessableEntity:
+	default:
+		return &baiduHTTPError{StatusCode: resp.StatusCode}
+	}
+	var body struct {
+		ErrorCode int    `json:"error_code"`
+		ErrorMsg  string `json:"error_msg"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&body) == nil && body.ErrorCode == baiduErrDailyLimit {
+		return fmt.Errorf("%w (百度 API 错误 %d: %s)", ErrQuotaExceeded, body.ErrorCode, body.ErrorMsg)
+	}
+	return nil
+}
+
+// CheckCredentials 实现 CredentialChecker，返回 Err
+func (m *MockOCRProvider) CheckCredentials() error {
+	return m.Err
+}
@@ -0,0 +1,185 @@
+package extractor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// QualityKeyPrefix 字段质量评分的元数据键前缀，如 "_quality.defendant" = "0.95"，取值范围 0 ~ 1
+const QualityKeyPrefix = "_quality."
+
+// LowQualityKey 评分低于阈值、需要人工核对的字段键，多个以 RepeatSeparator 连接 (元数据)
+const LowQualityKey = "_lowQuality"
+
+// DefaultQualityThreshold 默认的低质量阈值
+const DefaultQualityThreshold = 0.6
+
+var (
+	nameFields  = map[string]bool{"plaintiff": true, "plaintiffAgent": true, "defendant": true, "thirdParty": true}
+	phoneFormat = regexp.MustCompile(`^(?:1\d{10}|0\d{2,3}-?\d{7,8})$`)
+	// orgSuffixes 单位名称的常见结尾，单位当事人不按人名评分
+	orgSuffixes = []string{"公司", "银行", "分行", "支行", "中心", "事务所", "合作社", "委员会", "医院", "学校", "研究院", "厂", "店", "局", "部"}
+)
+
+// commonSurnames 常见姓氏 (覆盖绝大多数人口)，复姓以首字计
+const commonSurnames = "王李张刘陈杨黄赵吴周徐孙马朱胡郭何高林罗郑梁谢宋唐许韩冯邓曹彭曾肖田董袁潘于蒋蔡余杜叶程苏魏吕丁任沈姚卢姜崔钟谭陆汪范金石廖贾夏韦付方白邹孟熊秦邱江尹薛闫段雷侯龙史陶黎贺顾毛郝龚邵万钱严覃武戴莫孔向汤常温康施文牛樊葛邢安齐易乔伍庞颜倪庄聂章鲁岳翟殷詹申欧耿关兰焦俞左柳甘祝包宁尚符舒阮柯纪梅童凌毕单季裴霍涂成苗谷盛曲翁冉骆蓝路游辛靳管柴蒙鲍华喻祁蒲房滕屈饶解牟艾尤阳时穆农司卓古吉缪简车项连芦麦褚娄窦戚岑景党宫费卜冷晏席卫米柏宗瞿桂全佟应臧闵苟邬边卞姬师和仇栾隋商刁沙荣巫寇桑郎甄丛仲虞敖巩明佘池查麻苑迟邝欧阳上官司马诸葛"
+
+// FieldQuality 对单个字段的取值做启发式质量评分 (0 ~ 1)，用于发现识别出的乱码：
+// 身份证号码以校验位为准，电话、日期、金额等结构化字段看格式，人名看姓氏与常用字，
+// 其余文本看常用字、数字与标点所占的比例。空值返回 1
+func FieldQuality(field, value string) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 1
+	}
+	switch {
+	case field == "idNumber" || field == "plaintiffId" || field == "thirdPartyId":
+		return averageQuality(value, idQuality)
+	case field == "plaintiffPhone":
+		return averageQuality(value, func(s string) float64 { return boolScore(phoneFormat.MatchString(s)) })
+	case field == "gender":
+		return boolScore(value == "男" || value == "女")
+	case field == "birthday":
+		_, err := time.Parse("2006-01-02", value)
+		return boolScore(err == nil)
+	case field == "amountValue":
+		_, err := strconv.ParseFloat(value, 64)
+		return boolScore(err == nil)
+	case nameFields[field]:
+		return averageQuality(value, nameQuality)
+	default:
+		return textQuality(value)
+	}
+}
+
+// averageQuality 对以 RepeatSeparator 连接的多个取值分别评分后取平均
+func averageQuality(value string, score func(string) float64) float64 {
+	parts := strings.Split(value, RepeatSeparator)
+	total := 0.0
+	for _, p := range parts {
+		total += score(strings.TrimSpace(p))
+	}
+	return total / float64(len(parts))
+}
+
+func boolScore(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+// idQuality 校验通过为 1；格式正确但校验位或日期不对多为个别字符识别错误，给 0.3
+func idQuality(id string) float64 {
+	if _, ok := ParseIDNumber(id); ok {
+		return 1
+	}
+	if len(id) == 18 && strings.Trim(strings.ToUpper(id[:17]), "0123456789") == "" {
+		return 0.3
+	}
+	return 0
+}
+
+// nameQuality 人名：2 ~ 4 个汉字、以常见姓氏开头、由常用字组成时得分高 (非汉字不得分)；单位名称按文本评分
+func nameQuality(name string) float64 {
+	for _, suffix := range orgSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return textQuality(name)
+		}
+	}
+	runes := []rune(strings.ReplaceAll(name, "·", ""))
+	if len(runes) < 2 || len(runes) > 4 {
+		// 少数民族、外籍人名较长，只看用字
+		return textQuality(name) * 0.8
+	}
+	score := 0.0
+	for _, r := range runes {
+		if unicode.Is(unicode.Han, r) {
+			score += charQuality(r)
+		}
+	}
+	score /= float64(len(runes))
+	if !strings.ContainsRune(commonSurnames, runes[0]) {
+		score *= 0.7
+	}
+	return score
+}
+
+// textQuality 常用字、数字、字母与标点所占的 (加权) 比例
+func textQuality(s string) float64 {
+	total, score := 0, 0.0
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		score += charQuality(r)
+	}
+	if total == 0 {
+		return 1
+	}
+	return score / float64(total)
+}
+
+// charQuality 单个字符的可信度：GB2312 一级汉字 (3755 个常用字) 为 1，二级汉字为 0.5，
+// 其余汉字 (乱码中常见的生僻字) 为 0；数字、字母与常见标点为 1
+func charQuality(r rune) float64 {
+	if r < 0x80 {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return 1
+		}
+		return 0
+	}
+	if !unicode.Is(unicode.Han, r) {
+		if unicode.IsPunct(r) || unicode.IsDigit(r) || r == '×' {
+			return 1
+		}
+		return 0.5
+	}
+	b, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(string(r)))
+	if err != nil || len(b) != 2 {
+		return 0
+	}
+	switch {
+	case b[0] >= 0xB0 && b[0] <= 0xD7 && b[1] >= 0xA1:
+		return 1
+	case b[0] >= 0xD8 && b[0] <= 0xF7 && b[1] >= 0xA1:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// scoreRecords 返回附带各字段质量评分的记录副本，评分低于 threshold 的字段列入 LowQualityKey
+func scoreRecords(records []Record, threshold float64) []Record {
+	out := make([]Record, 0, len(records))
+	for _, rec := range records {
+		r := make(Record, len(rec)*2)
+		var low []string
+		for _, k := range exportFieldOrder {
+			v, ok := rec[k]
+			if !ok || k == "page" || k == SummaryKey || strings.TrimSpace(v) == "" {
+				continue
+			}
+			q := FieldQuality(k, v)
+			r[QualityKeyPrefix+k] = fmt.Sprintf("%.2f", q)
+			if q < threshold {
+				low = append(low, k)
+			}
+		}
+		for k, v := range rec {
+			r[k] = v
+		}
+		if len(low) > 0 {
+			r[LowQualityKey] = strings.Join(low, RepeatSeparator)
+		}
+		out = append(out, r)
+	}
+	return out
+}
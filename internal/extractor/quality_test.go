@@ -0,0 +1,51 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFieldQuality(t *testing.T) {
+	tests := []struct {
+		field, value string
+		high         bool
+	}{
+		{"defendant", "张三", true},
+		{"defendant", "欧阳明华", true},
+		{"defendant", "北京某某科技有限公司", true},
+		{"defendant", "䶮靐龘", false}, // 识别出的生僻字乱码
+		{"defendant", "a#9", false},
+		{"idNumber", "110101199001011237", true},
+		{"idNumber", "110101199001011234", false}, // 校验位错误
+		{"idNumber", "11O1O119900101I237", false},
+		{"plaintiffPhone", "13800138000", true},
+		{"plaintiffPhone", "1380013", false},
+		{"birthday", "1990-01-01", true},
+		{"birthday", "1990-13-45", false},
+		{"request", "一、判令被告偿还借款人民币10000元；\n二、本案诉讼费由被告承担。", true},
+		{"factsReason", "冫刂丬亻彳氵犭纟钅礻衤囗", false},
+	}
+	for _, tt := range tests {
+		q := FieldQuality(tt.field, tt.value)
+		if high := q >= DefaultQualityThreshold; high != tt.high {
+			t.Errorf("FieldQuality(%s, %q) = %.2f, expected high=%v", tt.field, tt.value, q, tt.high)
+		}
+	}
+}
+
+func TestExtractText_ScoreQuality(t *testing.T) {
+	e := NewExtractor(nil)
+	e.ScoreQuality = true
+	text := "被告：龘靐齉，身份证号码：110101199001011237\n诉讼请求：\n一、判令被告偿还借款10000元。\n事实与理由：\n借款未还。\n此致"
+	records, err := e.ExtractText(text, nil)
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %v (%v)", records, err)
+	}
+	r := records[0]
+	if r[QualityKeyPrefix+"idNumber"] != "1.00" {
+		t.Errorf("Expected a perfect idNumber score, got %q", r[QualityKeyPrefix+"idNumber"])
+	}
+	if r[LowQualityKey] != "defendant" || !strings.HasPrefix(r[QualityKeyPrefix+"defendant"], "0.") {
+		t.Errorf("Expected only defendant flagged, got %q (score %q)", r[LowQualityKey], r[QualityKeyPrefix+"defendant"])
+	}
+}
@@ -0,0 +1,162 @@
+package extractor
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrTextNotCached 没有该文件的缓存原文：文件尚未提取过、原文已过期或被淘汰、已重启，或提取路径不缓存原文
+// (混合解析、本地系统识别、邮件、不返回原文的识别引擎)
+var ErrTextNotCached = errors.New("没有该文件的缓存原文，请重新提取")
+
+// FileHash 返回文件内容的 SHA-256 (十六进制)，即提取缓存与 ReparseFields 使用的键
+func FileHash(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// 提取缓存 (结果与原文) 的默认有效期与容量。原文与结果含当事人信息，不宜长期驻留内存
+const (
+	DefaultTextCacheTTL  = 10 * time.Minute
+	DefaultTextCacheSize = 100
+)
+
+// cacheEntry 一份文档的提取缓存：提取结果与提取时使用的原文。两者同时写入、同时过期或被淘汰，
+// 因此命中结果缓存时 ReparseFields 总能取到原文。ocr 与 local 只有一项非空，都为空表示该提取路径不缓存原文
+type cacheEntry struct {
+	records []Record   // 提取结果，为 nil 表示提取尚未完成或未提取到记录
+	path    string     // 得到 records 时实际采用的提取路径 (PlanNative 等)
+	ocr     []OCRPage  // 识别引擎返回的各页原文，按识别结果规则解析
+	local   []pageText // DOCX 正文 (一项，无页码) 或 PDF 各页文本层，按本地文本规则解析
+	expires time.Time
+}
+
+// pageText 一页正文，page 为 0 表示没有页码
+type pageText struct {
+	page int
+	text string
+}
+
+// cacheLimits 返回提取缓存的有效期与容量，未设置时使用默认值
+func (e *Extractor) cacheLimits() (time.Duration, int) {
+	ttl, size := e.TextCacheTTL, e.TextCacheSize
+	if ttl <= 0 {
+		ttl = DefaultTextCacheTTL
+	}
+	if size <= 0 {
+		size = DefaultTextCacheSize
+	}
+	return ttl, size
+}
+
+// updateCache 取出 (或新建) key 对应的缓存条目交给 update 修改，并重新计算有效期；
+// 超出容量时先清理过期条目，再淘汰最早写入的条目
+func (e *Extractor) updateCache(key string, update func(*cacheEntry)) {
+	ttl, size := e.cacheLimits()
+	now := time.Now()
+
+	e.cacheMu.Lock()
+	defer e.cacheMu.Unlock()
+	c, exists := e.cache[key]
+	if !exists {
+		e.cacheOrder = append(e.cacheOrder, key)
+	}
+	if !exists || now.After(c.expires) {
+		c = &cacheEntry{}
+		e.cache[key] = c
+	}
+	update(c)
+	c.expires = now.Add(ttl)
+
+	if len(e.cache) <= size {
+		return
+	}
+	var kept []string
+	for _, k := range e.cacheOrder {
+		if c, ok := e.cache[k]; !ok || now.After(c.expires) {
+			delete(e.cache, k)
+			continue
+		}
+		kept = append(kept, k)
+	}
+	for len(kept) > size {
+		delete(e.cache, kept[0])
+		kept = kept[1:]
+	}
+	e.cacheOrder = kept
+}
+
+// cachedEntry 返回 key 对应的未过期缓存条目
+func (e *Extractor) cachedEntry(key string) (*cacheEntry, bool) {
+	e.cacheMu.RLock()
+	defer e.cacheMu.RUnlock()
+	c, ok := e.cache[key]
+	if !ok || time.Now().After(c.expires) {
+		return nil, false
+	}
+	return c, true
+}
+
+// cacheText 按文件内容哈希缓存提取时使用的原文 (ocr 与 local 只传一项)
+func (e *Extractor) cacheText(fileData []byte, ocr []OCRPage, local []pageText) {
+	e.updateCache(e.calculateHash(fileData), func(c *cacheEntry) {
+		c.ocr, c.local = ocr, local
+	})
+}
+
+// forgetText 删除缓存的原文，用于结果无法仅凭原文复现的提取路径 (如混合解析)
+func (e *Extractor) forgetText(fileData []byte) {
+	e.cacheMu.Lock()
+	if c, ok := e.cache[e.calculateHash(fileData)]; ok {
+		c.ocr, c.local = nil, nil
+	}
+	e.cacheMu.Unlock()
+}
+
+// ReparseFields 以新的字段选择重新解析已提取过的文件 (fileHash 见 FileHash)：只对缓存的原文重新执行本地规则，
+// 不再读取文件或调用识别引擎。结果同样经过标注清理、后处理与必填字段检查；
+// 没有缓存原文 (含已过期或已被淘汰，见 TextCacheTTL、TextCacheSize) 时返回 ErrTextNotCached
+func (e *Extractor) ReparseFields(fileHash string, fields []string) ([]Record, error) {
+	e.cacheMu.RLock()
+	var t cacheEntry
+	c, ok := e.cache[fileHash]
+	if ok {
+		t = *c
+	}
+	e.cacheMu.RUnlock()
+	if !ok || time.Now().After(t.expires) || (t.ocr == nil && t.local == nil) {
+		return nil, ErrTextNotCached
+	}
+	e.logger.Info("使用缓存原文重新解析", "hash", fileHash[:min(8, len(fileHash))], "fields", fields)
+
+	var records []Record
+	if t.ocr != nil {
+		var err error
+		records, err = e.patterns.parseOCRPages(e.logger, "cache", t.ocr, nil)
+		if err != nil && !errors.Is(err, ErrNoData) {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			records = keepFields(records, append([]string{"page"}, fields...))
+		}
+	} else {
+		if len(fields) == 0 {
+			for k := range PatternRegistry {
+				fields = append(fields, k)
+			}
+		}
+		for _, p := range t.local {
+			pageRecords := e.parseCases(p.text, fields)
+			if p.page > 0 {
+				for _, r := range pageRecords {
+					r["page"] = strconv.Itoa(p.page)
+				}
+			}
+			records = append(records, pageRecords...)
+		}
+	}
+	return e.finishRecords(context.Background(), records)
+}
@@ -0,0 +1,128 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"legal-extractor/internal/config"
+)
+
+func TestReparseFields_ReducedFieldSet(t *testing.T) {
+	for _, name := range []string{"complaint.docx", "complaint.pdf"} {
+		t.Run(name, func(t *testing.T) {
+			data := readFixture(t, name)
+			e := NewExtractor(nil)
+			full, err := e.ExtractData(data, name, FieldKeys(), nil)
+			if err != nil {
+				t.Fatalf("ExtractData returned error: %v", err)
+			}
+			if len(full) == 0 || full[0]["request"] == "" {
+				t.Fatalf("Expected a record with a request, got %v", full)
+			}
+
+			records, err := e.ReparseFields(FileHash(data), []string{"defendant", "idNumber"})
+			if err != nil {
+				t.Fatalf("ReparseFields returned error: %v", err)
+			}
+			if len(records) != len(full) {
+				t.Fatalf("Expected %d records, got %d", len(full), len(records))
+			}
+			for i, r := range records {
+				if r["defendant"] != full[i]["defendant"] || r["idNumber"] != full[i]["idNumber"] {
+					t.Errorf("record %d: expected %q/%q, got %q/%q", i, full[i]["defendant"], full[i]["idNumber"], r["defendant"], r["idNumber"])
+				}
+				if _, ok := r["request"]; ok {
+					t.Errorf("record %d: request was not selected but got %q", i, r["request"])
+				}
+				if r["page"] != full[i]["page"] {
+					t.Errorf("record %d: page %q, want %q", i, r["page"], full[i]["page"])
+				}
+			}
+		})
+	}
+}
+
+func TestReparseFields_OCRTextWithoutSecondCall(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		fmt.Fprint(w, `{"error_code":0,"result":{"layoutParsingResults":[`+
+			`{"markdown":{"text":"# 民事起诉状\n被告：张三，男，身份证号码：110101199001011237\n## 诉讼请求\n1. 判令被告偿还借款 10000 元\n"}},`+
+			`{"markdown":{"text":"证据清单\n"}}]}}`)
+	}))
+	defer srv.Close()
+
+	data := readFixture(t, "scanned.pdf")
+	e := NewExtractor(nil)
+	e.SetOCRProvider(newTestBaiduClient(srv, config.BaiduConfig{}))
+	if _, err := e.ExtractData(data, "scanned.pdf", nil, nil); err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+
+	records, err := e.ReparseFields(FileHash(data), []string{"defendant"})
+	if err != nil {
+		t.Fatalf("ReparseFields returned error: %v", err)
+	}
+	if len(records) != 1 || records[0]["defendant"] != "张三" || records[0]["page"] != "1" {
+		t.Fatalf("Expected the defendant from the cached text, got %v", records)
+	}
+	if _, ok := records[0]["idNumber"]; ok {
+		t.Errorf("idNumber was not selected but got %q", records[0]["idNumber"])
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected a single OCR request, got %d", n)
+	}
+}
+
+func TestReparseFields_NotCached(t *testing.T) {
+	if _, err := NewExtractor(nil).ReparseFields(FileHash([]byte("never extracted")), nil); !errors.Is(err, ErrTextNotCached) {
+		t.Errorf("Expected ErrTextNotCached, got %v", err)
+	}
+}
+
+func TestReparseFields_EvictedText(t *testing.T) {
+	first, second := readFixture(t, "complaint.docx"), readFixture(t, "complaint.pdf")
+	e := NewExtractor(nil)
+	e.TextCacheSize = 1
+	if _, err := e.ExtractData(first, "complaint.docx", nil, nil); err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if _, err := e.ExtractData(second, "complaint.pdf", nil, nil); err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+
+	if _, err := e.ReparseFields(FileHash(first), []string{"defendant"}); !errors.Is(err, ErrTextNotCached) {
+		t.Errorf("Expected ErrTextNotCached for the evicted file, got %v", err)
+	}
+	if _, err := e.ReparseFields(FileHash(second), []string{"defendant"}); err != nil {
+		t.Errorf("ReparseFields returned error for the newest file: %v", err)
+	}
+}
+
+func TestReparseFields_ExpiredText(t *testing.T) {
+	data := readFixture(t, "complaint.docx")
+	e := NewExtractor(nil)
+	e.TextCacheTTL = time.Nanosecond
+	if _, err := e.ExtractData(data, "complaint.docx", nil, nil); err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	if _, err := e.ReparseFields(FileHash(data), nil); !errors.Is(err, ErrTextNotCached) {
+		t.Errorf("Expected ErrTextNotCached for the expired text, got %v", err)
+	}
+
+	// 提取结果与原文同时过期：再次提取会重新解析并缓存原文，而不是命中结果缓存却没有原文
+	e.TextCacheTTL = time.Minute
+	if _, err := e.ExtractData(data, "complaint.docx", nil, nil); err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+	if records, err := e.ReparseFields(FileHash(data), []string{"defendant"}); err != nil || len(records) == 0 {
+		t.Errorf("Expected the re-extraction to refill the text cache, got %v (%v)", records, err)
+	}
+}
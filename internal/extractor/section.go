@@ -0,0 +1,94 @@
+package extractor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sectionAnchor 一个正文段落的定位规则，与 parseCases 中对应字段的规则一致
+type sectionAnchor struct {
+	full  *regexp.Regexp
+	label *regexp.Regexp
+	next  []*regexp.Regexp
+}
+
+// sectionAnchors 支持 ExtractSection 的段落
+func sectionAnchors() map[string]sectionAnchor {
+	return map[string]sectionAnchor{
+		"request":     {DefaultPatterns.Request, DefaultPatterns.RequestLabel, []*regexp.Regexp{DefaultPatterns.FactsLabel}},
+		"factsReason": {DefaultPatterns.Facts, DefaultPatterns.FactsLabel, []*regexp.Regexp{DefaultPatterns.RequestLabel}},
+	}
+}
+
+// ExtractSection 返回文档中某一段落 (sectionKey 为 "request" 诉讼请求或 "factsReason" 事实与理由，不区分大小写)
+// 的原文，不做合并断行等整理；多个案件的段落按出现顺序以空行连接。
+// DOCX 与带文本层的 PDF 直接定位原文 (PDF 各页连续拼接，跨页的段落不会被截断)；
+// 扫描件等需识别的文件退回 ExtractData，连接各记录中的该字段
+func (e *Extractor) ExtractSection(fileData []byte, fileName, sectionKey string) (string, error) {
+	key := canonicalFieldKey(sectionKey)
+	anchor, ok := sectionAnchors()[key]
+	if !ok {
+		return "", fmt.Errorf("不支持提取段落 %q (支持 request、factsReason)", sectionKey)
+	}
+
+	text, err := e.documentText(fileData, fileName)
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		records, err := e.ExtractData(fileData, fileName, []string{key}, nil)
+		if err != nil {
+			return "", err
+		}
+		var sections []string
+		for _, r := range records {
+			if v := strings.TrimSpace(r[key]); v != "" {
+				sections = append(sections, v)
+			}
+		}
+		return strings.Join(sections, "\n\n"), nil
+	}
+
+	var sections []string
+	for _, part := range DefaultPatterns.Split.Split(text, -1) {
+		if loc := DefaultPatterns.Annex.FindStringIndex(part); loc != nil {
+			part = part[:loc[0]]
+		}
+		if start, end, ok := e.patterns.sectionSpan(part, anchor.full, anchor.label, anchor.next...); ok {
+			sections = append(sections, strings.TrimSpace(part[start:end]))
+		}
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// documentText 返回 DOCX 或带文本层 PDF 的全文；需要识别的文件 (扫描件、图片、邮件) 返回空串
+func (e *Extractor) documentText(fileData []byte, fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".docx":
+		return extractTextFromDocx(fileData, e.ResolveDocxNumbering)
+	case ".pdf":
+		doc, err := e.pdfText().Open(fileData)
+		if err != nil {
+			if isPdfEncryptionError(err) {
+				return "", ErrPDFEncrypted
+			}
+			return "", nil
+		}
+		var sb strings.Builder
+		textLayer := false
+		for page := 1; page <= doc.NumPage(); page++ {
+			t, _ := doc.PageText(page)
+			textLayer = textLayer || e.hasTextLayer(t)
+			sb.WriteString(t)
+			sb.WriteString("\n")
+		}
+		if !textLayer {
+			return "", nil
+		}
+		return sb.String(), nil
+	default:
+		return "", nil
+	}
+}
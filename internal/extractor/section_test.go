@@ -0,0 +1,38 @@
+package extractor
+
+import (
+	"testing"
+)
+
+func TestExtractSection(t *testing.T) {
+	e := NewExtractor(nil)
+	data := readFixture(t, "two_cases.docx")
+
+	facts, err := e.ExtractSection(data, "two_cases.docx", "factsReason")
+	if err != nil {
+		t.Fatalf("ExtractSection: %v", err)
+	}
+	want := "2023年1月1日，被告向原告借款10000元，约定三个月内归还。\n借款到期后，被告至今未还。" +
+		"\n\n2023年2月1日，被告向原告借款5000元，逾期未还。"
+	if facts != want {
+		t.Errorf("factsReason = %q, want %q", facts, want)
+	}
+
+	request, err := e.ExtractSection(data, "two_cases.docx", "REQUEST")
+	if err != nil {
+		t.Fatalf("ExtractSection: %v", err)
+	}
+	if want := "一、判令被告偿还借款10000元；\n二、本案诉讼费用由被告承担。\n\n一、判令被告偿还借款5000元。"; request != want {
+		t.Errorf("request = %q, want %q", request, want)
+	}
+
+	if _, err := e.ExtractSection(data, "two_cases.docx", "defendant"); err == nil {
+		t.Error("ExtractSection(defendant) succeeded, want unsupported section error")
+	}
+
+	// 扫描件经识别引擎提取
+	e.SetOCRProvider(NewMockOCRProvider())
+	if facts, err := e.ExtractSection(readFixture(t, "scanned.pdf"), "scanned.pdf", "factsReason"); err != nil || facts == "" {
+		t.Errorf("scanned factsReason = %q, %v", facts, err)
+	}
+}
@@ -0,0 +1,76 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+
+	"legal-extractor/internal/config"
+)
+
+// SummaryKey is the record key holding the flattened one-line summary
+const SummaryKey = "summary"
+
+// DefaultSummaryTemplate is used when neither the export options nor conf.yaml set a template.
+// Segments are separated by "；"; a segment whose placeholders are all empty is dropped.
+const DefaultSummaryTemplate = "被告：{defendant}；身份证：{idNumber}；诉讼请求：{request}；金额：{amount}；事实与理由：{factsReason}"
+
+var summaryPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// FormatSummary renders one record through template. Placeholders are field keys
+// in braces, e.g. {defendant}; multi-line values are collapsed onto one line.
+func FormatSummary(r Record, template string) string {
+	if template == "" {
+		template = DefaultSummaryTemplate
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(template, "；") {
+		filled := false
+		out := summaryPlaceholder.ReplaceAllStringFunc(segment, func(m string) string {
+			value := strings.Join(strings.Fields(r[m[1:len(m)-1]]), " ")
+			if value != "" {
+				filled = true
+			}
+			return value
+		})
+		if filled || !summaryPlaceholder.MatchString(segment) {
+			segments = append(segments, out)
+		}
+	}
+	return strings.Join(segments, "；")
+}
+
+// summaryTemplate resolves the template from the options, then conf.yaml, then the default
+func summaryTemplate(opts ExportOptions) string {
+	if opts.SummaryTemplate != "" {
+		return opts.SummaryTemplate
+	}
+	if t := config.Get().Export.SummaryTemplate; t != "" {
+		return t
+	}
+	return DefaultSummaryTemplate
+}
+
+// withSummary returns copies of records carrying a summary field when opts asks for one.
+// With SummaryOnly the structured fields are dropped, keeping page and "_" metadata.
+// The input records are never modified.
+func withSummary(records []Record, opts ExportOptions) []Record {
+	if !opts.Summary && !opts.SummaryOnly {
+		return records
+	}
+	template := summaryTemplate(opts)
+
+	out := make([]Record, 0, len(records))
+	for _, r := range records {
+		nr := make(Record, len(r)+1)
+		for k, v := range r {
+			if opts.SummaryOnly && k != "page" && !strings.HasPrefix(k, "_") {
+				continue
+			}
+			nr[k] = v
+		}
+		nr[SummaryKey] = FormatSummary(r, template)
+		out = append(out, nr)
+	}
+	return out
+}
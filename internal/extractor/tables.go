@@ -0,0 +1,124 @@
+package extractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ErrTablesUnsupported 该格式不支持表格提取
+var ErrTablesUnsupported = errors.New("表格提取目前仅支持 DOCX 文件")
+
+// Table 文书中的一张表格 (如证据清单、还款计划)，单元格按出现顺序排列。
+// 合并单元格按 Word 中实际的单元格计，因此各行的列数可能不同
+type Table struct {
+	// Index 表格在文档中的序号，从 1 开始
+	Index int `json:"index"`
+	// Title 表格之前最近一段非空文字，通常是表名 (如 "证据清单")
+	Title string     `json:"title,omitempty"`
+	Rows  [][]string `json:"rows"`
+}
+
+// ExtractTables 提取文档中的表格，与字段提取相互独立。目前仅支持 DOCX (<w:tbl>)，其他格式返回 ErrTablesUnsupported。
+// 只提取最外层表格，嵌套表格的文字并入所在单元格
+func (e *Extractor) ExtractTables(fileData []byte, fileName string) ([]Table, error) {
+	if strings.ToLower(filepath.Ext(fileName)) != ".docx" {
+		return nil, ErrTablesUnsupported
+	}
+	r, err := zip.NewReader(bytes.NewReader(fileData), int64(len(fileData)))
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []Table
+	for _, part := range mainDocumentParts(r) {
+		partTables, err := docxTables(r, part)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range partTables {
+			t.Index = len(tables) + 1
+			tables = append(tables, t)
+		}
+	}
+	return tables, nil
+}
+
+// docxTables 读取一个文档部件中的最外层表格
+func docxTables(r *zip.Reader, part string) ([]Table, error) {
+	f, err := openZipFile(r, part)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		tables    []Table
+		current   *Table
+		depth     int             // 表格嵌套层数，0 表示不在表格内
+		cell      strings.Builder // 当前单元格文字
+		paragraph strings.Builder // 表格外当前段落的文字
+		title     string          // 表格外最近一段非空文字
+	)
+	decoder := xml.NewDecoder(f)
+	for {
+		t, _ := decoder.Token()
+		if t == nil {
+			break
+		}
+		switch se := t.(type) {
+		case xml.StartElement:
+			switch se.Name.Local {
+			case "tbl":
+				depth++
+				if depth == 1 {
+					current = &Table{Title: title}
+				}
+			case "tr":
+				if depth == 1 {
+					current.Rows = append(current.Rows, nil)
+				}
+			case "tc":
+				if depth == 1 {
+					cell.Reset()
+				}
+			case "t":
+				var s string
+				if err := decoder.DecodeElement(&s, &se); err != nil {
+					continue
+				}
+				if depth > 0 {
+					cell.WriteString(s)
+				} else {
+					paragraph.WriteString(s)
+				}
+			}
+		case xml.EndElement:
+			switch se.Name.Local {
+			case "tbl":
+				depth--
+				if depth == 0 {
+					tables = append(tables, *current)
+					current, title = nil, ""
+				}
+			case "tc":
+				if depth == 1 && len(current.Rows) > 0 {
+					last := len(current.Rows) - 1
+					current.Rows[last] = append(current.Rows[last], strings.TrimSpace(cell.String()))
+				}
+			case "p":
+				if depth > 0 {
+					// 单元格内的多个段落以换行分隔
+					cell.WriteString("\n")
+				} else if s := strings.TrimSpace(paragraph.String()); s != "" {
+					title = s
+				}
+				paragraph.Reset()
+			}
+		}
+	}
+	return tables, nil
+}
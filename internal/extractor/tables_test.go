@@ -0,0 +1,69 @@
+package extractor
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExtractTables_DOCX(t *testing.T) {
+	e := NewExtractor(nil)
+	data := readFixture(t, "tables.docx")
+	tables, err := e.ExtractTables(data, "tables.docx")
+	if err != nil {
+		t.Fatalf("ExtractTables: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("tables = %d, want 2", len(tables))
+	}
+
+	evidence := tables[0]
+	if evidence.Index != 1 || evidence.Title != "证据清单" {
+		t.Errorf("table 1 = #%d %q, want #1 证据清单", evidence.Index, evidence.Title)
+	}
+	want := [][]string{
+		{"序号", "证据名称", "证明目的"},
+		{"1", "借款合同", "证明借贷关系成立"},
+		{"2", "银行转账凭证\n（2023年1月1日）", "证明原告已交付借款"},
+	}
+	if len(evidence.Rows) != len(want) {
+		t.Fatalf("rows = %v, want %v", evidence.Rows, want)
+	}
+	for i := range want {
+		if strings.Join(evidence.Rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, evidence.Rows[i], want[i])
+		}
+	}
+	if tables[1].Title != "还款计划" || tables[1].Rows[1][2] != "10000元" {
+		t.Errorf("table 2 = %+v", tables[1])
+	}
+
+	// 字段提取不受表格影响
+	records, err := e.ExtractData(data, "tables.docx", allFields, nil)
+	if err != nil || len(records) != 1 || records[0]["defendant"] != "张三" {
+		t.Errorf("ExtractData = %v, %v", records, err)
+	}
+
+	if _, err := e.ExtractTables(readFixture(t, "complaint.pdf"), "complaint.pdf"); !errors.Is(err, ErrTablesUnsupported) {
+		t.Errorf("PDF err = %v, want ErrTablesUnsupported", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tables.xlsx")
+	if err := ExportTablesExcel(path, tables); err != nil {
+		t.Fatalf("ExportTablesExcel: %v", err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); strings.Join(sheets, "|") != "证据清单|还款计划" {
+		t.Errorf("sheets = %v", sheets)
+	}
+	if v, _ := f.GetCellValue("证据清单", "B3"); v != "银行转账凭证\n（2023年1月1日）" {
+		t.Errorf("B3 = %q", v)
+	}
+}
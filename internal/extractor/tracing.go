@@ -0,0 +1,67 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName 提取器的 OpenTelemetry instrumentation 名称
+const tracerName = "legal-extractor/extractor"
+
+// startSpan 开启一个追踪 span。未注册 TracerProvider 时使用全局 noop 实现，几乎没有开销。
+// 属性只记录引擎、页数、记录数等统计信息，不包含任何当事人信息
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan 记录错误状态并结束 span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// parseWithProvider 调用云端识别引擎，以 span 记录引擎名称与识别出的记录数；不自行计数的引擎按一次调用累计用量 (pages 为送入识别的页数)。
+// 引擎实现 PageRecognizer 时在本地解析其返回的原文，并一同返回原文供缓存，否则原文为 nil；
+// 实现 ContextPageRecognizer 的引擎在本 span 下发起请求，追踪上下文随请求传给识别服务
+func (e *Extractor) parseWithProvider(ctx context.Context, p OCRProvider, fileData []byte, isPdf bool, pages int, onProgress ProgressCallback) ([]Record, []OCRPage, error) {
+	ctx, span := startSpan(ctx, "ocr.ParseDocument",
+		attribute.String("ocr.provider", p.Name()),
+		attribute.Bool("ocr.is_pdf", isPdf),
+		attribute.Int("file.size", len(fileData)),
+	)
+	var records []Record
+	var text []OCRPage
+	var err error
+	if r, ok := p.(ContextPageRecognizer); ok {
+		text, err = r.RecognizePagesContext(ctx, fileData, isPdf, onProgress)
+		if err == nil {
+			records, err = e.patterns.parseOCRPages(e.logger, p.Name(), text, onProgress)
+		}
+	} else if r, ok := p.(PageRecognizer); ok {
+		text, err = r.RecognizePages(fileData, isPdf, onProgress)
+		if err == nil {
+			records, err = e.patterns.parseOCRPages(e.logger, p.Name(), text, onProgress)
+		}
+	} else {
+		records, err = p.ParseDocument(fileData, isPdf, onProgress)
+	}
+	span.SetAttributes(attribute.Int("extractor.record_count", len(records)))
+	// 未解析出记录不是调用失败，不计入失败次数
+	callErr := err
+	if errors.Is(err, ErrNoData) {
+		callErr = nil
+	}
+	endSpan(span, callErr)
+	if _, ok := p.(callCounter); !ok {
+		e.usage.Record(p.Name(), 1, pages, callErr)
+	}
+	return records, text, err
+}
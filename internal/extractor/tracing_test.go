@@ -0,0 +1,110 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"legal-extractor/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestExtractData_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	e := NewExtractor(nil)
+	e.SetOCRProvider(NewMockOCRProvider())
+	records, err := e.ExtractData(readFixture(t, "scanned.pdf"), "scanned.pdf", allFields, nil)
+	if err != nil {
+		t.Fatalf("ExtractData returned error: %v", err)
+	}
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name] = s
+	}
+	root, ok := spans["extractor.ExtractData"]
+	if !ok {
+		t.Fatalf("Missing extractor.ExtractData span, got %v", exporter.GetSpans())
+	}
+	ocr, ok := spans["ocr.ParseDocument"]
+	if !ok {
+		t.Fatalf("Missing ocr.ParseDocument span")
+	}
+	if ocr.Parent.SpanID() != root.SpanContext.SpanID() {
+		t.Errorf("OCR span should be a child of the extraction span")
+	}
+
+	attrs := map[string]string{}
+	for _, s := range []tracetest.SpanStub{root, ocr} {
+		for _, kv := range s.Attributes {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+	}
+	if attrs["ocr.provider"] != "mock" || attrs["pdf.page_count"] != "2" {
+		t.Errorf("Unexpected span attributes: %v", attrs)
+	}
+	// 属性中不得出现当事人信息
+	for k, v := range attrs {
+		if v == records[0]["defendant"] || v == records[0]["idNumber"] {
+			t.Errorf("Span attribute %s leaks PII: %q", k, v)
+		}
+	}
+}
+
+func TestExtractImageContext_PropagatesTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	}()
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		fmt.Fprint(w, `{"error_code":0,"result":{"layoutParsingResults":[{"markdown":{"text":"被告：张三"}}]}}`)
+	}))
+	defer srv.Close()
+
+	e := NewExtractor(nil)
+	e.SetOCRProvider(newTestBaiduClient(srv, config.BaiduConfig{}))
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "caller")
+	if _, err := e.ExtractImageContext(ctx, []byte("image"), nil, nil); err != nil {
+		t.Fatalf("ExtractImageContext returned error: %v", err)
+	}
+	parent.End()
+
+	// 识别请求携带调用方的 trace ID，父 span 为 ocr.ParseDocument
+	traceID := parent.SpanContext().TraceID().String()
+	if !strings.Contains(traceparent, traceID) {
+		t.Fatalf("traceparent %q does not carry the caller's trace %s", traceparent, traceID)
+	}
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "ocr.ParseDocument" && !strings.Contains(traceparent, s.SpanContext.SpanID().String()) {
+			t.Errorf("traceparent %q should reference the ocr.ParseDocument span", traceparent)
+		}
+	}
+
+	// ctx 已取消时不再发出识别请求
+	traceparent = ""
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.ExtractImageContext(canceled, []byte("image2"), nil, nil); err == nil || traceparent != "" {
+		t.Errorf("Expected a canceled context to abort the request, got err=%v", err)
+	}
+}
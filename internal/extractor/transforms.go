@@ -0,0 +1,184 @@
+package extractor
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Transform 对一条记录做后处理 (如清理空白、脱敏、计算派生字段)，返回处理后的记录；返回 nil 表示丢弃该记录。
+// 传入的是记录的副本，可以直接修改后返回
+type Transform func(Record) Record
+
+// 内置后处理的名称，可在 extraction.transforms 中按名称启用
+const (
+	TransformTrimWhitespace = "trim-whitespace"
+	TransformValidateID     = "validate-id"
+	TransformMaskPII        = "mask-pii"
+)
+
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]Transform{
+		TransformTrimWhitespace: trimWhitespace,
+		TransformValidateID:     validateIDs,
+		TransformMaskPII:        maskPII,
+	}
+)
+
+// RegisterTransform 注册一个具名后处理，之后可在配置中按名称启用；同名时覆盖已有的注册
+func RegisterTransform(name string, t Transform) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	transforms[name] = t
+}
+
+// TransformNames 返回已注册的后处理名称 (按名称排序)
+func TransformNames() []string {
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTransforms 按名称依次查找已注册的后处理，存在未注册的名称时返回错误
+func LookupTransforms(names []string) ([]Transform, error) {
+	var list []Transform
+	for _, name := range names {
+		transformsMu.RLock()
+		t, ok := transforms[strings.TrimSpace(name)]
+		transformsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("未知的后处理 %q (可用: %s)", name, strings.Join(TransformNames(), "、"))
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+// applyTransforms 依次对每条记录的副本执行后处理，缓存中的原始记录不受影响
+func applyTransforms(records []Record, list []Transform) []Record {
+	if len(list) == 0 {
+		return records
+	}
+	out := make([]Record, 0, len(records))
+	for _, rec := range records {
+		r := make(Record, len(rec))
+		for k, v := range rec {
+			r[k] = v
+		}
+		for _, t := range list {
+			if r = t(r); r == nil {
+				break
+			}
+		}
+		if r != nil {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// trimWhitespace 去除各字段首尾及每行首尾的空白，并删除多余的空行
+func trimWhitespace(r Record) Record {
+	for k, v := range r {
+		if strings.HasPrefix(k, "_") {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(v, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		r[k] = strings.Join(lines, "\n")
+	}
+	return r
+}
+
+// idFields 含身份证号码的字段
+var idFields = []string{"plaintiffId", "idNumber", "thirdPartyId"}
+
+// validateIDs 校验身份证号码的出生日期与校验位 (见 ParseIDNumber)，无效的号码在 WarningsKey 中提示人工核对
+func validateIDs(r Record) Record {
+	var warnings []string
+	for _, field := range idFields {
+		if r[field] == "" {
+			continue
+		}
+		for _, id := range strings.Split(r[field], RepeatSeparator) {
+			if _, ok := ParseIDNumber(id); !ok {
+				warnings = append(warnings, fmt.Sprintf("%s (%s) 校验未通过", PatternRegistry[field].Label, normalizeIDText(id)))
+			}
+		}
+	}
+	if len(warnings) == 0 {
+		return r
+	}
+	if existing := r[WarningsKey]; existing != "" {
+		warnings = append([]string{existing}, warnings...)
+	}
+	r[WarningsKey] = strings.Join(warnings, "；")
+	return r
+}
+
+// warningDate 提示中的日期 (如身份证号码推导的出生日期)
+var warningDate = regexp.MustCompile(`(\d{4})-\d{2}-\d{2}`)
+
+// maskPII 对外提供数据时隐去敏感信息：身份证号码保留前 6 位与末 4 位，电话号码保留前 3 位与末 4 位，
+// 出生日期只保留年份。提示 (WarningsKey) 中出现的这些取值与日期同样隐去 (validate-id 等会在提示中写出原值)。
+// 姓名不做处理
+func maskPII(r Record) Record {
+	var replace []string // 原值与脱敏后的值交替排列，用于替换提示中的原值
+	mask := func(field string, head, tail int) {
+		v, ok := r[field]
+		if !ok {
+			return
+		}
+		r[field] = maskEach(v, head, tail)
+		for _, p := range strings.Split(v, RepeatSeparator) {
+			if p = strings.TrimSpace(p); p != "" {
+				replace = append(replace, normalizeIDText(p), maskEach(p, head, tail), p, maskEach(p, head, tail))
+			}
+		}
+	}
+	for _, field := range idFields {
+		mask(field, 6, 4)
+	}
+	mask("plaintiffPhone", 3, 4)
+	if b := r["birthday"]; len(b) >= 4 {
+		replace = append(replace, b, b[:4])
+		r["birthday"] = b[:4]
+	}
+
+	if w := r[WarningsKey]; w != "" {
+		if len(replace) > 0 {
+			w = strings.NewReplacer(replace...).Replace(w)
+		}
+		r[WarningsKey] = warningDate.ReplaceAllString(w, "$1")
+	}
+	return r
+}
+
+// maskEach 将 s 中每个取值 (以 RepeatSeparator 分隔) 除首 head 位、末 tail 位以外的字符替换为 "*"
+func maskEach(s string, head, tail int) string {
+	if s == "" {
+		return s
+	}
+	parts := strings.Split(s, RepeatSeparator)
+	for i, p := range parts {
+		runes := []rune(strings.TrimSpace(p))
+		if len(runes) <= head+tail {
+			parts[i] = strings.Repeat("*", len(runes))
+			continue
+		}
+		parts[i] = string(runes[:head]) + strings.Repeat("*", len(runes)-head-tail) + string(runes[len(runes)-tail:])
+	}
+	return strings.Join(parts, RepeatSeparator)
+}
@@ -0,0 +1,86 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransforms_CustomRunsOnEveryRecord(t *testing.T) {
+	RegisterTransform("test-shout", func(r Record) Record {
+		r["defendant"] += "!"
+		r["derived"] = "page-" + r["page"]
+		return r
+	})
+	list, err := LookupTransforms([]string{TransformTrimWhitespace, "test-shout"})
+	if err != nil {
+		t.Fatalf("LookupTransforms returned error: %v", err)
+	}
+
+	data, pages := mergedFixture(t, "complaint.pdf", 3)
+	e := NewExtractor(nil)
+	e.Transforms = list
+	// 第二次命中缓存，后处理仍只作用一次
+	for run := 0; run < 2; run++ {
+		records, err := e.ExtractData(data, "merged.pdf", allFields, nil)
+		if err != nil {
+			t.Fatalf("ExtractData returned error: %v", err)
+		}
+		if len(records) != pages {
+			t.Fatalf("Expected %d records, got %d", pages, len(records))
+		}
+		for _, r := range records {
+			if r["defendant"] != "张三!" || r["derived"] != "page-"+r["page"] {
+				t.Errorf("run %d: transform not applied once: %v", run, r)
+			}
+		}
+	}
+
+	if _, err := LookupTransforms([]string{"no-such-transform"}); err == nil {
+		t.Error("Expected an error for an unregistered transform")
+	}
+}
+
+func TestTransforms_BuiltIns(t *testing.T) {
+	list, err := LookupTransforms([]string{TransformTrimWhitespace, TransformValidateID, TransformMaskPII})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []Record{{
+		"defendant":      "  张三 ",
+		"request":        " 一、偿还借款；\n\n  二、诉讼费由被告承担。 ",
+		"idNumber":       "110101199001011237",
+		"plaintiffId":    "110101199001011235",
+		"plaintiffPhone": "13800138000",
+		"birthday":       "1990-01-01",
+		WarningsKey:      "出生日期 (1990-01-01) 与身份证号码推导的 (1990-01-02) 不一致",
+	}}
+	got := applyTransforms(in, list)[0]
+	want := Record{
+		"defendant":      "张三",
+		"request":        "一、偿还借款；\n二、诉讼费由被告承担。",
+		"idNumber":       "110101********1237",
+		"plaintiffId":    "110101********1235",
+		"plaintiffPhone": "138****8000",
+		"birthday":       "1990",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+	// 校验在脱敏之前进行，只提示无效的号码；提示中的号码与日期同样脱敏
+	if !strings.Contains(got[WarningsKey], "110101********1235") || strings.Contains(got[WarningsKey], "1237") {
+		t.Errorf("Expected a masked warning for the invalid plaintiff ID only, got %q", got[WarningsKey])
+	}
+	for _, leaked := range []string{"110101199001011235", "1990-01-01", "1990-01-02"} {
+		if strings.Contains(got[WarningsKey], leaked) {
+			t.Errorf("Warnings still contain %q: %q", leaked, got[WarningsKey])
+		}
+	}
+	if _, ok := got["thirdPartyId"]; ok {
+		t.Error("mask-pii should not add absent fields")
+	}
+	if in[0]["idNumber"] != "110101199001011237" {
+		t.Error("Transforms must not modify the input records")
+	}
+}
@@ -0,0 +1,139 @@
+package extractor
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded 云端识别接口的调用配额已用尽 (百度错误码 17：每日请求量超限)
+var ErrQuotaExceeded = errors.New("云端识别每日调用量已达上限")
+
+// ProviderUsage 单个识别引擎的累计用量
+type ProviderUsage struct {
+	Calls         int64     `json:"calls"`         // 实际发出的接口请求次数，含分块与重试 (本地识别为处理的页数)
+	Pages         int64     `json:"pages"`         // 送入识别的页数
+	Failures      int64     `json:"failures"`      // 失败的调用次数
+	QuotaExceeded int64     `json:"quotaExceeded"` // 其中因配额用尽失败的次数
+	LastUsed      time.Time `json:"lastUsed"`
+}
+
+// UsageCounter 按识别引擎统计调用量，并尽力持久化到 JSON 文件，方便用户对照配额。
+// 持久化失败不影响识别流程；path 为空时只在内存中统计
+type UsageCounter struct {
+	mu    sync.Mutex
+	path  string
+	usage map[string]ProviderUsage
+}
+
+// NewUsageCounter 创建用量计数器，并从 path 读取此前的统计 (文件不存在或损坏时从零开始)
+func NewUsageCounter(path string) *UsageCounter {
+	u := &UsageCounter{path: path, usage: make(map[string]ProviderUsage)}
+	if path == "" {
+		return u
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &u.usage)
+	}
+	return u
+}
+
+// Record 记录一次识别调用，err 非 nil 时计为失败
+func (u *UsageCounter) Record(provider string, calls, pages int, err error) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	p := u.usage[provider]
+	p.Calls += int64(calls)
+	p.Pages += int64(pages)
+	if err != nil {
+		p.Failures++
+		if errors.Is(err, ErrQuotaExceeded) {
+			p.QuotaExceeded++
+		}
+	}
+	p.LastUsed = time.Now()
+	u.usage[provider] = p
+	u.save()
+}
+
+// Snapshot 返回各引擎用量的副本
+func (u *UsageCounter) Snapshot() map[string]ProviderUsage {
+	out := make(map[string]ProviderUsage)
+	if u == nil {
+		return out
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for k, v := range u.usage {
+		out[k] = v
+	}
+	return out
+}
+
+// Reset 清零全部统计
+func (u *UsageCounter) Reset() error {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage = make(map[string]ProviderUsage)
+	if u.path == "" {
+		return nil
+	}
+	if err := os.Remove(u.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// save 写入统计文件 (调用方持有锁)，先写临时文件再重命名，避免中途退出留下损坏的文件
+func (u *UsageCounter) save() {
+	if u.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(u.usage, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(u.path), 0755); err != nil {
+		return
+	}
+	tmp := u.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, u.path)
+}
+
+// callCounter 由自行统计实际接口调用 (含分块与重试) 的识别引擎实现，提取器不再按文档为其计数
+type callCounter interface {
+	setUsageCounter(u *UsageCounter)
+}
+
+// SetUsageCounter 设置用量计数器 (传入 nil 表示不统计)，并转交给主引擎与备用引擎
+func (e *Extractor) SetUsageCounter(u *UsageCounter) {
+	e.usage = u
+	e.shareUsage(append([]OCRProvider{e.ocr}, e.backups...)...)
+}
+
+// shareUsage 让自行计数的识别引擎使用提取器的用量计数器
+func (e *Extractor) shareUsage(providers ...OCRProvider) {
+	for _, p := range providers {
+		if c, ok := p.(callCounter); ok {
+			c.setUsageCounter(e.usage)
+		}
+	}
+}
+
+// Usage 返回用量计数器，未设置时为 nil
+func (e *Extractor) Usage() *UsageCounter {
+	return e.usage
+}
@@ -0,0 +1,64 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"legal-extractor/internal/config"
+)
+
+func TestUsageCounter_IncrementsPerCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	usage := NewUsageCounter(path)
+
+	data := readFixture(t, "scanned.pdf")
+	var e *Extractor
+	for i := 1; i <= 2; i++ {
+		// 每次使用新的提取器，避开结果缓存，确保都调用识别引擎
+		e = NewExtractor(nil)
+		e.SetOCRProvider(NewMockOCRProvider())
+		e.SetUsageCounter(usage)
+		if _, err := e.ExtractData(data, "scanned.pdf", nil, nil); err != nil {
+			t.Fatalf("ExtractData returned error: %v", err)
+		}
+		got := e.Usage().Snapshot()["mock"]
+		if got.Calls != int64(i) || got.Pages != int64(2*i) || got.Failures != 0 {
+			t.Errorf("After %d extractions: unexpected usage %+v", i, got)
+		}
+	}
+
+	// 统计持久化后可被重新读取
+	if got := NewUsageCounter(path).Snapshot()["mock"]; got.Calls != 2 {
+		t.Errorf("Persisted usage not reloaded, got %+v", got)
+	}
+
+	if err := e.Usage().Reset(); err != nil {
+		t.Fatalf("Reset returned error: %v", err)
+	}
+	if len(e.Usage().Snapshot()) != 0 || len(NewUsageCounter(path).Snapshot()) != 0 {
+		t.Error("Reset should clear both memory and the persisted file")
+	}
+}
+
+func TestBaiduClient_DailyLimitCountsAsQuotaFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error_code":17,"error_msg":"Open api daily request limit reached"}`)
+	}))
+	defer srv.Close()
+
+	e := NewExtractor(nil)
+	e.SetOCRProvider(newTestBaiduClient(srv, config.BaiduConfig{}))
+	e.SetUsageCounter(NewUsageCounter(""))
+
+	_, err := e.ExtractImage([]byte("image"), nil, nil)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if got := e.Usage().Snapshot()["baidu"]; got.Calls != 1 || got.Failures != 1 || got.QuotaExceeded != 1 {
+		t.Errorf("Unexpected usage %+v", got)
+	}
+}
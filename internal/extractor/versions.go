@@ -0,0 +1,165 @@
+package extractor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"legal-extractor/internal/config"
+)
+
+// 模式集标识：未覆盖任何提取规则时为 PatternSetDefault，否则为 PatternSetCustom
+const (
+	PatternSetDefault = "default"
+	PatternSetCustom  = "custom"
+)
+
+// bridgeVersionTimeout 查询桥接工具版本的超时时间，版本查询不应拖慢版本接口
+const bridgeVersionTimeout = 5 * time.Second
+
+// ComponentVersions 各组件的版本信息，供支持人员将问题与具体的桥接工具、识别服务与提取规则对应
+type ComponentVersions struct {
+	// BridgeVersion 本地识别桥接工具 (WinOcrBridge) 的版本，非 Windows 或未找到桥接工具时为空
+	BridgeVersion string `json:"bridgeVersion"`
+	// Providers 已配置的识别引擎及其服务地址
+	Providers []ProviderVersion `json:"providers"`
+	// PatternSet 提取规则是内置的 (default) 还是经配置覆盖的 (custom)
+	PatternSet string `json:"patternSet"`
+	// PatternSetHash 当前生效规则的指纹 (SHA-256 前 12 位)，规则相同的两个部署指纹一致
+	PatternSetHash string `json:"patternSetHash"`
+	// PatternOverrides 覆盖了内置规则的配置项，按配置文件中的写法列出
+	PatternOverrides []string `json:"patternOverrides"`
+}
+
+// ProviderVersion 识别引擎的名称、服务地址与版本，不含任何密钥
+type ProviderVersion struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Version  string `json:"version,omitempty"`
+}
+
+// EndpointReporter 由调用远程服务的识别引擎实现，返回不含凭据的服务地址
+type EndpointReporter interface {
+	Endpoint() string
+}
+
+// Endpoint 实现 EndpointReporter，去掉地址中的用户信息与查询参数
+func (c *BaiduClient) Endpoint() string {
+	return redactEndpoint(c.config.ApiUrl)
+}
+
+// redactEndpoint 只保留服务地址的协议、主机与路径，避免泄露地址中携带的凭据
+func redactEndpoint(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
+
+// bridgeVersion 查询本地桥接工具的版本，测试中可替换
+var bridgeVersion = func(ctx context.Context) (string, error) {
+	if runtime.GOOS != "windows" {
+		return "", nil
+	}
+	bridgePath, err := findWinOcrBridge()
+	if err != nil {
+		return "", nil
+	}
+	output, err := runBridge(ctx, bridgeVersionTimeout, bridgePath, "--version")
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// Versions 汇总桥接工具、识别引擎与提取规则的版本信息。桥接工具版本查询失败时记录日志并留空，不影响其余信息
+func (e *Extractor) Versions(ctx context.Context) ComponentVersions {
+	v := ComponentVersions{Providers: []ProviderVersion{}}
+
+	bridge, err := bridgeVersion(ctx)
+	if err != nil {
+		e.logger.Warn("查询桥接工具版本失败", "error", err)
+	}
+	v.BridgeVersion = bridge
+
+	for _, p := range append([]OCRProvider{e.ocr}, e.backups...) {
+		if p == nil {
+			continue
+		}
+		pv := ProviderVersion{Name: p.Name()}
+		if r, ok := p.(EndpointReporter); ok {
+			pv.Endpoint = r.Endpoint()
+		}
+		v.Providers = append(v.Providers, pv)
+	}
+	if bridge != "" {
+		v.Providers = append(v.Providers, ProviderVersion{Name: "winocr", Version: bridge})
+	}
+
+	overrides := patternOverrides(config.Get())
+	v.PatternOverrides = make([]string, 0, len(overrides))
+	for _, o := range overrides {
+		v.PatternOverrides = append(v.PatternOverrides, o.key)
+	}
+	v.PatternSet = PatternSetDefault
+	if len(overrides) > 0 {
+		v.PatternSet = PatternSetCustom
+	}
+	v.PatternSetHash = patternSetHash(e.ActivePatterns(), overrides)
+	return v
+}
+
+// patternOverride 一项覆盖了内置提取规则的配置及其取值
+type patternOverride struct {
+	key   string
+	value any
+}
+
+// patternOverrides 列出配置中覆盖了内置提取规则的配置项，顺序固定
+func patternOverrides(cfg *config.Config) []patternOverride {
+	ext := cfg.Extraction
+	var out []patternOverride
+	if len(ext.DefendantStopKeywords) > 0 {
+		out = append(out, patternOverride{"extraction.defendant_stop_keywords", ext.DefendantStopKeywords})
+	}
+	if ext.DefendantMaxRunes > 0 && ext.DefendantMaxRunes != DefaultDefMaxRunes {
+		out = append(out, patternOverride{"extraction.defendant_max_runes", ext.DefendantMaxRunes})
+	}
+	if len(ext.SectionEndAnchors) > 0 {
+		out = append(out, patternOverride{"extraction.section_end_anchors", ext.SectionEndAnchors})
+	}
+	if len(ext.FieldPrefixes) > 0 {
+		out = append(out, patternOverride{"extraction.field_prefixes", ext.FieldPrefixes})
+	}
+	if ws := strings.TrimSpace(ext.NameWhitespace); ws != "" && ws != NameWhitespaceCompact {
+		out = append(out, patternOverride{"extraction.name_whitespace", ws})
+	}
+	if ext.StrictIDLabel {
+		out = append(out, patternOverride{"extraction.strict_id_label", true})
+	}
+	if aliases := cfg.OCR.FieldAliases; len(aliases) > 0 {
+		out = append(out, patternOverride{"ocr.field_aliases", aliases})
+	}
+	return out
+}
+
+// patternSetHash 计算生效正则与各项覆盖配置的指纹
+func patternSetHash(active ActivePatterns, overrides []patternOverride) string {
+	settings := make(map[string]any, len(overrides))
+	for _, o := range overrides {
+		settings[o.key] = o.value
+	}
+	data, _ := json.Marshal(struct {
+		Patterns  ActivePatterns `json:"patterns"`
+		Overrides map[string]any `json:"overrides"`
+	}{active, settings})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
@@ -5,6 +5,7 @@ import (
 
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"legal-extractor/internal/app"
 	"legal-extractor/internal/config"
@@ -27,6 +28,7 @@ func main() {
 	// 2. Initialize Extractor
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	ext := extractor.NewExtractor(logger)
+	ext.SetUsageCounter(extractor.NewUsageCounter(filepath.Join(config.Dir(), "usage.json")))
 
 	// Create an instance of the app structure
 	application := app.NewApp(ext)